@@ -1,18 +1,28 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
-	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/ilyakaznacheev/cleanenv"
+	"roadmap.restapi/internal/analytics"
 	"roadmap.restapi/internal/api"
+	"roadmap.restapi/internal/api/handlers"
+	apimiddleware "roadmap.restapi/internal/api/middleware"
 	"roadmap.restapi/internal/config"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/customdomain"
+	"roadmap.restapi/internal/export"
+	"roadmap.restapi/internal/fallback"
+	"roadmap.restapi/internal/health"
+	"roadmap.restapi/internal/outbox"
+	"roadmap.restapi/internal/page"
 	"roadmap.restapi/internal/postgres"
+	"roadmap.restapi/internal/privacy"
 	"roadmap.restapi/internal/redis"
 	"roadmap.restapi/internal/token"
 	"roadmap.restapi/internal/url"
@@ -35,6 +45,11 @@ func main() {
 
 	config.SetCfg(&cfg)
 
+	if err := apimiddleware.CheckTokenSources(cfg.TokensConfig.Sources); err != nil {
+		slog.Error("invalid tokens config", "err", err)
+		os.Exit(1)
+	}
+
 	// Logging
 	logLevel := slog.LevelInfo
 	if !config.Cfg().IsProd() {
@@ -53,6 +68,8 @@ func main() {
 		os.Exit(1)
 	}
 
+	transactor := postgres.NewTransactor(pgDB)
+
 	rdb, err := redis.Connect(
 		cfg.RedisConfig.Host,
 		cfg.RedisConfig.Username,
@@ -64,31 +81,183 @@ func main() {
 		os.Exit(1)
 	}
 
+	// outbox
+	outboxRepo := outbox.NewPostgresRepository(pgDB)
+	outboxSenders := outbox.NewDispatcher(outbox.NewLogSender())
+	outboxRelay := outbox.NewRelay(outboxRepo, outboxSenders, cfg.OutboxConfig.BatchSize)
+
 	// users
 	userRepo := user.NewPostgresUserRepository(pgDB)
 	passwordHasher := user.NewArgon2IDPasswordHasher()
 	users := user.NewUseCases(userRepo, passwordHasher)
 
 	// tokens
-	tokenExtractor := token.NewJWTClaimsExtractor(
-		[]byte(cfg.TokensConfig.SecretKey),
-		jwt.SigningMethodHS512,
-	)
-	tokenGenerator := token.NewJWTGenerator(
-		[]byte(cfg.TokensConfig.SecretKey),
-		jwt.SigningMethodHS512,
+	signingKeys, err := loadTokenKeys(cfg.TokensConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load token signing keys. err: %s", err.Error())
+		os.Exit(1)
+	}
+	tokenExtractor := token.NewJWTClaimsExtractorWithKeys(signingKeys)
+	tokenGenerator := token.NewJWTGeneratorWithKeys(
+		signingKeys,
 		config.Cfg().TokensConfig.AccessTTL,
 		config.Cfg().TokensConfig.RefreshTTL,
 	)
-	tokenWhitelist := token.NewRedisWhitelistRepository(
-		rdb,
-		time.Duration(config.Cfg().TokensConfig.RefreshTTL)*time.Minute,
+	tokenWhitelist := token.NewRedisWhitelistRepository(rdb, config.Cfg().TokensConfig.RefreshTTL)
+	tokenSessions := token.NewRedisSessionRepository(rdb, config.Cfg().TokensConfig.RefreshTTL)
+	tokenDenylist := token.NewRedisDenylist(rdb, cfg.TokensConfig.DenylistCapacity)
+	tokens := token.NewUseCases(tokenExtractor, tokenGenerator, tokenWhitelist, tokenSessions, tokenDenylist)
+
+	// custom domains
+	domains := customdomain.NewUseCases(
+		customdomain.NewPostgresRepository(pgDB),
+		customdomain.NewNetResolver(cfg.DomainsConfig.LookupTimeout),
+		customdomain.NewRedisHostCache(rdb, cfg.DomainsConfig.HostCacheTTL),
 	)
-	tokens := token.NewUseCases(tokenExtractor, tokenGenerator, tokenWhitelist)
 
 	// urls
 	urlsRepo := url.NewPostgresURLRepository(pgDB)
-	urls := url.NewUseCases(urlsRepo)
+	urlRevisions := url.NewPostgresRevisionRepository(pgDB)
+	urlTransfers := url.NewPostgresTransferRepository(pgDB)
+	urls := url.NewUseCases(
+		transactor,
+		urlsRepo,
+		urlRevisions,
+		urlTransfers,
+		outboxRepo,
+		domains,
+		cfg.URLsConfig.TransferTTL,
+	)
+	outboxSenders.Handle(url.TOPIC_METADATA_REQUESTED, url.NewMetadataSender(
+		urlsRepo,
+		url.NewHTTPMetadataFetcher(
+			cfg.PreviewConfig.FetchTimeout,
+			cfg.PreviewConfig.UserAgent,
+			cfg.PreviewConfig.MaxBodyBytes,
+		),
+	))
+
+	interstitial, err := url.NewInterstitialPolicy(
+		cfg.InterstitialConfig.Domains,
+		cfg.InterstitialConfig.SuspiciousPatterns,
+		cfg.InterstitialConfig.RecentChangeWindow,
+	)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid interstitial config. err: %s", err.Error())
+		os.Exit(1)
+	}
+
+	// analytics
+	privacyUC := privacy.NewUseCases(
+		privacy.NewPostgresSettingsRepository(pgDB),
+		privacy.Policy{
+			IPv4PrefixBits: cfg.PrivacyConfig.IPv4PrefixBits,
+			IPv6PrefixBits: cfg.PrivacyConfig.IPv6PrefixBits,
+			HonorDNT:       cfg.PrivacyConfig.HonorDNT,
+			HonorGPC:       cfg.PrivacyConfig.HonorGPC,
+		},
+	)
+
+	botRanges, err := analytics.LoadIPRanges(cfg.AnalyticsConfig.BotIPRangesFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load bot ip ranges. err: %s", err.Error())
+		os.Exit(1)
+	}
+
+	refererRules, err := analytics.LoadRefererRules(cfg.AnalyticsConfig.RefererSourcesFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load referer sources. err: %s", err.Error())
+		os.Exit(1)
+	}
+
+	stats := analytics.NewUseCases(
+		analytics.NewPostgresURLStatisticsRepository(pgDB),
+		analytics.NewRedisVisitorsRepository(rdb, cfg.AnalyticsConfig.UniqueVisitorsTTL),
+		analytics.NewRegexUserAgentParser(),
+		analytics.NewRefererNormalizer(refererRules),
+		analytics.NewBotClassifier(
+			botRanges,
+			analytics.NewRedisRateCounter(rdb),
+			analytics.NewMemoryRateCounter(),
+			cfg.AnalyticsConfig.BotRateLimit,
+			cfg.AnalyticsConfig.BotRateWindow,
+		),
+		analytics.NewRedisClickStream(rdb),
+		privacyUC,
+		analytics.NewRedisDashboardCache(rdb, cfg.AnalyticsConfig.DashboardCacheTTL),
+	)
+	clicksRollup := analytics.NewRollup(
+		analytics.NewPostgresRollupRepository(pgDB),
+		cfg.AnalyticsConfig.RawRetention,
+		cfg.AnalyticsConfig.RollupDelay,
+	)
+	exportFiles, err := export.NewDiskFileStore(cfg.ExportConfig.Dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to prepare export dir. err: %s", err.Error())
+		os.Exit(1)
+	}
+	exports := export.NewUseCases(
+		export.NewPostgresRowSource(pgDB),
+		export.NewPostgresJobRepository(pgDB),
+		exportFiles,
+		cfg.ExportConfig.AsyncThreshold,
+	)
+	exportWorker := export.NewWorker(exports, cfg.ExportConfig.Retention)
+	fallbacks := fallback.NewUseCases(fallback.NewPostgresRepository(pgDB), domains)
+	clickRecorder := analytics.NewClickRecorder(
+		stats,
+		cfg.AnalyticsConfig.ClickQueueSize,
+		cfg.AnalyticsConfig.ClickWorkers,
+	)
+	clientIPs, err := handlers.NewClientIPResolver(cfg.AnalyticsConfig.IPHeader, cfg.AnalyticsConfig.TrustedProxies)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid trusted proxies. err: %s", err.Error())
+		os.Exit(1)
+	}
+	redirector := handlers.NewRedirector(
+		stats,
+		clickRecorder,
+		fallbacks,
+		interstitial,
+		cfg.InterstitialConfig.Countdown,
+		cfg.AnalyticsConfig.CountryHeader,
+		clientIPs,
+		cfg.URLsConfig.PublicBaseURL,
+	)
+
+	// pages
+	pagesUC := page.NewUseCases(page.NewPostgresRepository(pgDB), urlsRepo)
+
+	// health
+	healthRepo := health.NewPostgresRepository(pgDB)
+	healthChecker := health.NewChecker(
+		transactor,
+		healthRepo,
+		health.NewHTTPProber(cfg.HealthConfig.Timeout, cfg.HealthConfig.UserAgent),
+		outboxRepo,
+		health.CheckerOptions{
+			Interval:         cfg.HealthConfig.Interval,
+			RetryBackoff:     cfg.HealthConfig.RetryBackoff,
+			MaxBackoff:       cfg.HealthConfig.MaxBackoff,
+			FailureThreshold: cfg.HealthConfig.FailureThreshold,
+			Concurrency:      cfg.HealthConfig.Concurrency,
+			HostInterval:     cfg.HealthConfig.HostInterval,
+			BatchSize:        cfg.HealthConfig.BatchSize,
+			HistoryRetention: cfg.HealthConfig.HistoryRetention,
+			PruneInterval:    cfg.HealthConfig.PruneInterval,
+		},
+	)
+
+	// Background jobs
+	jobsCtx := ctxlogging.Add(context.Background(), log)
+	go tokenDenylist.Run(jobsCtx, cfg.TokensConfig.DenylistRebuildInterval)
+	go outboxRelay.Run(jobsCtx, cfg.OutboxConfig.PollInterval)
+	go clicksRollup.Run(jobsCtx, cfg.AnalyticsConfig.RollupInterval)
+	go clickRecorder.Run(jobsCtx)
+	go exportWorker.Run(jobsCtx, cfg.ExportConfig.PollInterval)
+	if cfg.HealthConfig.Enabled {
+		go healthChecker.Run(jobsCtx, cfg.HealthConfig.Tick)
+	}
 
 	// Router
 	router := api.NewRouter(
@@ -96,9 +265,20 @@ func main() {
 		users,
 		userRepo,
 		tokens,
-		tokenExtractor,
+		token.NewDenylistClaimsExtractor(tokenExtractor, tokenDenylist),
+		signingKeys,
 		urlsRepo,
 		urls,
+		healthRepo,
+		stats,
+		redirector,
+		pagesUC,
+		domains,
+		fallbacks,
+		privacyUC,
+		exports,
+		clientIPs,
+		cfg.URLsConfig.PublicBaseURL,
 	)
 	router.Mount("/debug", middleware.Profiler())
 
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+
+	"roadmap.restapi/internal/config"
+	"roadmap.restapi/internal/token"
+)
+
+// loadTokenKeys builds the key ring of cfg: the key of the top level
+// settings, active from the start, followed by the rotating keys.
+func loadTokenKeys(cfg config.TokensConfig) (*token.KeyRing, error) {
+	keys := []token.RingKey{}
+
+	if cfg.SecretKey != "" || cfg.PrivateKeyFile != "" || len(cfg.Keys) == 0 {
+		key, err := token.LoadSigningKey(cfg.Algorithm, cfg.SecretKey, cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, token.RingKey{Key: key})
+	}
+
+	for i, k := range cfg.Keys {
+		key, err := token.LoadSigningKey(k.Algorithm, k.SecretKey, k.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i, err)
+		}
+		if k.ID != "" {
+			key.ID = k.ID
+		}
+		keys = append(keys, token.RingKey{Key: key, ActiveFrom: k.ActiveFrom})
+	}
+
+	return token.NewKeyRing(keys, cfg.RotationGrace)
+}
@@ -0,0 +1,146 @@
+package analytics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/netip"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"roadmap.restapi/internal/crawler"
+	"roadmap.restapi/internal/ctxlogging"
+)
+
+// botPattern matches user agents of crawlers, http libraries, uptime
+// monitors and vulnerability scanners.
+var botPattern = regexp.MustCompile(`(?i)bot\b|bot/|crawl|spider|slurp|` +
+	`curl/|wget/|python-requests|python-urllib|aiohttp|go-http-client|java/|okhttp|libwww-perl|httpie|axios/|node-fetch|scrapy|` +
+	`uptimerobot|pingdom|statuscake|site24x7|newrelicpinger|datadog|checkly|betteruptime|` +
+	`nmap|masscan|zgrab|nikto|sqlmap|nuclei|censys|shodan|expanse`)
+
+// headlessPattern matches user agents of automated browsers.
+var headlessPattern = regexp.MustCompile(`(?i)headlesschrome|phantomjs|puppeteer|playwright|selenium|slimerjs|htmlunit`)
+
+// BotClassifier tells clicks of humans from clicks of bots.
+type BotClassifier struct {
+	ipRanges   []netip.Prefix
+	rates      RateCounter
+	ephemeral  RateCounter
+	rateLimit  int64
+	rateWindow time.Duration
+}
+
+// NewBotClassifier creates a classifier. Visitors from ipRanges are bots, as
+// are visitors clicking more than rateLimit times within rateWindow. Clicks
+// of visitors refusing tracking are counted by ephemeral, which must keep
+// them in memory only.
+func NewBotClassifier(ipRanges []netip.Prefix, rates RateCounter, ephemeral RateCounter, rateLimit int64, rateWindow time.Duration) *BotClassifier {
+	return &BotClassifier{
+		ipRanges:   ipRanges,
+		rates:      rates,
+		ephemeral:  ephemeral,
+		rateLimit:  rateLimit,
+		rateWindow: rateWindow,
+	}
+}
+
+// IsBot classifies the visitor. fingerprint identifies the visitor for the
+// rate heuristic, which is skipped when it is empty. The rate of anonymous
+// visitors is kept by the ephemeral counter.
+func (c *BotClassifier) IsBot(ctx context.Context, visitor Visitor, fingerprint string, anonymous bool) bool {
+	ua := visitor.UserAgent
+	if ua == "" || crawler.IsPreviewBot(ua) || botPattern.MatchString(ua) {
+		return true
+	}
+
+	// browsers always send the language on navigation, scripts that only
+	// pretend to be one usually don't
+	if headlessPattern.MatchString(ua) || (strings.HasPrefix(ua, "Mozilla/") && visitor.AcceptLanguage == "") {
+		return true
+	}
+
+	if c.fromBotRange(visitor.IP) {
+		return true
+	}
+
+	if fingerprint == "" || c.rateLimit <= 0 {
+		return false
+	}
+
+	rates := c.rates
+	if anonymous {
+		rates = c.ephemeral
+	}
+
+	hits, err := rates.Hit(ctx, fingerprint, c.rateWindow)
+	if err != nil {
+		ctxlogging.Get(ctx).Warn("failed to count click rate", "err", err)
+		return false
+	}
+
+	return hits > c.rateLimit
+}
+
+func (c *BotClassifier) fromBotRange(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range c.ipRanges {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LoadIPRanges reads CIDR ranges, one per line, from path. Single addresses,
+// empty lines and # comments are allowed. An empty path loads no ranges.
+func LoadIPRanges(path string) ([]netip.Prefix, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ranges := []netip.Prefix{}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if !strings.Contains(line, "/") {
+			addr, err := netip.ParseAddr(line)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+			}
+			ranges = append(ranges, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		ranges = append(ranges, prefix.Masked())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return ranges, nil
+}
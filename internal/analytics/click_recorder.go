@@ -0,0 +1,82 @@
+package analytics
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/ctxlogging"
+)
+
+// PendingClick holds the arguments of UseCases.AddClick for a click waiting
+// in a ClickRecorder.
+type PendingClick struct {
+	ID          uuid.UUID
+	AuthorID    uuid.UUID
+	URLDomain   string
+	URLID       string
+	Visitor     Visitor
+	CountryCode *string
+	Referer     string
+}
+
+// ClickRecorder records clicks in background with a fixed number of workers
+// so analytics never slows down or breaks a redirect. Clicks arriving while
+// the queue is full are dropped.
+type ClickRecorder struct {
+	analytics *UseCases
+	queue     chan PendingClick
+	workers   int
+}
+
+func NewClickRecorder(analytics *UseCases, queueSize int, workers int) *ClickRecorder {
+	return &ClickRecorder{
+		analytics: analytics,
+		queue:     make(chan PendingClick, max(queueSize, 1)),
+		workers:   max(workers, 1),
+	}
+}
+
+// Enqueue hands click to the workers without blocking. It returns false when
+// the queue is full and the click was dropped.
+func (r *ClickRecorder) Enqueue(click PendingClick) bool {
+	select {
+	case r.queue <- click:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run records queued clicks until ctx is done.
+func (r *ClickRecorder) Run(ctx context.Context) {
+	wg := sync.WaitGroup{}
+	for range r.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case click := <-r.queue:
+					r.record(ctx, click)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Record stores click right away, for callers that hand the click id out and
+// need the click to exist before the visitor can use it.
+func (r *ClickRecorder) Record(ctx context.Context, c PendingClick) error {
+	return r.analytics.AddClick(ctx, c.ID, c.AuthorID, c.URLDomain, c.URLID, c.Visitor, c.CountryCode, c.Referer)
+}
+
+func (r *ClickRecorder) record(ctx context.Context, c PendingClick) {
+	if err := r.Record(ctx, c); err != nil {
+		ctxlogging.Get(ctx).Error("failed to record click", "urlID", c.URLID, "err", err)
+	}
+}
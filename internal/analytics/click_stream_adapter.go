@@ -0,0 +1,87 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/redis/go-redis/v9"
+	"roadmap.restapi/internal/ctxlogging"
+)
+
+const (
+	clickChannelPrefix = "clicks:"
+	// subscriberBuffer is how many events a slow subscriber may lag behind
+	// before events are dropped.
+	subscriberBuffer = 64
+)
+
+// RedisClickStream publishes click events over Redis pub/sub, one channel
+// per url.
+type RedisClickStream struct {
+	client *redis.Client
+}
+
+func NewRedisClickStream(client *redis.Client) *RedisClickStream {
+	return &RedisClickStream{
+		client: client,
+	}
+}
+
+func clickChannel(domain string, urlID string) string {
+	return clickChannelPrefix + domain + ":" + urlID
+}
+
+func (r *RedisClickStream) Publish(ctx context.Context, event ClickEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Publish(ctx, clickChannel(event.URLDomain, event.URLID), payload).Err()
+}
+
+func (r *RedisClickStream) Subscribe(ctx context.Context, domain string, urlID string) (<-chan ClickEvent, func(), error) {
+	log := ctxlogging.Get(ctx)
+	ctx, cancel := context.WithCancel(ctx)
+
+	sub := r.client.Subscribe(ctx, clickChannel(domain, urlID))
+	// wait for the confirmation so no event published after Subscribe
+	// returned is missed
+	if _, err := sub.Receive(ctx); err != nil {
+		cancel()
+		sub.Close()
+		return nil, nil, err
+	}
+
+	events := make(chan ClickEvent, subscriberBuffer)
+	go func() {
+		defer close(events)
+		defer sub.Close()
+
+		msgs := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+
+				var event ClickEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					log.Error("invalid click event", "err", err, "payload", msg.Payload)
+					continue
+				}
+
+				select {
+				case events <- event:
+				default:
+					log.Debug("dropped click event for slow subscriber", "url_id", urlID)
+				}
+			}
+		}
+	}()
+
+	return events, cancel, nil
+}
@@ -0,0 +1,30 @@
+package analytics
+
+import (
+	neturl "net/url"
+
+	"github.com/google/uuid"
+)
+
+// CLICK_ID_PARAM is the query parameter carrying the click id, both on
+// destinations and on the conversion pixel.
+const CLICK_ID_PARAM = "cid"
+
+// AppendClickID adds the click id to the query of destination, keeping the
+// existing query as is. Destinations that do not parse are returned
+// unchanged.
+func AppendClickID(destination string, clickID uuid.UUID) string {
+	parsed, err := neturl.Parse(destination)
+	if err != nil {
+		return destination
+	}
+
+	param := CLICK_ID_PARAM + "=" + clickID.String()
+	if parsed.RawQuery == "" {
+		parsed.RawQuery = param
+	} else {
+		parsed.RawQuery += "&" + param
+	}
+
+	return parsed.String()
+}
@@ -0,0 +1,162 @@
+package analytics
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/ctxlogging"
+)
+
+const (
+	DASHBOARD_DEFAULT_DAYS  = 30
+	DASHBOARD_MAX_DAYS      = 366
+	DASHBOARD_DEFAULT_LIMIT = 10
+	// DASHBOARD_MAX_LIMIT is also the length of the top lists cached, any
+	// limit up to it is served from the same cache entry.
+	DASHBOARD_MAX_LIMIT = 100
+)
+
+// Dashboard summarizes the clicks of humans on all links of userID. A zero
+// To ends today, a zero From starts DASHBOARD_DEFAULT_DAYS before To and a
+// zero Limit lists DASHBOARD_DEFAULT_LIMIT entries. Dashboards are cached
+// briefly, so the latest clicks may be missing.
+func (s *UseCases) Dashboard(ctx context.Context, userID uuid.UUID, filter DashboardFilter) (*Dashboard, error) {
+	if filter.To.IsZero() {
+		filter.To = Day(time.Now()).Add(24 * time.Hour)
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.AddDate(0, 0, -DASHBOARD_DEFAULT_DAYS)
+	}
+	filter.From = Day(filter.From)
+	if filter.Limit == 0 {
+		filter.Limit = DASHBOARD_DEFAULT_LIMIT
+	}
+
+	if !filter.From.Before(filter.To) || filter.To.Sub(filter.From) > DASHBOARD_MAX_DAYS*24*time.Hour {
+		return nil, ErrInvalidRange
+	}
+	if filter.Limit < 0 || filter.Limit > DASHBOARD_MAX_LIMIT {
+		return nil, ErrInvalidLimit
+	}
+
+	log := ctxlogging.Get(ctx)
+
+	dashboard, ok, err := s.dashboards.Get(ctx, userID, filter.From, filter.To)
+	if err != nil {
+		log.Warn("failed to read cached dashboard", "err", err)
+	}
+	if ok {
+		return dashboard.top(filter.Limit), nil
+	}
+
+	previousFrom := filter.From.Add(-filter.To.Sub(filter.From))
+	stats, err := s.repo.AccountStats(ctx, userID, previousFrom, filter.To)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard = buildDashboard(filter.From, filter.To, previousFrom, stats)
+	s.classifyReferers(dashboard.TopReferers)
+	if err := s.dashboards.Set(ctx, userID, dashboard); err != nil {
+		log.Warn("failed to cache dashboard", "err", err)
+	}
+
+	return dashboard.top(filter.Limit), nil
+}
+
+// buildDashboard sums stats of [previousFrom, to) into a dashboard of
+// [from, to), the days before from only count towards the comparison.
+func buildDashboard(from time.Time, to time.Time, previousFrom time.Time, stats []AccountStatistics) *Dashboard {
+	dashboard := &Dashboard{
+		From:  from,
+		To:    to,
+		Trend: []DailyClicks{},
+		Comparison: PeriodComparison{
+			PreviousFrom: previousFrom,
+			PreviousTo:   from,
+		},
+	}
+
+	daily := map[time.Time]int{}
+	links := map[linkKey]int{}
+	countries := map[string]int{}
+	referers := map[string]int{}
+	for _, day := range stats {
+		if day.Date.Before(from) {
+			dashboard.Comparison.PreviousClicks += day.TotalClicks
+			continue
+		}
+
+		dashboard.TotalClicks += day.TotalClicks
+		daily[Day(day.Date)] += day.TotalClicks
+		for _, l := range day.ByLink {
+			links[linkKey{domain: l.URLDomain, id: l.URLID}] += l.Clicks
+		}
+		for _, g := range day.ByGeo {
+			countries[g.CountryCode] += g.Clicks
+		}
+		for _, r := range day.ByReferer {
+			referers[r.Referer] += r.Clicks
+		}
+	}
+
+	for date := from; date.Before(to); date = date.Add(24 * time.Hour) {
+		dashboard.Trend = append(dashboard.Trend, DailyClicks{Date: date, Clicks: daily[date]})
+	}
+
+	dashboard.TopLinks = sortedLinks(links)
+	dashboard.TopCountries = []ClicksByGeo{}
+	for _, kc := range sortedCounts(countries) {
+		dashboard.TopCountries = append(dashboard.TopCountries, ClicksByGeo{CountryCode: kc.key, Clicks: kc.clicks})
+	}
+	dashboard.TopReferers = []ClicksByReferer{}
+	for _, kc := range sortedCounts(referers) {
+		dashboard.TopReferers = append(dashboard.TopReferers, ClicksByReferer{Referer: kc.key, Clicks: kc.clicks})
+	}
+
+	dashboard.Comparison.Clicks = dashboard.TotalClicks
+	if previous := dashboard.Comparison.PreviousClicks; previous > 0 {
+		growth := math.Round(float64(dashboard.TotalClicks-previous)/float64(previous)*1000) / 10
+		dashboard.Comparison.GrowthPercent = &growth
+	}
+
+	return dashboard.top(DASHBOARD_MAX_LIMIT)
+}
+
+// top returns a copy of the dashboard with top lists of at most limit
+// entries.
+func (d *Dashboard) top(limit int) *Dashboard {
+	top := *d
+	top.TopLinks = d.TopLinks[:min(limit, len(d.TopLinks))]
+	top.TopReferers = d.TopReferers[:min(limit, len(d.TopReferers))]
+	top.TopCountries = d.TopCountries[:min(limit, len(d.TopCountries))]
+
+	return &top
+}
+
+type linkKey struct {
+	domain string
+	id     string
+}
+
+// sortedLinks orders link counts by clicks, most clicked first.
+func sortedLinks(links map[linkKey]int) []ClicksByLink {
+	sorted := make([]ClicksByLink, 0, len(links))
+	for link, clicks := range links {
+		sorted = append(sorted, ClicksByLink{URLDomain: link.domain, URLID: link.id, Clicks: clicks})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Clicks != sorted[j].Clicks {
+			return sorted[i].Clicks > sorted[j].Clicks
+		}
+		if sorted[i].URLDomain != sorted[j].URLDomain {
+			return sorted[i].URLDomain < sorted[j].URLDomain
+		}
+		return sorted[i].URLID < sorted[j].URLID
+	})
+
+	return sorted
+}
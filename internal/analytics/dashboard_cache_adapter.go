@@ -0,0 +1,56 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const dashboardKeyPrefix = "dashboard:"
+
+// RedisDashboardCache keeps dashboards as JSON for ttl.
+type RedisDashboardCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewRedisDashboardCache(client *redis.Client, ttl time.Duration) *RedisDashboardCache {
+	return &RedisDashboardCache{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func dashboardKey(userID uuid.UUID, from time.Time, to time.Time) string {
+	return dashboardKeyPrefix + userID.String() + ":" + from.Format(time.DateOnly) + ":" + to.Format(time.DateOnly)
+}
+
+func (c *RedisDashboardCache) Get(ctx context.Context, userID uuid.UUID, from time.Time, to time.Time) (*Dashboard, bool, error) {
+	raw, err := c.client.Get(ctx, dashboardKey(userID, from, to)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	dashboard := &Dashboard{}
+	if err := json.Unmarshal(raw, dashboard); err != nil {
+		return nil, false, err
+	}
+
+	return dashboard, true, nil
+}
+
+func (c *RedisDashboardCache) Set(ctx context.Context, userID uuid.UUID, dashboard *Dashboard) error {
+	raw, err := json.Marshal(dashboard)
+	if err != nil {
+		return err
+	}
+
+	return c.client.Set(ctx, dashboardKey(userID, dashboard.From, dashboard.To), raw, c.ttl).Err()
+}
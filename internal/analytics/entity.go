@@ -1,21 +1,170 @@
 package analytics
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DEVICE_DESKTOP = "desktop"
+	DEVICE_MOBILE  = "mobile"
+	DEVICE_TABLET  = "tablet"
+	DEVICE_OTHER   = "other"
+
+	// FAMILY_OTHER is the os or browser family of unrecognized user agents.
+	FAMILY_OTHER = "Other"
+)
+
+// UserAgent is the classification of a click's user agent header.
+type UserAgent struct {
+	Device  string
+	OS      string
+	Browser string
+}
+
+type Click struct {
+	ID            uuid.UUID `db:"id"`
+	URLDomain     string    `db:"url_domain"`
+	URLID         string    `db:"url_id"`
+	ClickedAt     time.Time `db:"clicked_at"`
+	CountryCode   *string   `db:"country_code"`
+	Referer       *string   `db:"referer"`
+	DeviceType    *string   `db:"device_type"`
+	OSFamily      *string   `db:"os_family"`
+	BrowserFamily *string   `db:"browser_family"`
+	IsBot         bool      `db:"is_bot"`
+}
+
+// Conversion is an outcome, like a signup or a purchase, attributed to the
+// click that led to it. A click converts at most once.
+type Conversion struct {
+	ID          uuid.UUID `db:"id"`
+	ClickID     uuid.UUID `db:"click_id"`
+	URLDomain   string    `db:"url_domain"`
+	URLID       string    `db:"url_id"`
+	ClickedAt   time.Time `db:"clicked_at"`
+	ConvertedAt time.Time `db:"converted_at"`
+	Value       *float64  `db:"value"`
+}
+
+// ClickEvent is a click as published to live subscribers of its url.
+type ClickEvent struct {
+	URLDomain   string    `json:"url_domain"`
+	URLID       string    `json:"url_id"`
+	ClickedAt   time.Time `json:"clicked_at"`
+	CountryCode *string   `json:"country_code"`
+	Referer     *string   `json:"referer"`
+	Device      *string   `json:"device"`
+}
 
 type ClicksByGeo struct {
 	CountryCode string `json:"country_code"`
 	Clicks      int    `json:"clicks"`
 }
 
+// ClicksByReferer counts clicks per referer host, empty for direct visits.
 type ClicksByReferer struct {
-	Referer string `json:"referer"`
+	Referer    string `json:"referer"`
+	SourceType string `json:"source_type"`
+	Clicks     int    `json:"clicks"`
+}
+
+type ClicksByDevice struct {
+	Device string `json:"device"`
+	Clicks int    `json:"clicks"`
+}
+
+type ClicksByOS struct {
+	OS     string `json:"os"`
+	Clicks int    `json:"clicks"`
+}
+
+type ClicksByBrowser struct {
+	Browser string `json:"browser"`
 	Clicks  int    `json:"clicks"`
 }
 
+// StatsFilter selects the clicks statistics are computed from.
+type StatsFilter struct {
+	// From is the first day, inclusive.
+	From time.Time
+	// To is the end of the range, exclusive.
+	To          time.Time
+	IncludeBots bool
+}
+
 type UrlStatistics struct {
-	UrlID       string            `json:"url_id"`
+	UrlDomain   string    `json:"url_domain"`
+	UrlID       string    `json:"url_id"`
+	Date        time.Time `json:"date"`
+	TotalClicks int       `json:"total_clicks"`
+	BotClicks   int       `json:"bot_clicks"`
+	// UniqueVisitors is a HyperLogLog estimate, about 1% off.
+	UniqueVisitors int64             `json:"unique_visitors"`
+	ByGeo          []ClicksByGeo     `json:"by_geo"`
+	ByReferer      []ClicksByReferer `json:"by_referer"`
+	ByDevice       []ClicksByDevice  `json:"by_device"`
+	ByOS           []ClicksByOS      `json:"by_os"`
+	ByBrowser      []ClicksByBrowser `json:"by_browser"`
+	// Conversions are counted on the day of the click they are attributed
+	// to. ConversionRate is their share of TotalClicks.
+	Conversions     int     `json:"conversions"`
+	ConversionValue float64 `json:"conversion_value"`
+	ConversionRate  float64 `json:"conversion_rate"`
+}
+
+type ClicksByLink struct {
+	URLDomain string `json:"url_domain"`
+	URLID     string `json:"url_id"`
+	Clicks    int    `json:"clicks"`
+}
+
+// AccountStatistics are the clicks of humans on all links of an account on
+// one day.
+type AccountStatistics struct {
 	Date        time.Time         `json:"date"`
 	TotalClicks int               `json:"total_clicks"`
+	ByLink      []ClicksByLink    `json:"by_link"`
 	ByGeo       []ClicksByGeo     `json:"by_geo"`
 	ByReferer   []ClicksByReferer `json:"by_referer"`
 }
+
+type DailyClicks struct {
+	Date   time.Time `json:"date"`
+	Clicks int       `json:"clicks"`
+}
+
+// PeriodComparison compares the clicks of a period with the period of the
+// same length right before it.
+type PeriodComparison struct {
+	Clicks         int       `json:"clicks"`
+	PreviousClicks int       `json:"previous_clicks"`
+	PreviousFrom   time.Time `json:"previous_from"`
+	PreviousTo     time.Time `json:"previous_to"`
+	// GrowthPercent is nil when the previous period had no clicks.
+	GrowthPercent *float64 `json:"growth_percent"`
+}
+
+// Dashboard summarizes the clicks of humans on all links of an account
+// within [From, To).
+type Dashboard struct {
+	From         time.Time         `json:"from"`
+	To           time.Time         `json:"to"`
+	TotalClicks  int               `json:"total_clicks"`
+	TopLinks     []ClicksByLink    `json:"top_links"`
+	Trend        []DailyClicks     `json:"trend"`
+	TopReferers  []ClicksByReferer `json:"top_referers"`
+	TopCountries []ClicksByGeo     `json:"top_countries"`
+	Comparison   PeriodComparison  `json:"comparison"`
+}
+
+// DashboardFilter selects the period of a dashboard and how many entries its
+// top lists have.
+type DashboardFilter struct {
+	// From is the first day, inclusive.
+	From time.Time
+	// To is the end of the range, exclusive.
+	To    time.Time
+	Limit int
+}
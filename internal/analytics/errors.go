@@ -0,0 +1,12 @@
+package analytics
+
+import "errors"
+
+var (
+	ErrInvalidRange = errors.New("from must be before to")
+	ErrInvalidLimit = errors.New("limit is out of range")
+
+	ErrClickNotFound    = errors.New("click not found")
+	ErrAlreadyConverted = errors.New("click is already converted")
+	ErrInvalidValue     = errors.New("conversion value must not be negative")
+)
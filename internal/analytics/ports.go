@@ -1,10 +1,64 @@
 package analytics
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type URLStatisticsRepository interface {
-	AddClick(ctx context.Context, urlID string) error
-	AddRefererClick(ctx context.Context, urlID string, referer string) error
-	AddGeoClick(ctx context.Context, urlID string, countryCode string) error
-	Stats(ctx context.Context, urlID string) ([]UrlStatistics, error)
+	AddClick(ctx context.Context, click *Click) error
+	Click(ctx context.Context, id uuid.UUID) (*Click, error)
+	// AddConversion fails with ErrAlreadyConverted when the click of the
+	// conversion has one already.
+	AddConversion(ctx context.Context, conversion *Conversion) error
+	// Stats returns per day statistics of the url. Clicks of bots are only
+	// counted when the filter includes them, BotClicks always.
+	Stats(ctx context.Context, domain string, urlID string, filter StatsFilter) ([]UrlStatistics, error)
+	// AccountStats returns per day statistics of the clicks of humans on all
+	// links authored by userID within [from, to).
+	AccountStats(ctx context.Context, userID uuid.UUID, from time.Time, to time.Time) ([]AccountStatistics, error)
+}
+
+type RollupRepository interface {
+	// RollUp aggregates clicks before until and deletes rolled up clicks
+	// before purgeBefore. It reports false when another instance holds the
+	// rollup lock.
+	RollUp(ctx context.Context, until time.Time, purgeBefore time.Time) (bool, error)
+}
+
+// UserAgentParser classifies user agent headers. Unknown parts are reported
+// as DEVICE_OTHER and FAMILY_OTHER.
+type UserAgentParser interface {
+	Parse(userAgent string) UserAgent
+}
+
+// ClickStream fans click events out to subscribers on every instance.
+type ClickStream interface {
+	Publish(ctx context.Context, event ClickEvent) error
+	// Subscribe streams events of the url until ctx is done or the returned
+	// func is called. Events a subscriber can not take in time are dropped.
+	Subscribe(ctx context.Context, domain string, urlID string) (<-chan ClickEvent, func(), error)
+}
+
+// RateCounter counts hits of a key within fixed time windows.
+type RateCounter interface {
+	// Hit counts a hit and returns the hits of the current window.
+	Hit(ctx context.Context, key string, window time.Duration) (int64, error)
+}
+
+// VisitorsRepository keeps unique visitor estimates per link and day.
+type VisitorsRepository interface {
+	// Salt returns the fingerprint salt of day.
+	Salt(ctx context.Context, day time.Time) (string, error)
+	Add(ctx context.Context, domain string, urlID string, day time.Time, fingerprint string) error
+	Count(ctx context.Context, domain string, urlID string, days []time.Time) (map[time.Time]int64, error)
+}
+
+// DashboardCache keeps recently computed dashboards for a short time.
+type DashboardCache interface {
+	// Get reports false when the dashboard of the period is not cached.
+	Get(ctx context.Context, userID uuid.UUID, from time.Time, to time.Time) (*Dashboard, bool, error)
+	Set(ctx context.Context, userID uuid.UUID, dashboard *Dashboard) error
 }
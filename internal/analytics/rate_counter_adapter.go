@@ -0,0 +1,65 @@
+package analytics
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const rateKeyPrefix = "click-rate:"
+
+// RedisRateCounter counts hits in fixed windows.
+type RedisRateCounter struct {
+	client *redis.Client
+}
+
+func NewRedisRateCounter(client *redis.Client) *RedisRateCounter {
+	return &RedisRateCounter{
+		client: client,
+	}
+}
+
+func (r *RedisRateCounter) Hit(ctx context.Context, key string, window time.Duration) (int64, error) {
+	bucket := time.Now().UnixNano() / int64(window)
+	redisKey := rateKeyPrefix + key + ":" + strconv.FormatInt(bucket, 10)
+
+	pipe := r.client.TxPipeline()
+	hits := pipe.Incr(ctx, redisKey)
+	pipe.Expire(ctx, redisKey, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return hits.Val(), nil
+}
+
+// MemoryRateCounter counts hits in fixed windows within the process. Only
+// the current window is kept, keys of past windows are dropped with it.
+type MemoryRateCounter struct {
+	mu     sync.Mutex
+	bucket int64
+	hits   map[string]int64
+}
+
+func NewMemoryRateCounter() *MemoryRateCounter {
+	return &MemoryRateCounter{
+		hits: map[string]int64{},
+	}
+}
+
+func (m *MemoryRateCounter) Hit(ctx context.Context, key string, window time.Duration) (int64, error) {
+	bucket := time.Now().UnixNano() / int64(window)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if bucket != m.bucket {
+		m.bucket = bucket
+		m.hits = map[string]int64{}
+	}
+	m.hits[key]++
+
+	return m.hits[key], nil
+}
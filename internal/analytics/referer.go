@@ -0,0 +1,194 @@
+package analytics
+
+import (
+	"bufio"
+	"fmt"
+	neturl "net/url"
+	"os"
+	"strings"
+)
+
+const (
+	SOURCE_DIRECT   = "direct"
+	SOURCE_SOCIAL   = "social"
+	SOURCE_SEARCH   = "search"
+	SOURCE_EMAIL    = "email"
+	SOURCE_PAGE     = "page"
+	SOURCE_REFERRAL = "referral"
+
+	// PAGE_REFERER_PREFIX starts the referer of clicks made on link-in-bio
+	// pages, followed by the page handle.
+	PAGE_REFERER_PREFIX = "page:"
+
+	maxHostLength = 253
+)
+
+// ruleSources are the sources a rules file may group hosts into, the other
+// ones follow from the referer itself.
+var ruleSources = map[string]bool{
+	SOURCE_SOCIAL: true,
+	SOURCE_SEARCH: true,
+	SOURCE_EMAIL:  true,
+}
+
+// genericTLDs are the generic top level domains a wildcard rule matches,
+// besides every two letter country code.
+var genericTLDs = map[string]bool{
+	"com": true, "net": true, "org": true, "info": true, "biz": true, "edu": true, "gov": true,
+}
+
+// secondLevelLabels are the labels registries put under a country code,
+// like co.uk and com.au.
+var secondLevelLabels = map[string]bool{
+	"co": true, "com": true, "net": true, "org": true, "ac": true, "edu": true, "gov": true,
+	"gob": true, "ne": true, "or": true,
+}
+
+// RefererRule groups a host and its subdomains into a traffic source. A
+// host ending in ".*" matches the name under any public suffix, like
+// google.com and google.co.uk, but not google.evil.com.
+type RefererRule struct {
+	Source string
+	Host   string
+}
+
+func (r RefererRule) matches(host string) bool {
+	base, wildcard := strings.CutSuffix(r.Host, ".*")
+	if !wildcard {
+		return host == r.Host || strings.HasSuffix(host, "."+r.Host)
+	}
+
+	labels := strings.Split(host, ".")
+	for i := range labels {
+		rest, ok := strings.CutPrefix(strings.Join(labels[i:], "."), base+".")
+		if ok && isPublicSuffix(rest) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isPublicSuffix reports whether suffix is a top level domain or a second
+// level domain of a country code registry, under which anybody registers
+// names.
+func isPublicSuffix(suffix string) bool {
+	second, tld, found := strings.Cut(suffix, ".")
+	if !found {
+		return genericTLDs[suffix] || isCountryCode(suffix)
+	}
+
+	return secondLevelLabels[second] && isCountryCode(tld)
+}
+
+func isCountryCode(label string) bool {
+	return len(label) == 2 && label[0] >= 'a' && label[0] <= 'z' && label[1] >= 'a' && label[1] <= 'z'
+}
+
+// RefererNormalizer reduces referers to the host they came from and tells
+// the traffic source of a host.
+type RefererNormalizer struct {
+	rules []RefererRule
+}
+
+// NewRefererNormalizer creates a normalizer. The first rule matching a host
+// decides its source.
+func NewRefererNormalizer(rules []RefererRule) *RefererNormalizer {
+	return &RefererNormalizer{rules: rules}
+}
+
+// Normalize reduces raw to its lower case host without www. or m. so paths
+// and query strings, which may identify the visitor, are never stored. Page
+// referers are kept as they are and an empty referer stays empty, the visit
+// being direct. Referers without a usable host are reported as not ok.
+func (n *RefererNormalizer) Normalize(raw string) (string, bool) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if raw == "" || strings.HasPrefix(raw, PAGE_REFERER_PREFIX) {
+		return raw, true
+	}
+
+	if !strings.Contains(raw, "://") {
+		raw = "//" + raw
+	}
+	parsed, err := neturl.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+
+	// hosts without a dot or colon are placeholders like "null" or
+	// "localhost" rather than sources
+	host := strings.TrimSuffix(parsed.Hostname(), ".")
+	if !strings.ContainsAny(host, ".:") || len(host) > maxHostLength || strings.ContainsFunc(host, invalidHostRune) {
+		return "", false
+	}
+
+	for _, prefix := range []string{"www.", "m."} {
+		if rest, ok := strings.CutPrefix(host, prefix); ok && strings.Contains(rest, ".") {
+			host = rest
+			break
+		}
+	}
+
+	return host, true
+}
+
+// invalidHostRune reports runes neither DNS names nor IP addresses contain.
+func invalidHostRune(r rune) bool {
+	return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == ':' || r == '_')
+}
+
+// Source tells the traffic source of a normalized referer.
+func (n *RefererNormalizer) Source(referer string) string {
+	if referer == "" {
+		return SOURCE_DIRECT
+	}
+	if strings.HasPrefix(referer, PAGE_REFERER_PREFIX) {
+		return SOURCE_PAGE
+	}
+
+	for _, rule := range n.rules {
+		if rule.matches(referer) {
+			return rule.Source
+		}
+	}
+
+	return SOURCE_REFERRAL
+}
+
+// LoadRefererRules reads "<source> <host>" rules, one per line, see
+// configs/referer_sources.txt. An empty path yields no rules.
+func LoadRefererRules(path string) ([]RefererRule, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rules := []RefererRule{}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected \"<source> <host>\"", path, n)
+		}
+		if !ruleSources[fields[0]] {
+			return nil, fmt.Errorf("%s:%d: unknown source %q", path, n, fields[0])
+		}
+		rules = append(rules, RefererRule{Source: fields[0], Host: strings.ToLower(fields[1])})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
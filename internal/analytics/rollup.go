@@ -0,0 +1,63 @@
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"roadmap.restapi/internal/ctxlogging"
+)
+
+// Rollup periodically aggregates raw clicks into hourly and daily rollups
+// and deletes raw clicks older than the retention. Hours are rolled up once
+// they are closed for delay, so clicks recorded late are not lost.
+type Rollup struct {
+	repo      RollupRepository
+	retention time.Duration
+	delay     time.Duration
+}
+
+// NewRollup creates the job. A zero retention keeps raw clicks forever.
+func NewRollup(repo RollupRepository, retention time.Duration, delay time.Duration) *Rollup {
+	return &Rollup{
+		repo:      repo,
+		retention: retention,
+		delay:     delay,
+	}
+}
+
+func (r *Rollup) Run(ctx context.Context, interval time.Duration) {
+	log := ctxlogging.Get(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.RollUp(ctx, time.Now().UTC()); err != nil {
+				log.Error("click rollup failed", "err", err)
+			}
+		}
+	}
+}
+
+// RollUp rolls up every hour closed at now.
+func (r *Rollup) RollUp(ctx context.Context, now time.Time) error {
+	until := now.Add(-r.delay).Truncate(time.Hour)
+
+	var purgeBefore time.Time
+	if r.retention > 0 {
+		purgeBefore = now.Add(-r.retention)
+	}
+
+	done, err := r.repo.RollUp(ctx, until, purgeBefore)
+	if err != nil {
+		return err
+	}
+	if !done {
+		ctxlogging.Get(ctx).Debug("click rollup is running on another instance")
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/errormapper"
+	"roadmap.restapi/internal/postgres"
+)
+
+type PostgresRollupRepository struct {
+	db     *sqlx.DB
+	errMap *errormapper.ErrorMapper
+}
+
+func NewPostgresRollupRepository(db *sqlx.DB) *PostgresRollupRepository {
+	return &PostgresRollupRepository{
+		db:     db,
+		errMap: errormapper.NewErrorMapper(),
+	}
+}
+
+// RollUp aggregates raw clicks before until into hourly rollups, complete
+// days of hourly rollups into daily ones and deletes raw clicks before
+// purgeBefore that are rolled up. All of it happens in one transaction
+// holding an advisory lock, so a concurrent run on another instance skips
+// instead of counting clicks twice.
+func (r *PostgresRollupRepository) RollUp(ctx context.Context, until time.Time, purgeBefore time.Time) (bool, error) {
+	log := ctxlogging.Get(ctx)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err = tx.GetContext(ctx, &locked, `SELECT pg_try_advisory_xact_lock(hashtext('click_rollups'))`); err != nil {
+		return false, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+	if !locked {
+		return false, nil
+	}
+
+	hourlyMark, err := r.watermark(ctx, tx, GRANULARITY_HOURLY)
+	if err != nil {
+		return false, err
+	}
+
+	if hourlyMark.Before(until) {
+		_, err = tx.ExecContext(ctx, tx.Rebind(`INSERT INTO click_rollups_hourly (url_domain, url_id, bucket, dimension, key, is_bot, clicks)
+		SELECT url_domain, url_id, date_trunc('hour', clicked_at), d.dimension, d.key, is_bot, COUNT(*)
+		FROM clicks `+clickDimensions+`
+		WHERE clicked_at >= ? AND clicked_at < ? AND d.key IS NOT NULL
+		GROUP BY 1, 2, 3, 4, 5, 6
+		ON CONFLICT (url_domain, url_id, bucket, dimension, key, is_bot)
+		DO UPDATE SET clicks = click_rollups_hourly.clicks + EXCLUDED.clicks`), hourlyMark, until)
+		if err != nil {
+			return false, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+		}
+
+		if err = r.setWatermark(ctx, tx, GRANULARITY_HOURLY, until); err != nil {
+			return false, err
+		}
+		hourlyMark = until
+	}
+
+	dailyMark, err := r.watermark(ctx, tx, GRANULARITY_DAILY)
+	if err != nil {
+		return false, err
+	}
+
+	if dailyUntil := hourlyMark.Truncate(24 * time.Hour); dailyMark.Before(dailyUntil) {
+		_, err = tx.ExecContext(ctx, tx.Rebind(`INSERT INTO click_rollups_daily (url_domain, url_id, bucket, dimension, key, is_bot, clicks)
+		SELECT url_domain, url_id, date_trunc('day', bucket), dimension, key, is_bot, SUM(clicks)
+		FROM click_rollups_hourly
+		WHERE bucket >= ? AND bucket < ?
+		GROUP BY 1, 2, 3, 4, 5, 6
+		ON CONFLICT (url_domain, url_id, bucket, dimension, key, is_bot)
+		DO UPDATE SET clicks = click_rollups_daily.clicks + EXCLUDED.clicks`), dailyMark, dailyUntil)
+		if err != nil {
+			return false, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+		}
+
+		if err = r.setWatermark(ctx, tx, GRANULARITY_DAILY, dailyUntil); err != nil {
+			return false, err
+		}
+	}
+
+	// clicks not rolled up yet are kept whatever their age
+	if purge := minTime(purgeBefore, hourlyMark); !purge.IsZero() {
+		if _, err = tx.ExecContext(ctx, tx.Rebind(`DELETE FROM clicks WHERE clicked_at < ?`), purge); err != nil {
+			return false, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return true, nil
+}
+
+// watermark returns the end of the rolled up range, zero time if nothing
+// was rolled up yet.
+func (r *PostgresRollupRepository) watermark(ctx context.Context, tx *sqlx.Tx, granularity string) (time.Time, error) {
+	log := ctxlogging.Get(ctx)
+
+	marks := []time.Time{}
+	err := tx.SelectContext(ctx, &marks, tx.Rebind(`SELECT rolled_up_to FROM click_rollup_watermarks WHERE granularity = ?`), granularity)
+	if err != nil {
+		return time.Time{}, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+	if len(marks) == 0 {
+		return time.Time{}, nil
+	}
+
+	return marks[0], nil
+}
+
+func (r *PostgresRollupRepository) setWatermark(ctx context.Context, tx *sqlx.Tx, granularity string, to time.Time) error {
+	log := ctxlogging.Get(ctx)
+
+	_, err := tx.ExecContext(ctx, tx.Rebind(`INSERT INTO click_rollup_watermarks (granularity, rolled_up_to)
+	VALUES (?, ?)
+	ON CONFLICT (granularity) DO UPDATE SET rolled_up_to = EXCLUDED.rolled_up_to`), granularity, to)
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
@@ -0,0 +1,419 @@
+package analytics
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/database"
+	"roadmap.restapi/internal/errormapper"
+	"roadmap.restapi/internal/postgres"
+)
+
+const (
+	GRANULARITY_HOURLY = "hourly"
+	GRANULARITY_DAILY  = "daily"
+
+	dimensionTotal   = "total"
+	dimensionGeo     = "country_code"
+	dimensionReferer = "referer"
+	dimensionDevice  = "device_type"
+	dimensionOS      = "os_family"
+	dimensionBrowser = "browser_family"
+)
+
+// clickDimensions turns a click into one row per dimension it is counted
+// in. Rows with a NULL key are not counted.
+const clickDimensions = `CROSS JOIN LATERAL (VALUES
+		('total', ''),
+		('country_code', clicks.country_code),
+		('referer', clicks.referer),
+		('device_type', clicks.device_type),
+		('os_family', clicks.os_family),
+		('browser_family', clicks.browser_family)
+	) AS d(dimension, key)`
+
+type PostgresURLStatisticsRepository struct {
+	db     *sqlx.DB
+	errMap *errormapper.ErrorMapper
+}
+
+func NewPostgresURLStatisticsRepository(db *sqlx.DB) *PostgresURLStatisticsRepository {
+	return &PostgresURLStatisticsRepository{
+		db: db,
+		errMap: errormapper.NewErrorMapper(
+			errormapper.NewMapping(database.ErrNotFound, ErrClickNotFound),
+			errormapper.NewMapping(database.ErrUniqueViolation, ErrAlreadyConverted),
+		),
+	}
+}
+
+// AddClick stores the click under its ID, clicks without one get a new ID.
+func (r *PostgresURLStatisticsRepository) AddClick(ctx context.Context, click *Click) error {
+	log := ctxlogging.Get(ctx)
+	if click.ID == uuid.Nil {
+		click.ID = uuid.New()
+	}
+
+	rows, err := r.db.NamedQueryContext(ctx, `INSERT INTO clicks (id, url_domain, url_id, clicked_at, country_code, referer, device_type, os_family, browser_family, is_bot)
+	VALUES (:id, :url_domain, :url_id, :clicked_at, :country_code, :referer, :device_type, :os_family, :browser_family, :is_bot)
+	RETURNING *
+	`, click)
+
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+	defer rows.Close()
+
+	rows.Next()
+	if err = rows.Err(); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	if err = rows.StructScan(click); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
+
+func (r *PostgresURLStatisticsRepository) Click(ctx context.Context, id uuid.UUID) (*Click, error) {
+	log := ctxlogging.Get(ctx)
+	var click Click
+	err := r.db.GetContext(ctx, &click, r.db.Rebind(`SELECT * FROM clicks WHERE id = ?`), id)
+	if err != nil {
+		return nil, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return &click, nil
+}
+
+func (r *PostgresURLStatisticsRepository) AddConversion(ctx context.Context, conversion *Conversion) error {
+	log := ctxlogging.Get(ctx)
+	rows, err := r.db.NamedQueryContext(ctx, `INSERT INTO conversions (click_id, url_domain, url_id, clicked_at, value)
+	VALUES (:click_id, :url_domain, :url_id, :clicked_at, :value)
+	RETURNING *
+	`, conversion)
+
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+	defer rows.Close()
+
+	rows.Next()
+	if err = rows.Err(); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	if err = rows.StructScan(conversion); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
+
+type dimensionCount struct {
+	URLDomain string    `db:"url_domain"`
+	URLID     string    `db:"url_id"`
+	Date      time.Time `db:"date"`
+	Dimension string    `db:"dimension"`
+	Key       string    `db:"key"`
+	IsBot     bool      `db:"is_bot"`
+	Clicks    int       `db:"clicks"`
+}
+
+type watermark struct {
+	Granularity string    `db:"granularity"`
+	RolledUpTo  time.Time `db:"rolled_up_to"`
+}
+
+type dayConversions struct {
+	Date        time.Time `db:"date"`
+	Conversions int       `db:"conversions"`
+	Value       float64   `db:"value"`
+}
+
+// Stats returns per day statistics of the url, newest day first.
+func (r *PostgresURLStatisticsRepository) Stats(ctx context.Context, domain string, urlID string, filter StatsFilter) ([]UrlStatistics, error) {
+	log := ctxlogging.Get(ctx)
+
+	counts, err := r.counts(ctx, `url_domain = ? AND url_id = ?`, []any{domain, urlID}, filter.From, filter.To)
+	if err != nil {
+		return nil, err
+	}
+
+	conversions := []dayConversions{}
+	err = r.db.SelectContext(ctx, &conversions, r.db.Rebind(`SELECT
+		date_trunc('day', clicked_at) AS date, COUNT(*) AS conversions, COALESCE(SUM(value), 0) AS value
+	FROM conversions
+	WHERE url_domain = ? AND url_id = ? AND clicked_at >= ? AND clicked_at < ?
+	GROUP BY 1`), domain, urlID, filter.From, filter.To)
+	if err != nil {
+		return nil, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	stats := aggregateStats(domain, urlID, counts, filter.IncludeBots)
+	addConversions(stats, conversions)
+
+	return stats, nil
+}
+
+// AccountStats returns per day statistics of all links authored by userID,
+// newest day first. Clicks of bots are left out.
+func (r *PostgresURLStatisticsRepository) AccountStats(ctx context.Context, userID uuid.UUID, from time.Time, to time.Time) ([]AccountStatistics, error) {
+	counts, err := r.counts(ctx, `(url_domain, url_id) IN (SELECT domain, id FROM urls WHERE author_id = ?)`, []any{userID}, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return aggregateAccountStats(counts), nil
+}
+
+// counts returns per link and day counts of the clicks matching scope within
+// [from, to). Each part of the range is read from the coarsest source
+// covering it: daily rollups, then hourly rollups, then the raw clicks not
+// rolled up yet.
+func (r *PostgresURLStatisticsRepository) counts(ctx context.Context, scope string, scopeArgs []any, from time.Time, to time.Time) ([]dimensionCount, error) {
+	log := ctxlogging.Get(ctx)
+
+	marks := []watermark{}
+	if err := r.db.SelectContext(ctx, &marks, `SELECT granularity, rolled_up_to FROM click_rollup_watermarks`); err != nil {
+		return nil, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	var dailyMark, hourlyMark time.Time
+	for _, mark := range marks {
+		switch mark.Granularity {
+		case GRANULARITY_DAILY:
+			dailyMark = mark.RolledUpTo
+		case GRANULARITY_HOURLY:
+			hourlyMark = mark.RolledUpTo
+		}
+	}
+
+	args := func(start time.Time, end time.Time) []any {
+		return append(append([]any{}, scopeArgs...), start, end)
+	}
+
+	counts := []dimensionCount{}
+
+	if end := minTime(to, dailyMark); from.Before(end) {
+		err := r.db.SelectContext(ctx, &counts, r.db.Rebind(`SELECT
+			url_domain, url_id, bucket AS date, dimension, key, is_bot, clicks
+		FROM click_rollups_daily
+		WHERE `+scope+` AND bucket >= ? AND bucket < ?`), args(from, end)...)
+		if err != nil {
+			return nil, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+		}
+	}
+
+	if start, end := maxTime(from, dailyMark), minTime(to, hourlyMark); start.Before(end) {
+		hourly := []dimensionCount{}
+		err := r.db.SelectContext(ctx, &hourly, r.db.Rebind(`SELECT
+			url_domain, url_id, date_trunc('day', bucket) AS date, dimension, key, is_bot, SUM(clicks) AS clicks
+		FROM click_rollups_hourly
+		WHERE `+scope+` AND bucket >= ? AND bucket < ?
+		GROUP BY 1, 2, 3, 4, 5, 6`), args(start, end)...)
+		if err != nil {
+			return nil, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+		}
+		counts = append(counts, hourly...)
+	}
+
+	if start := maxTime(from, hourlyMark); start.Before(to) {
+		raw := []dimensionCount{}
+		err := r.db.SelectContext(ctx, &raw, r.db.Rebind(`SELECT
+			url_domain, url_id, date_trunc('day', clicked_at) AS date, d.dimension, d.key, is_bot, COUNT(*) AS clicks
+		FROM clicks `+clickDimensions+`
+		WHERE `+scope+` AND clicked_at >= ? AND clicked_at < ? AND d.key IS NOT NULL
+		GROUP BY 1, 2, 3, 4, 5, 6`), args(start, to)...)
+		if err != nil {
+			return nil, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+		}
+		counts = append(counts, raw...)
+	}
+
+	return counts, nil
+}
+
+type dayCounts struct {
+	stats      *UrlStatistics
+	dimensions map[string]map[string]int
+}
+
+// aggregateStats merges counts of all sources into per day statistics.
+func aggregateStats(domain string, urlID string, counts []dimensionCount, includeBots bool) []UrlStatistics {
+	days := map[time.Time]*dayCounts{}
+	for _, c := range counts {
+		date := c.Date.UTC()
+		day, ok := days[date]
+		if !ok {
+			day = &dayCounts{
+				stats:      &UrlStatistics{UrlDomain: domain, UrlID: urlID, Date: date},
+				dimensions: map[string]map[string]int{},
+			}
+			days[date] = day
+		}
+
+		if c.Dimension == dimensionTotal && c.IsBot {
+			day.stats.BotClicks += c.Clicks
+		}
+		if c.IsBot && !includeBots {
+			continue
+		}
+
+		if c.Dimension == dimensionTotal {
+			day.stats.TotalClicks += c.Clicks
+			continue
+		}
+		if day.dimensions[c.Dimension] == nil {
+			day.dimensions[c.Dimension] = map[string]int{}
+		}
+		day.dimensions[c.Dimension][c.Key] += c.Clicks
+	}
+
+	stats := make([]UrlStatistics, 0, len(days))
+	for _, day := range days {
+		s := day.stats
+		s.ByGeo = []ClicksByGeo{}
+		for _, kc := range sortedCounts(day.dimensions[dimensionGeo]) {
+			s.ByGeo = append(s.ByGeo, ClicksByGeo{CountryCode: kc.key, Clicks: kc.clicks})
+		}
+		s.ByReferer = []ClicksByReferer{}
+		for _, kc := range sortedCounts(day.dimensions[dimensionReferer]) {
+			s.ByReferer = append(s.ByReferer, ClicksByReferer{Referer: kc.key, Clicks: kc.clicks})
+		}
+		s.ByDevice = []ClicksByDevice{}
+		for _, kc := range sortedCounts(day.dimensions[dimensionDevice]) {
+			s.ByDevice = append(s.ByDevice, ClicksByDevice{Device: kc.key, Clicks: kc.clicks})
+		}
+		s.ByOS = []ClicksByOS{}
+		for _, kc := range sortedCounts(day.dimensions[dimensionOS]) {
+			s.ByOS = append(s.ByOS, ClicksByOS{OS: kc.key, Clicks: kc.clicks})
+		}
+		s.ByBrowser = []ClicksByBrowser{}
+		for _, kc := range sortedCounts(day.dimensions[dimensionBrowser]) {
+			s.ByBrowser = append(s.ByBrowser, ClicksByBrowser{Browser: kc.key, Clicks: kc.clicks})
+		}
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Date.After(stats[j].Date) })
+
+	return stats
+}
+
+// addConversions sets the conversions of the days in stats. Conversions are
+// bucketed by the day of their click, so each of them has a day in stats.
+func addConversions(stats []UrlStatistics, conversions []dayConversions) {
+	days := map[time.Time]dayConversions{}
+	for _, c := range conversions {
+		days[c.Date.UTC()] = c
+	}
+
+	for i := range stats {
+		c, ok := days[stats[i].Date]
+		if !ok {
+			continue
+		}
+		stats[i].Conversions = c.Conversions
+		stats[i].ConversionValue = c.Value
+		if stats[i].TotalClicks > 0 {
+			stats[i].ConversionRate = math.Round(float64(c.Conversions)/float64(stats[i].TotalClicks)*10000) / 10000
+		}
+	}
+}
+
+// aggregateAccountStats merges counts of humans on all links into per day
+// statistics.
+func aggregateAccountStats(counts []dimensionCount) []AccountStatistics {
+	type accountDay struct {
+		stats      *AccountStatistics
+		links      map[linkKey]int
+		dimensions map[string]map[string]int
+	}
+
+	days := map[time.Time]*accountDay{}
+	for _, c := range counts {
+		if c.IsBot {
+			continue
+		}
+
+		date := c.Date.UTC()
+		day, ok := days[date]
+		if !ok {
+			day = &accountDay{
+				stats:      &AccountStatistics{Date: date},
+				links:      map[linkKey]int{},
+				dimensions: map[string]map[string]int{},
+			}
+			days[date] = day
+		}
+
+		if c.Dimension == dimensionTotal {
+			day.stats.TotalClicks += c.Clicks
+			day.links[linkKey{domain: c.URLDomain, id: c.URLID}] += c.Clicks
+			continue
+		}
+		if day.dimensions[c.Dimension] == nil {
+			day.dimensions[c.Dimension] = map[string]int{}
+		}
+		day.dimensions[c.Dimension][c.Key] += c.Clicks
+	}
+
+	stats := make([]AccountStatistics, 0, len(days))
+	for _, day := range days {
+		s := day.stats
+		s.ByLink = sortedLinks(day.links)
+		s.ByGeo = []ClicksByGeo{}
+		for _, kc := range sortedCounts(day.dimensions[dimensionGeo]) {
+			s.ByGeo = append(s.ByGeo, ClicksByGeo{CountryCode: kc.key, Clicks: kc.clicks})
+		}
+		s.ByReferer = []ClicksByReferer{}
+		for _, kc := range sortedCounts(day.dimensions[dimensionReferer]) {
+			s.ByReferer = append(s.ByReferer, ClicksByReferer{Referer: kc.key, Clicks: kc.clicks})
+		}
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Date.After(stats[j].Date) })
+
+	return stats
+}
+
+type keyCount struct {
+	key    string
+	clicks int
+}
+
+// sortedCounts orders counts by clicks, most clicked first.
+func sortedCounts(counts map[string]int) []keyCount {
+	sorted := make([]keyCount, 0, len(counts))
+	for key, clicks := range counts {
+		sorted = append(sorted, keyCount{key: key, clicks: clicks})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].clicks != sorted[j].clicks {
+			return sorted[i].clicks > sorted[j].clicks
+		}
+		return sorted[i].key < sorted[j].key
+	})
+
+	return sorted
+}
+
+func minTime(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -2,38 +2,213 @@ package analytics
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/privacy"
 )
 
 type UseCases struct {
-	repo URLStatisticsRepository
+	repo     URLStatisticsRepository
+	visitors VisitorsRepository
+	parser   UserAgentParser
+	referers *RefererNormalizer
+	bots     *BotClassifier
+	stream   ClickStream
+	privacy  *privacy.UseCases
+	// dashboards caches account dashboards
+	dashboards DashboardCache
 }
 
-func NewUseCases(repo URLStatisticsRepository) *UseCases {
+func NewUseCases(
+	repo URLStatisticsRepository,
+	visitors VisitorsRepository,
+	parser UserAgentParser,
+	referers *RefererNormalizer,
+	bots *BotClassifier,
+	stream ClickStream,
+	privacy *privacy.UseCases,
+	dashboards DashboardCache,
+) *UseCases {
 	return &UseCases{
-		repo: repo,
+		repo:     repo,
+		visitors: visitors,
+		parser:   parser,
+		referers: referers,
+		bots:     bots,
+		stream:   stream,
+		privacy:  privacy,
+
+		dashboards: dashboards,
 	}
 }
 
+// AddClick records the click clickID on a link of authorID. The visitor's
+// address only ever leaves memory anonymized or hashed; visitors refusing
+// tracking are counted without any details. referer is normalized to its
+// host, empty for direct visits.
 func (s *UseCases) AddClick(
 	ctx context.Context,
+	clickID uuid.UUID,
+	authorID uuid.UUID,
+	domain string,
 	urlID string,
+	visitor Visitor,
 	countryCode *string,
-	referer *string,
+	referer string,
 ) error {
-	if err := s.repo.AddClick(ctx, urlID); err != nil {
-		return err
+	now := time.Now().UTC()
+	anonymous := s.privacy.RefusesTracking(visitor.DoNotTrack, visitor.GlobalPrivacyControl)
+
+	// the fingerprint hashes the raw address with the salt of the day, the
+	// anonymized one would merge visitors behind one network into one. It is
+	// the rate key of anonymous visitors too, but never leaves memory for
+	// them.
+	fingerprint, err := s.fingerprint(ctx, Day(now), visitor)
+	if err != nil {
+		ctxlogging.Get(ctx).Warn("failed to fingerprint visitor", "err", err, "url_id", urlID)
 	}
 
-	if countryCode != nil {
-		if err := s.repo.AddGeoClick(ctx, urlID, *countryCode); err != nil {
-			return err
+	click := &Click{
+		ID:        clickID,
+		URLDomain: domain,
+		URLID:     urlID,
+		ClickedAt: now,
+		IsBot:     s.bots.IsBot(ctx, visitor, fingerprint, anonymous),
+	}
+	if !anonymous {
+		ua := s.parser.Parse(visitor.UserAgent)
+		click.CountryCode = countryCode
+		click.DeviceType = &ua.Device
+		click.OSFamily = &ua.OS
+		click.BrowserFamily = &ua.Browser
+		if normalized, ok := s.referers.Normalize(referer); ok && s.privacy.CaptureReferer(ctx, authorID) {
+			click.Referer = &normalized
 		}
 	}
 
-	if referer != nil {
-		if err := s.repo.AddRefererClick(ctx, urlID, *referer); err != nil {
-			return err
-		}
+	if err := s.repo.AddClick(ctx, click); err != nil {
+		return err
+	}
+
+	if click.IsBot {
+		return nil
+	}
+
+	event := ClickEvent{
+		URLDomain:   domain,
+		URLID:       urlID,
+		ClickedAt:   now,
+		CountryCode: click.CountryCode,
+		Referer:     click.Referer,
+		Device:      click.DeviceType,
+	}
+	if err := s.stream.Publish(ctx, event); err != nil {
+		ctxlogging.Get(ctx).Warn("failed to publish click", "err", err, "url_id", urlID)
+	}
+
+	// unique visitors are an estimate on top of the clicks, losing one
+	// must not fail the click
+	if anonymous || fingerprint == "" {
+		return nil
+	}
+
+	if err := s.visitors.Add(ctx, domain, urlID, Day(now), fingerprint); err != nil {
+		ctxlogging.Get(ctx).Warn("failed to count unique visitor", "err", err, "url_id", urlID)
 	}
+
 	return nil
 }
+
+// Trackable reports whether clicks of visitor may be followed up to
+// conversions.
+func (s *UseCases) Trackable(visitor Visitor) bool {
+	return !s.privacy.RefusesTracking(visitor.DoNotTrack, visitor.GlobalPrivacyControl)
+}
+
+func (s *UseCases) Click(ctx context.Context, clickID uuid.UUID) (*Click, error) {
+	return s.repo.Click(ctx, clickID)
+}
+
+// Convert attributes a conversion with an optional value to click. A click
+// converts only once, later conversions fail with ErrAlreadyConverted.
+func (s *UseCases) Convert(ctx context.Context, click *Click, value *float64) (*Conversion, error) {
+	if value != nil && *value < 0 {
+		return nil, ErrInvalidValue
+	}
+
+	conversion := &Conversion{
+		ClickID:   click.ID,
+		URLDomain: click.URLDomain,
+		URLID:     click.URLID,
+		ClickedAt: click.ClickedAt,
+		Value:     value,
+	}
+	if err := s.repo.AddConversion(ctx, conversion); err != nil {
+		return nil, err
+	}
+
+	return conversion, nil
+}
+
+// SubscribeClicks streams clicks of humans on the url as they happen, see
+// ClickStream.Subscribe.
+func (s *UseCases) SubscribeClicks(ctx context.Context, domain string, urlID string) (<-chan ClickEvent, func(), error) {
+	return s.stream.Subscribe(ctx, domain, urlID)
+}
+
+func (s *UseCases) fingerprint(ctx context.Context, day time.Time, visitor Visitor) (string, error) {
+	salt, err := s.visitors.Salt(ctx, day)
+	if err != nil {
+		return "", err
+	}
+
+	return visitor.Fingerprint(salt), nil
+}
+
+// Stats returns per day statistics of the url, newest day first. Clicks of
+// bots are left out of the totals and breakdowns unless the filter includes
+// them. A zero From starts with the first click, a zero To ends today.
+func (s *UseCases) Stats(ctx context.Context, domain string, urlID string, filter StatsFilter) ([]UrlStatistics, error) {
+	if !filter.From.IsZero() {
+		filter.From = Day(filter.From)
+	}
+	if filter.To.IsZero() {
+		filter.To = Day(time.Now()).Add(24 * time.Hour)
+	}
+
+	stats, err := s.repo.Stats(ctx, domain, urlID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range stats {
+		s.classifyReferers(stats[i].ByReferer)
+	}
+
+	days := make([]time.Time, len(stats))
+	for i := range stats {
+		days[i] = Day(stats[i].Date)
+	}
+
+	counts, err := s.visitors.Count(ctx, domain, urlID, days)
+	if err != nil {
+		ctxlogging.Get(ctx).Warn("failed to count unique visitors", "err", err, "url_id", urlID)
+		return stats, nil
+	}
+
+	for i := range stats {
+		stats[i].UniqueVisitors = counts[days[i]]
+	}
+
+	return stats, nil
+}
+
+// classifyReferers sets the traffic source of referers. Sources are told at
+// read time, so changed rules apply to past clicks as well.
+func (s *UseCases) classifyReferers(referers []ClicksByReferer) {
+	for i := range referers {
+		referers[i].SourceType = s.referers.Source(referers[i].Referer)
+	}
+}
@@ -0,0 +1,76 @@
+package analytics
+
+import "regexp"
+
+type uaRule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+func newUARules(rules [][2]string) []uaRule {
+	compiled := make([]uaRule, len(rules))
+	for i, rule := range rules {
+		compiled[i] = uaRule{name: rule[0], pattern: regexp.MustCompile(`(?i)` + rule[1])}
+	}
+
+	return compiled
+}
+
+// deviceRules are checked in order, the first match wins. Android tablets
+// are the Android user agents without the "Mobile" token.
+var deviceRules = newUARules([][2]string{
+	{DEVICE_TABLET, `ipad|tablet|kindle|silk/|playbook`},
+	{DEVICE_MOBILE, `mobi|iphone|ipod|windows phone|blackberry|opera mini`},
+	{DEVICE_TABLET, `android`},
+	{DEVICE_DESKTOP, `windows nt|macintosh|x11|cros|linux`},
+})
+
+// osRules are checked in order. iOS user agents claim to be "like Mac OS X"
+// and Android ones to run Linux, so the specific rules go first.
+var osRules = newUARules([][2]string{
+	{"Windows Phone", `windows phone`},
+	{"Windows", `windows`},
+	{"iOS", `iphone|ipad|ipod|iphone os|cpu os`},
+	{"Android", `android`},
+	{"Mac OS", `mac os x|macintosh`},
+	{"Chrome OS", `cros`},
+	{"Linux", `linux|x11`},
+})
+
+// browserRules are checked in order. Most browsers mention Chrome and Safari
+// as well, so those come last.
+var browserRules = newUARules([][2]string{
+	{"Edge", `edg(e|a|ios)?/`},
+	{"Opera", `opr/|opera`},
+	{"Samsung Internet", `samsungbrowser/`},
+	{"Yandex Browser", `yabrowser/`},
+	{"Firefox", `firefox/|fxios/`},
+	{"Chrome", `chrome/|crios/|chromium/`},
+	{"Internet Explorer", `msie |trident/`},
+	{"Safari", `safari/`},
+})
+
+// RegexUserAgentParser classifies user agents with ordered regex rules.
+type RegexUserAgentParser struct{}
+
+func NewRegexUserAgentParser() *RegexUserAgentParser {
+	return &RegexUserAgentParser{}
+}
+
+func (p *RegexUserAgentParser) Parse(userAgent string) UserAgent {
+	return UserAgent{
+		Device:  matchUARule(deviceRules, userAgent, DEVICE_OTHER),
+		OS:      matchUARule(osRules, userAgent, FAMILY_OTHER),
+		Browser: matchUARule(browserRules, userAgent, FAMILY_OTHER),
+	}
+}
+
+func matchUARule(rules []uaRule, userAgent string, fallback string) string {
+	for _, rule := range rules {
+		if rule.pattern.MatchString(userAgent) {
+			return rule.name
+		}
+	}
+
+	return fallback
+}
@@ -0,0 +1,37 @@
+package analytics
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Visitor identifies the client of a click. It is never stored as is, only
+// as a salted fingerprint.
+type Visitor struct {
+	IP             string
+	UserAgent      string
+	AcceptLanguage string
+	// DoNotTrack and GlobalPrivacyControl are the DNT and Sec-GPC headers.
+	DoNotTrack           string
+	GlobalPrivacyControl string
+}
+
+// Fingerprint hashes the visitor with the salt of the day. Salts are
+// rotated daily and dropped afterwards, so fingerprints of different days
+// can not be linked and can not be reversed by enumerating IPs.
+func (v Visitor) Fingerprint(salt string) string {
+	h := sha256.New()
+	h.Write([]byte(salt))
+	h.Write([]byte{0})
+	h.Write([]byte(v.IP))
+	h.Write([]byte{0})
+	h.Write([]byte(v.UserAgent))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Day truncates t to the UTC day statistics are grouped by.
+func Day(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
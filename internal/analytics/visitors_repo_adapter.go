@@ -0,0 +1,86 @@
+package analytics
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	visitorsKeyPrefix = "uv:"
+	saltKeyPrefix     = "uv-salt:"
+	// a salt outlives its day only long enough for late clicks of the day
+	saltTTL = 48 * time.Hour
+)
+
+// RedisVisitorsRepository estimates unique visitors with one HyperLogLog per
+// link and day.
+type RedisVisitorsRepository struct {
+	client    *redis.Client
+	retention time.Duration
+}
+
+func NewRedisVisitorsRepository(client *redis.Client, retention time.Duration) *RedisVisitorsRepository {
+	return &RedisVisitorsRepository{
+		client:    client,
+		retention: retention,
+	}
+}
+
+func visitorsKey(domain string, urlID string, day time.Time) string {
+	return visitorsKeyPrefix + domain + ":" + urlID + ":" + day.Format(time.DateOnly)
+}
+
+// Salt returns the salt of day shared by all instances. The first caller of
+// the day generates it.
+func (r *RedisVisitorsRepository) Salt(ctx context.Context, day time.Time) (string, error) {
+	key := saltKeyPrefix + day.Format(time.DateOnly)
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	if err := r.client.SetNX(ctx, key, hex.EncodeToString(b), saltTTL).Err(); err != nil {
+		return "", err
+	}
+
+	return r.client.Get(ctx, key).Result()
+}
+
+func (r *RedisVisitorsRepository) Add(ctx context.Context, domain string, urlID string, day time.Time, fingerprint string) error {
+	key := visitorsKey(domain, urlID, day)
+
+	pipe := r.client.TxPipeline()
+	pipe.PFAdd(ctx, key, fingerprint)
+	pipe.Expire(ctx, key, r.retention)
+	_, err := pipe.Exec(ctx)
+
+	return err
+}
+
+func (r *RedisVisitorsRepository) Count(ctx context.Context, domain string, urlID string, days []time.Time) (map[time.Time]int64, error) {
+	counts := make(map[time.Time]int64, len(days))
+	if len(days) == 0 {
+		return counts, nil
+	}
+
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.IntCmd, len(days))
+	for i, day := range days {
+		cmds[i] = pipe.PFCount(ctx, visitorsKey(domain, urlID, day))
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	for i, day := range days {
+		counts[day] = cmds[i].Val()
+	}
+
+	return counts, nil
+}
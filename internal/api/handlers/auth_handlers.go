@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"time"
 	"github.com/google/uuid"
@@ -16,7 +18,19 @@ import (
 
 const API_COOKIE_PATH = "/api/v1"
 
-func registration(users *user.UseCases, tokens *token.UseCases) func(w http.ResponseWriter, r *http.Request) {
+// Clients that cannot keep cookies ask login and refresh for the tokens in the
+// body with ?token_mode=body and send them back as Bearer tokens, the refresh
+// token to /refresh and /logout. /logout takes the access token in its body.
+const (
+	TOKEN_MODE_PARAM = "token_mode"
+	TOKEN_MODE_BODY  = "body"
+)
+
+func bodyTokenMode(r *http.Request) bool {
+	return r.URL.Query().Get(TOKEN_MODE_PARAM) == TOKEN_MODE_BODY
+}
+
+func registration(users *user.UseCases, tokens *token.UseCases, clientIPs *ClientIPResolver) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		log := ctxlogging.Get(r.Context())
 		body, err := request.ParseAndValidateJson(validate, r.Body, RegistrationRequest{})
@@ -36,7 +50,7 @@ func registration(users *user.UseCases, tokens *token.UseCases) func(w http.Resp
 			return
 		}
 
-		tokenPair, err := tokens.NewPair(r.Context(), newUser.ID)
+		tokenPair, err := tokens.NewPair(r.Context(), newUser.ID, sessionClient(r, clientIPs))
 		if err != nil {
 			log.Error("unknown error on token generation", "err", err)
 			response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
@@ -71,13 +85,24 @@ func registration(users *user.UseCases, tokens *token.UseCases) func(w http.Resp
 
 func logout(tokens *token.UseCases) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		refTokenCookie, err := r.Cookie(middleware.COOKIE_REFRESH)
+		refreshToken, err := middleware.Token(r, middleware.COOKIE_REFRESH, config.Cfg().TokensConfig.Sources)
 		if err != nil {
 			response.WriteJsonErrorResponse(w, err, http.StatusUnprocessableEntity)
 			return
 		}
 
-		if err = tokens.RevokeToken(r.Context(), refTokenCookie.Value); err != nil {
+		body := LogoutRequest{}
+		if err = json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		accessToken := body.AccessToken
+		if accessCookie, err := r.Cookie(middleware.COOKIE_ACCESS); err == nil && accessToken == "" {
+			accessToken = accessCookie.Value
+		}
+
+		if err = tokens.RevokeToken(r.Context(), refreshToken, accessToken); err != nil {
 			response.WriteJsonErrorResponse(w, err, http.StatusUnprocessableEntity)
 			return
 		}
@@ -107,20 +132,25 @@ func logout(tokens *token.UseCases) func(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-func refresh(tokens *token.UseCases) func(w http.ResponseWriter, r *http.Request) {
+func refresh(tokens *token.UseCases, clientIPs *ClientIPResolver) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		refTokenCookie, err := r.Cookie(middleware.COOKIE_REFRESH)
+		refreshToken, err := middleware.Token(r, middleware.COOKIE_REFRESH, config.Cfg().TokensConfig.Sources)
 		if err != nil {
 			response.WriteJsonErrorResponse(w, err, http.StatusUnprocessableEntity)
 			return
 		}
 
-		newPair, err := tokens.RefreshTokenPair(r.Context(), refTokenCookie.Value)
+		newPair, err := tokens.RefreshTokenPair(r.Context(), refreshToken, sessionClient(r, clientIPs))
 		if err != nil {
 			response.WriteJsonErrorResponse(w, err, http.StatusUnprocessableEntity)
 			return
 		}
 
+		if bodyTokenMode(r) {
+			response.WriteJsonResponse(w, response.NewResponse(newPair), http.StatusOK)
+			return
+		}
+
 		accessCookie := &http.Cookie{
 			Name:     middleware.COOKIE_ACCESS,
 			Value:    newPair.Access,
@@ -144,7 +174,7 @@ func refresh(tokens *token.UseCases) func(w http.ResponseWriter, r *http.Request
 	}
 }
 
-func login(users *user.UseCases, tokens *token.UseCases) func(w http.ResponseWriter, r *http.Request) {
+func login(users *user.UseCases, tokens *token.UseCases, clientIPs *ClientIPResolver) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		log := ctxlogging.Get(r.Context())
 		body, err := request.ParseAndValidateJson(validate, r.Body, LoginRequest{})
@@ -164,13 +194,18 @@ func login(users *user.UseCases, tokens *token.UseCases) func(w http.ResponseWri
 			return
 		}
 
-		tokenPair, err := tokens.NewPair(r.Context(), u.ID)
+		tokenPair, err := tokens.NewPair(r.Context(), u.ID, sessionClient(r, clientIPs))
 		if err != nil {
 			log.Error("unknown error on token generation", "err", err)
 			response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
 			return
 		}
 
+		if bodyTokenMode(r) {
+			response.WriteJsonResponse(w, response.NewResponse(tokenPair), http.StatusOK)
+			return
+		}
+
 		accessCookie := &http.Cookie{
 			Name:     middleware.COOKIE_ACCESS,
 			Value:    tokenPair.Access,
@@ -215,3 +250,84 @@ func me(userRepo user.UserRepository) func(w http.ResponseWriter, r *http.Reques
 		}), http.StatusOK)
 	}
 }
+
+// changePassword sets a new password and logs the user out of every other
+// session, keeping the one the change was made from.
+func changePassword(users *user.UseCases, tokens *token.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		sid := r.Context().Value(middleware.CTX_SESSION_ID).(uuid.UUID)
+
+		body, err := request.ParseAndValidateJson(validate, r.Body, ChangePasswordRequest{})
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		if err = users.ChangePassword(r.Context(), uid, body.CurrentPassword, body.NewPassword); err != nil {
+			if errors.Is(err, user.ErrPasswordIncorrect) {
+				response.WriteJsonErrorResponse(w, err, http.StatusForbidden)
+			} else {
+				log.Error("unknown error on password change", "err", err)
+				response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		if err = tokens.RevokeSessions(r.Context(), uid, sid); err != nil {
+			log.Error("failed to revoke sessions after password change", "err", err)
+			response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		response.WriteJsonResponse(w, response.NewResponse(struct{}{}), http.StatusNoContent)
+	}
+}
+
+// deleteAccount removes the user with everything they own and logs them out
+// of every session.
+func deleteAccount(users *user.UseCases, tokens *token.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+
+		body, err := request.ParseAndValidateJson(validate, r.Body, DeleteAccountRequest{})
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		if err = users.CheckPassword(r.Context(), uid, body.Password); err != nil {
+			if errors.Is(err, user.ErrPasswordIncorrect) {
+				response.WriteJsonErrorResponse(w, err, http.StatusForbidden)
+			} else {
+				log.Error("unknown error on account deletion", "err", err)
+				response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		// sessions go first, a deleted account must not stay logged in if
+		// revoking fails
+		if err = tokens.RevokeSessions(r.Context(), uid, uuid.Nil); err != nil {
+			log.Error("failed to revoke sessions of deleted account", "err", err)
+			response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		if err = users.DeleteAccount(r.Context(), uid, body.Password); err != nil {
+			if errors.Is(err, user.ErrPasswordIncorrect) {
+				response.WriteJsonErrorResponse(w, err, http.StatusForbidden)
+			} else {
+				log.Error("unknown error on account deletion", "err", err)
+				response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		clearTokenCookies(w)
+
+		response.WriteJsonResponse(w, response.NewResponse(struct{}{}), http.StatusNoContent)
+	}
+}
@@ -14,13 +14,22 @@ func AuthRouter(
 	userRepo user.UserRepository,
 	users *user.UseCases,
 	tokens *token.UseCases,
+	clientIPs *ClientIPResolver,
 ) chi.Router {
 	r := chi.NewRouter()
-	r.Post("/registration", registration(users, tokens))
-	r.Post("/login", login(users, tokens))
+	r.Post("/registration", registration(users, tokens, clientIPs))
+	r.Post("/login", login(users, tokens, clientIPs))
 	r.Post("/logout", logout(tokens))
-	r.Post("/refresh", refresh(tokens))
+	r.Post("/refresh", refresh(tokens, clientIPs))
 	r.With(middleware.Auth(extractor, userRepo)).Get("/me", http.HandlerFunc(me(userRepo)))
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Auth(extractor, userRepo))
+		r.Delete("/me", deleteAccount(users, tokens))
+		r.Put("/password", changePassword(users, tokens))
+		r.Get("/sessions", sessions(tokens))
+		r.Delete("/sessions", logoutEverywhere(tokens))
+		r.Delete("/sessions/{id}", revokeSession(tokens))
+	})
 	return r
 }
 
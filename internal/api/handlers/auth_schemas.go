@@ -1,6 +1,10 @@
 package handlers
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
@@ -12,7 +16,31 @@ type RegistrationRequest struct {
 	Password string `json:"password" validate:"required,min=6"`
 }
 
+// LogoutRequest is the optional body of a logout, the access token is
+// revoked together with the session.
+type LogoutRequest struct {
+	AccessToken string `json:"access_token"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=6"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
 type UserDTO struct {
 	Email string `json:"email"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+type SessionDTO struct {
+	ID              uuid.UUID `json:"id"`
+	CreatedAt       time.Time `json:"created_at"`
+	LastRefreshedAt time.Time `json:"last_refreshed_at"`
+	IP              string    `json:"ip"`
+	UserAgent       string    `json:"user_agent"`
+	Current         bool      `json:"current"`
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ClientIPResolver finds the address of the client behind trusted proxies.
+// The forwarding header is read only on connections from a trusted proxy,
+// and only its right-most entries appended by trusted proxies are skipped:
+// everything left of them is whatever the client sent.
+type ClientIPResolver struct {
+	header  string
+	trusted []netip.Prefix
+}
+
+// NewClientIPResolver parses trustedProxies as addresses or CIDR networks.
+// An empty header makes the resolver always use the connection address.
+func NewClientIPResolver(header string, trustedProxies []string) (*ClientIPResolver, error) {
+	trusted := make([]netip.Prefix, 0, len(trustedProxies))
+	for _, proxy := range trustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			addr, err := netip.ParseAddr(proxy)
+			if err != nil {
+				return nil, err
+			}
+			trusted = append(trusted, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, err
+		}
+		trusted = append(trusted, prefix.Masked())
+	}
+
+	return &ClientIPResolver{
+		header:  header,
+		trusted: trusted,
+	}, nil
+}
+
+// IP returns the address of the client of r.
+func (c *ClientIPResolver) IP(r *http.Request) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		remote = host
+	}
+
+	if c.header == "" || !c.isTrusted(remote) {
+		return remote
+	}
+
+	entries := strings.Split(strings.Join(r.Header.Values(c.header), ","), ",")
+	client := remote
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := strings.TrimSpace(entries[i])
+		if entry == "" {
+			continue
+		}
+
+		client = entry
+		if !c.isTrusted(entry) {
+			break
+		}
+	}
+
+	return client
+}
+
+func (c *ClientIPResolver) isTrusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range c.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/analytics"
+	"roadmap.restapi/internal/api/middleware"
+	"roadmap.restapi/internal/api/request"
+	"roadmap.restapi/internal/api/response"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/url"
+)
+
+// pixelGIF is a transparent 1x1 GIF.
+var pixelGIF = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00,
+	0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x01, 0x00,
+	0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// conversionPixel records a conversion of the click in the cid parameter.
+// Anybody can load the pixel, so it never takes a value, conversions with a
+// value are reported through the authenticated postback. The pixel is served
+// whatever happens, a broken image on the page of a customer would help
+// nobody.
+func conversionPixel(stats *analytics.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		query := r.URL.Query()
+
+		w.Header().Set("Content-Type", "image/gif")
+		w.Header().Set("Cache-Control", "no-store")
+		defer w.Write(pixelGIF)
+
+		clickID, err := uuid.Parse(query.Get(analytics.CLICK_ID_PARAM))
+		if err != nil {
+			log.Debug("conversion pixel without click id", "cid", query.Get(analytics.CLICK_ID_PARAM))
+			return
+		}
+
+		click, err := stats.Click(r.Context(), clickID)
+		if err != nil {
+			if !errors.Is(err, analytics.ErrClickNotFound) {
+				log.Error("failed to find converted click", "clickID", clickID, "err", err)
+			}
+			return
+		}
+
+		if _, err := stats.Convert(r.Context(), click, nil); err != nil {
+			if !errors.Is(err, analytics.ErrAlreadyConverted) {
+				log.Error("failed to record conversion", "clickID", clickID, "err", err)
+			}
+			return
+		}
+
+		log.Debug("conversion recorded", "clickID", clickID, "urlID", click.URLID)
+	}
+}
+
+// conversionPostback records a conversion reported by the backend of the
+// author of the clicked link.
+func conversionPostback(urls *url.UseCases, stats *analytics.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		body, err := request.ParseAndValidateJson(validate, r.Body, ConversionRequest{})
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		click, err := stats.Click(r.Context(), uuid.MustParse(body.ClickID))
+		if err != nil {
+			if errors.Is(err, analytics.ErrClickNotFound) {
+				response.WriteJsonErrorResponse(w, err, http.StatusNotFound)
+			} else {
+				log.Error("unhandled error", "err", err)
+				response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		if _, err := urls.Get(r.Context(), uid, click.URLDomain, click.URLID); err != nil {
+			if errors.Is(err, url.ErrUserIsNotAuthor) {
+				response.WriteJsonErrorResponse(w, err, http.StatusForbidden)
+			} else if errors.Is(err, url.ErrURLNotFound) {
+				response.WriteJsonErrorResponse(w, analytics.ErrClickNotFound, http.StatusNotFound)
+			} else {
+				log.Error("unhandled error", "err", err)
+				response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		conversion, err := stats.Convert(r.Context(), click, body.Value)
+		if err != nil {
+			if errors.Is(err, analytics.ErrAlreadyConverted) {
+				response.WriteJsonErrorResponse(w, err, http.StatusConflict)
+			} else if errors.Is(err, analytics.ErrInvalidValue) {
+				response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			} else {
+				log.Error("unhandled error", "err", err)
+				response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		log.Debug("conversion recorded", "clickID", click.ID, "urlID", click.URLID)
+		response.WriteJsonResponse(w, response.NewResponse(NewConversionDTO(conversion)), http.StatusCreated)
+	}
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"roadmap.restapi/internal/analytics"
+	"roadmap.restapi/internal/api/middleware"
+	"roadmap.restapi/internal/token"
+	"roadmap.restapi/internal/url"
+	"roadmap.restapi/internal/user"
+)
+
+// ConversionsRouter takes server-to-server postbacks of link authors.
+func ConversionsRouter(
+	extractor token.ClaimsExtractor,
+	userRepo user.UserRepository,
+	urls *url.UseCases,
+	stats *analytics.UseCases,
+) chi.Router {
+	r := chi.NewRouter()
+	r.Use(middleware.Auth(extractor, userRepo))
+
+	r.Post("/", http.HandlerFunc(conversionPostback(urls, stats)))
+
+	return r
+}
+
+// TrackingRouter serves the public conversion pixel.
+func TrackingRouter(stats *analytics.UseCases) chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/pixel.gif", http.HandlerFunc(conversionPixel(stats)))
+
+	return r
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/analytics"
+)
+
+type ConversionRequest struct {
+	ClickID string   `json:"click_id" validate:"required,uuid"`
+	Value   *float64 `json:"value" validate:"omitempty,gte=0"`
+}
+
+type ConversionDTO struct {
+	ID          uuid.UUID `json:"id"`
+	ClickID     uuid.UUID `json:"click_id"`
+	URLDomain   string    `json:"url_domain"`
+	URLID       string    `json:"url_id"`
+	ClickedAt   time.Time `json:"clicked_at"`
+	ConvertedAt time.Time `json:"converted_at"`
+	Value       *float64  `json:"value"`
+}
+
+func NewConversionDTO(c *analytics.Conversion) ConversionDTO {
+	return ConversionDTO{
+		ID:          c.ID,
+		ClickID:     c.ClickID,
+		URLDomain:   c.URLDomain,
+		URLID:       c.URLID,
+		ClickedAt:   c.ClickedAt,
+		ConvertedAt: c.ConvertedAt,
+		Value:       c.Value,
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/analytics"
+	"roadmap.restapi/internal/api/middleware"
+	"roadmap.restapi/internal/api/response"
+	"roadmap.restapi/internal/ctxlogging"
+)
+
+// dashboardFilter reads the optional from and to days of the dashboard, both
+// inclusive, and the length of its top lists.
+func dashboardFilter(r *http.Request) (analytics.DashboardFilter, error) {
+	filter := analytics.DashboardFilter{}
+	query := r.URL.Query()
+
+	if raw := query.Get("from"); raw != "" {
+		from, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			return filter, err
+		}
+		filter.From = from
+	}
+
+	if raw := query.Get("to"); raw != "" {
+		to, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			return filter, err
+		}
+		filter.To = to.AddDate(0, 0, 1)
+	}
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return filter, err
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+// dashboard responds with the part of the user's dashboard picked by part.
+func dashboard(stats *analytics.UseCases, part func(d *analytics.Dashboard) any) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+
+		filter, err := dashboardFilter(r)
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		result, err := stats.Dashboard(r.Context(), uid, filter)
+		if err != nil {
+			if errors.Is(err, analytics.ErrInvalidRange) || errors.Is(err, analytics.ErrInvalidLimit) {
+				response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			} else {
+				log.Error("unhandled error", "err", err)
+				response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		response.WriteJsonResponse(w, response.NewResponse(part(result)), http.StatusOK)
+	}
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"roadmap.restapi/internal/analytics"
+	"roadmap.restapi/internal/api/middleware"
+	"roadmap.restapi/internal/token"
+	"roadmap.restapi/internal/user"
+)
+
+func DashboardRouter(
+	extractor token.ClaimsExtractor,
+	userRepo user.UserRepository,
+	stats *analytics.UseCases,
+) chi.Router {
+	r := chi.NewRouter()
+	r.Use(middleware.Auth(extractor, userRepo))
+
+	r.Get("/", http.HandlerFunc(dashboard(stats, func(d *analytics.Dashboard) any { return d })))
+	r.Get("/top-links", http.HandlerFunc(dashboard(stats, func(d *analytics.Dashboard) any { return d.TopLinks })))
+	r.Get("/trend", http.HandlerFunc(dashboard(stats, func(d *analytics.Dashboard) any { return d.Trend })))
+	r.Get("/top-referers", http.HandlerFunc(dashboard(stats, func(d *analytics.Dashboard) any { return d.TopReferers })))
+	r.Get("/top-countries", http.HandlerFunc(dashboard(stats, func(d *analytics.Dashboard) any { return d.TopCountries })))
+	r.Get("/comparison", http.HandlerFunc(dashboard(stats, func(d *analytics.Dashboard) any { return d.Comparison })))
+
+	return r
+}
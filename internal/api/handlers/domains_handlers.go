@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/api/middleware"
+	"roadmap.restapi/internal/api/request"
+	"roadmap.restapi/internal/api/response"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/customdomain"
+)
+
+func writeDomainError(w http.ResponseWriter, r *http.Request, err error) {
+	log := ctxlogging.Get(r.Context())
+	if errors.Is(err, customdomain.ErrUserIsNotOwner) {
+		response.WriteJsonErrorResponse(w, err, http.StatusForbidden)
+	} else if errors.Is(err, customdomain.ErrDomainNotFound) {
+		response.WriteJsonErrorResponse(w, err, http.StatusNotFound)
+	} else if errors.Is(err, customdomain.ErrDomainAlreadyExists) || errors.Is(err, customdomain.ErrDomainTaken) ||
+		errors.Is(err, customdomain.ErrDomainHasForeignLinks) {
+		response.WriteJsonErrorResponse(w, err, http.StatusConflict)
+	} else if errors.Is(err, customdomain.ErrInvalidDomain) || errors.Is(err, customdomain.ErrVerificationFailed) {
+		response.WriteJsonErrorResponse(w, err, http.StatusUnprocessableEntity)
+	} else {
+		log.Error("unhandled error", "err", err)
+		response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+	}
+}
+
+func domainList(domains *customdomain.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+
+		list, err := domains.ByUser(r.Context(), uid)
+		if err != nil {
+			writeDomainError(w, r, err)
+			return
+		}
+
+		dtos := make([]DomainDTO, 0, len(list))
+		for i := range list {
+			dtos = append(dtos, NewDomainDTO(&list[i]))
+		}
+
+		response.WriteJsonResponse(w, response.NewResponse(dtos), http.StatusOK)
+	}
+}
+
+func domainCreate(domains *customdomain.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		body, err := request.ParseAndValidateJson(validate, r.Body, DomainCreateRequest{})
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		domain, err := domains.Register(r.Context(), uid, body.Name)
+		if err != nil {
+			writeDomainError(w, r, err)
+			return
+		}
+
+		log.Debug("domain registered", "domain", domain.Name)
+		response.WriteJsonResponse(w, response.NewResponse(NewDomainDTO(domain)), http.StatusCreated)
+	}
+}
+
+func domainVerify(domains *customdomain.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		domainID, err := uuid.Parse(chi.URLParam(r, "domain-id"))
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		domain, err := domains.Verify(r.Context(), uid, domainID)
+		if err != nil {
+			writeDomainError(w, r, err)
+			return
+		}
+
+		log.Debug("domain verified", "domain", domain.Name)
+		response.WriteJsonResponse(w, response.NewResponse(NewDomainDTO(domain)), http.StatusOK)
+	}
+}
+
+func domainDelete(domains *customdomain.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		domainID, err := uuid.Parse(chi.URLParam(r, "domain-id"))
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		if err = domains.Delete(r.Context(), uid, domainID); err != nil {
+			writeDomainError(w, r, err)
+			return
+		}
+
+		log.Debug("domain deleted", "domainID", domainID)
+		response.WriteJsonResponse(w, struct{}{}, http.StatusNoContent)
+	}
+}
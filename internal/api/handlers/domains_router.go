@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"roadmap.restapi/internal/api/middleware"
+	"roadmap.restapi/internal/customdomain"
+	"roadmap.restapi/internal/token"
+	"roadmap.restapi/internal/user"
+)
+
+func DomainsRouter(
+	extractor token.ClaimsExtractor,
+	userRepo user.UserRepository,
+	domains *customdomain.UseCases,
+) chi.Router {
+	r := chi.NewRouter()
+	r.Use(middleware.Auth(extractor, userRepo))
+
+	r.Get("/", http.HandlerFunc(domainList(domains)))
+	r.Post("/", http.HandlerFunc(domainCreate(domains)))
+	r.Post("/{domain-id}/verify", http.HandlerFunc(domainVerify(domains)))
+	r.Delete("/{domain-id}", http.HandlerFunc(domainDelete(domains)))
+
+	return r
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/customdomain"
+)
+
+type DomainCreateRequest struct {
+	Name string `json:"name" validate:"required,fqdn"`
+}
+
+type VerificationRecordDTO struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type DomainDTO struct {
+	ID           uuid.UUID             `json:"id"`
+	Name         string                `json:"name"`
+	Verified     bool                  `json:"verified"`
+	VerifiedAt   *time.Time            `json:"verified_at"`
+	CreatedAt    time.Time             `json:"created_at"`
+	Verification VerificationRecordDTO `json:"verification"`
+}
+
+func NewDomainDTO(d *customdomain.Domain) DomainDTO {
+	return DomainDTO{
+		ID:         d.ID,
+		Name:       d.Name,
+		Verified:   d.IsVerified(),
+		VerifiedAt: d.VerifiedAt,
+		CreatedAt:  d.CreatedAt,
+		Verification: VerificationRecordDTO{
+			Type:  "TXT",
+			Name:  d.RecordName(),
+			Value: d.RecordValue(),
+		},
+	}
+}
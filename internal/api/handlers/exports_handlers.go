@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/api/middleware"
+	"roadmap.restapi/internal/api/response"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/export"
+	"roadmap.restapi/internal/url"
+)
+
+func writeExportError(w http.ResponseWriter, r *http.Request, err error) {
+	log := ctxlogging.Get(r.Context())
+	if errors.Is(err, export.ErrUserIsNotOwner) {
+		response.WriteJsonErrorResponse(w, err, http.StatusForbidden)
+	} else if errors.Is(err, export.ErrJobNotFound) {
+		response.WriteJsonErrorResponse(w, err, http.StatusNotFound)
+	} else if errors.Is(err, export.ErrJobNotFinished) {
+		response.WriteJsonErrorResponse(w, err, http.StatusConflict)
+	} else if errors.Is(err, export.ErrInvalidFormat) ||
+		errors.Is(err, export.ErrInvalidGranularity) ||
+		errors.Is(err, export.ErrInvalidColumn) ||
+		errors.Is(err, export.ErrInvalidRange) {
+		response.WriteJsonErrorResponse(w, err, http.StatusUnprocessableEntity)
+	} else {
+		log.Error("unhandled error", "err", err)
+		response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+	}
+}
+
+// exportQuery reads format, granularity, comma separated columns and the
+// from and to days, both inclusive, of an export request. async asks for a
+// background job whatever the size.
+func exportQuery(r *http.Request) (q export.Query, async bool, err error) {
+	query := r.URL.Query()
+	q = export.Query{
+		Format:      export.FORMAT_CSV,
+		Granularity: export.GRANULARITY_RAW,
+	}
+
+	if raw := query.Get("format"); raw != "" {
+		q.Format = export.Format(raw)
+	}
+	if raw := query.Get("granularity"); raw != "" {
+		q.Granularity = export.Granularity(raw)
+	}
+	for _, column := range strings.Split(query.Get("columns"), ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			q.Columns = append(q.Columns, column)
+		}
+	}
+
+	if raw := query.Get("from"); raw != "" {
+		if q.From, err = time.Parse(time.DateOnly, raw); err != nil {
+			return q, false, err
+		}
+	}
+	if raw := query.Get("to"); raw != "" {
+		to, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			return q, false, err
+		}
+		q.To = to.AddDate(0, 0, 1)
+	}
+
+	if raw := query.Get("async"); raw != "" {
+		if async, err = strconv.ParseBool(raw); err != nil {
+			return q, false, err
+		}
+	}
+
+	return q, async, nil
+}
+
+// serveExport streams the export right away or, when asked to or when it
+// is large, schedules a job and answers with it.
+func serveExport(w http.ResponseWriter, r *http.Request, exports *export.UseCases, q export.Query, async bool) {
+	log := ctxlogging.Get(r.Context())
+
+	if err := exports.Validate(&q); err != nil {
+		writeExportError(w, r, err)
+		return
+	}
+
+	if !async {
+		large, err := exports.IsLarge(r.Context(), q)
+		if err != nil {
+			writeExportError(w, r, err)
+			return
+		}
+		async = large
+	}
+
+	if async {
+		job, err := exports.Schedule(r.Context(), q)
+		if err != nil {
+			writeExportError(w, r, err)
+			return
+		}
+
+		log.Debug("export scheduled", "jobID", job.ID)
+		response.WriteJsonResponse(w, response.NewResponse(NewExportJobDTO(job)), http.StatusAccepted)
+		return
+	}
+
+	// streaming may take longer than the write timeout of the server
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		log.Warn("failed to clear write deadline of export", "err", err)
+	}
+
+	w.Header().Set("Content-Type", export.ContentType(q.Format))
+	w.Header().Set("Content-Disposition", `attachment; filename="clicks.`+string(q.Format)+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	// the status is sent already, a failure can only cut the body short
+	if rows, err := exports.Stream(r.Context(), q, w); err != nil {
+		log.Error("export failed", "err", err, "rows", rows)
+	}
+}
+
+func urlStatsExport(urls *url.UseCases, exports *export.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		urlID := chi.URLParam(r, "url-id")
+
+		domain := urlDomain(r)
+
+		q, async, err := exportQuery(r)
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		if _, err := urls.Get(r.Context(), uid, domain, urlID); err != nil {
+			if errors.Is(err, url.ErrUserIsNotAuthor) {
+				response.WriteJsonErrorResponse(w, err, http.StatusForbidden)
+			} else if errors.Is(err, url.ErrURLNotFound) {
+				response.WriteJsonErrorResponse(w, err, http.StatusNotFound)
+			} else {
+				log.Error("unhandled error", "err", err)
+				response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		q.UserID = uid
+		q.URLDomain = domain
+		q.URLID = urlID
+		serveExport(w, r, exports, q, async)
+	}
+}
+
+// accountExport exports the clicks of all urls of the user.
+func accountExport(exports *export.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+
+		q, async, err := exportQuery(r)
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		q.UserID = uid
+		serveExport(w, r, exports, q, async)
+	}
+}
+
+func exportJobList(exports *export.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+
+		jobs, err := exports.Jobs(r.Context(), uid)
+		if err != nil {
+			writeExportError(w, r, err)
+			return
+		}
+
+		dtos := make([]ExportJobDTO, 0, len(jobs))
+		for i := range jobs {
+			dtos = append(dtos, NewExportJobDTO(&jobs[i]))
+		}
+
+		response.WriteJsonResponse(w, response.NewResponse(dtos), http.StatusOK)
+	}
+}
+
+func exportJobGet(exports *export.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		jobID, err := uuid.Parse(chi.URLParam(r, "job-id"))
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		job, err := exports.Job(r.Context(), uid, jobID)
+		if err != nil {
+			writeExportError(w, r, err)
+			return
+		}
+
+		response.WriteJsonResponse(w, response.NewResponse(NewExportJobDTO(job)), http.StatusOK)
+	}
+}
+
+func exportJobDownload(exports *export.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		jobID, err := uuid.Parse(chi.URLParam(r, "job-id"))
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		job, file, err := exports.Download(r.Context(), uid, jobID)
+		if err != nil {
+			writeExportError(w, r, err)
+			return
+		}
+		defer file.Close()
+
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+			log.Warn("failed to clear write deadline of download", "err", err)
+		}
+
+		w.Header().Set("Content-Type", export.ContentType(job.Format))
+		w.Header().Set("Content-Disposition", `attachment; filename="clicks-`+job.ID.String()+`.`+string(job.Format)+`"`)
+		w.WriteHeader(http.StatusOK)
+
+		if _, err := io.Copy(w, file); err != nil {
+			log.Error("download failed", "jobID", job.ID, "err", err)
+		}
+	}
+}
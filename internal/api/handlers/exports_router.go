@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"roadmap.restapi/internal/api/middleware"
+	"roadmap.restapi/internal/export"
+	"roadmap.restapi/internal/token"
+	"roadmap.restapi/internal/user"
+)
+
+func ExportsRouter(
+	extractor token.ClaimsExtractor,
+	userRepo user.UserRepository,
+	exports *export.UseCases,
+) chi.Router {
+	r := chi.NewRouter()
+	r.Use(middleware.Auth(extractor, userRepo))
+
+	r.Get("/", http.HandlerFunc(accountExport(exports)))
+	r.Get("/jobs", http.HandlerFunc(exportJobList(exports)))
+	r.Get("/jobs/{job-id}", http.HandlerFunc(exportJobGet(exports)))
+	r.Get("/jobs/{job-id}/download", http.HandlerFunc(exportJobDownload(exports)))
+
+	return r
+}
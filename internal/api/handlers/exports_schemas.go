@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/export"
+)
+
+type ExportJobDTO struct {
+	ID          uuid.UUID          `json:"id"`
+	URLDomain   string             `json:"url_domain"`
+	URLID       string             `json:"url_id,omitempty"`
+	Granularity export.Granularity `json:"granularity"`
+	Format      export.Format      `json:"format"`
+	Columns     []string           `json:"columns"`
+	From        time.Time          `json:"from"`
+	To          time.Time          `json:"to"`
+	Status      export.Status      `json:"status"`
+	RowCount    int64              `json:"row_count"`
+	Error       *string            `json:"error,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+	FinishedAt  *time.Time         `json:"finished_at,omitempty"`
+}
+
+func NewExportJobDTO(j *export.Job) ExportJobDTO {
+	return ExportJobDTO{
+		ID:          j.ID,
+		URLDomain:   j.URLDomain,
+		URLID:       j.URLID,
+		Granularity: j.Granularity,
+		Format:      j.Format,
+		Columns:     strings.Split(j.Columns, ","),
+		From:        j.From,
+		To:          j.To,
+		Status:      j.Status,
+		RowCount:    j.RowCount,
+		Error:       j.Error,
+		CreatedAt:   j.CreatedAt,
+		FinishedAt:  j.FinishedAt,
+	}
+}
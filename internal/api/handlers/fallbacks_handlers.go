@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/api/middleware"
+	"roadmap.restapi/internal/api/request"
+	"roadmap.restapi/internal/api/response"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/customdomain"
+	"roadmap.restapi/internal/fallback"
+)
+
+func writeFallbackError(w http.ResponseWriter, r *http.Request, err error) {
+	log := ctxlogging.Get(r.Context())
+	if errors.Is(err, fallback.ErrDomainNotAllowed) {
+		response.WriteJsonErrorResponse(w, err, http.StatusForbidden)
+	} else if errors.Is(err, fallback.ErrPageNotFound) {
+		response.WriteJsonErrorResponse(w, err, http.StatusNotFound)
+	} else if errors.Is(err, fallback.ErrInvalidMode) ||
+		errors.Is(err, fallback.ErrInvalidRedirect) ||
+		errors.Is(err, fallback.ErrInvalidTemplate) ||
+		errors.Is(err, fallback.ErrTemplateTooLarge) {
+		response.WriteJsonErrorResponse(w, err, http.StatusUnprocessableEntity)
+	} else {
+		log.Error("unhandled error", "err", err)
+		response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+	}
+}
+
+func fallbackList(fallbacks *fallback.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+
+		list, err := fallbacks.ByUser(r.Context(), uid)
+		if err != nil {
+			writeFallbackError(w, r, err)
+			return
+		}
+
+		dtos := make([]FallbackDTO, 0, len(list))
+		for i := range list {
+			dtos = append(dtos, NewFallbackDTO(&list[i]))
+		}
+
+		response.WriteJsonResponse(w, response.NewResponse(dtos), http.StatusOK)
+	}
+}
+
+func fallbackSave(fallbacks *fallback.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		body, err := request.ParseAndValidateJson(validate, r.Body, FallbackRequest{})
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		page := &fallback.Page{
+			UserID:      uid,
+			Domain:      customdomain.Normalize(body.Domain),
+			Mode:        body.Mode,
+			RedirectURL: body.RedirectURL,
+			Template:    body.Template,
+		}
+
+		if err = fallbacks.Save(r.Context(), page); err != nil {
+			writeFallbackError(w, r, err)
+			return
+		}
+
+		log.Debug("fallback page saved", "domain", page.Domain, "mode", page.Mode)
+		response.WriteJsonResponse(w, response.NewResponse(NewFallbackDTO(page)), http.StatusOK)
+	}
+}
+
+// fallbackDelete removes the page of ?domain=, or the user wide page when
+// domain is not given.
+func fallbackDelete(fallbacks *fallback.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		domain := urlDomain(r)
+
+		if err := fallbacks.Delete(r.Context(), uid, domain); err != nil {
+			writeFallbackError(w, r, err)
+			return
+		}
+
+		log.Debug("fallback page deleted", "domain", domain)
+		response.WriteJsonResponse(w, struct{}{}, http.StatusNoContent)
+	}
+}
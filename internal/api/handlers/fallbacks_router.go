@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"roadmap.restapi/internal/api/middleware"
+	"roadmap.restapi/internal/fallback"
+	"roadmap.restapi/internal/token"
+	"roadmap.restapi/internal/user"
+)
+
+func FallbacksRouter(
+	extractor token.ClaimsExtractor,
+	userRepo user.UserRepository,
+	fallbacks *fallback.UseCases,
+) chi.Router {
+	r := chi.NewRouter()
+	r.Use(middleware.Auth(extractor, userRepo))
+
+	r.Get("/", http.HandlerFunc(fallbackList(fallbacks)))
+	r.Put("/", http.HandlerFunc(fallbackSave(fallbacks)))
+	r.Delete("/", http.HandlerFunc(fallbackDelete(fallbacks)))
+
+	return r
+}
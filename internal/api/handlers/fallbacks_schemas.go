@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"time"
+
+	"roadmap.restapi/internal/fallback"
+)
+
+type FallbackRequest struct {
+	Domain      string        `json:"domain" validate:"omitempty,fqdn"`
+	Mode        fallback.Mode `json:"mode" validate:"required,oneof=not_found redirect template"`
+	RedirectURL string        `json:"redirect_url" validate:"required_if=Mode redirect"`
+	Template    string        `json:"template" validate:"required_if=Mode template"`
+}
+
+type FallbackDTO struct {
+	Domain      string        `json:"domain"`
+	Mode        fallback.Mode `json:"mode"`
+	RedirectURL string        `json:"redirect_url,omitempty"`
+	Template    string        `json:"template,omitempty"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+func NewFallbackDTO(p *fallback.Page) FallbackDTO {
+	return FallbackDTO{
+		Domain:      p.Domain,
+		Mode:        p.Mode,
+		RedirectURL: p.RedirectURL,
+		Template:    p.Template,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
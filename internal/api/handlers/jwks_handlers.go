@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"roadmap.restapi/internal/api/response"
+	"roadmap.restapi/internal/token"
+)
+
+// jwksMaxAge lets verifiers cache the key set for a while. Keys are
+// published before they sign, so this has to be shorter than the time
+// between configuring a key and its active_from.
+const jwksMaxAge = "public, max-age=300"
+
+// JWKS serves the public keys access tokens are verified with, so other
+// services can verify them without the signing secret. Secrets are never
+// published.
+func JWKS(keys *token.KeyRing) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", jwksMaxAge)
+		response.WriteJsonResponse(w, keys.JWKS(time.Now()), http.StatusOK)
+	}
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	neturl "net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/analytics"
+	"roadmap.restapi/internal/api/middleware"
+	"roadmap.restapi/internal/api/pages"
+	"roadmap.restapi/internal/api/request"
+	"roadmap.restapi/internal/api/response"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/customdomain"
+	"roadmap.restapi/internal/fallback"
+	"roadmap.restapi/internal/page"
+	"roadmap.restapi/internal/url"
+)
+
+const DEFAULT_ACCENT_COLOR = "#2456d6"
+
+func writePageError(w http.ResponseWriter, r *http.Request, err error) {
+	log := ctxlogging.Get(r.Context())
+	if errors.Is(err, page.ErrUserIsNotAuthor) {
+		response.WriteJsonErrorResponse(w, err, http.StatusForbidden)
+	} else if errors.Is(err, page.ErrPageNotFound) || errors.Is(err, url.ErrURLNotFound) {
+		response.WriteJsonErrorResponse(w, err, http.StatusNotFound)
+	} else if errors.Is(err, page.ErrHandleTaken) {
+		response.WriteJsonErrorResponse(w, err, http.StatusConflict)
+	} else if errors.Is(err, page.ErrInvalidHandle) ||
+		errors.Is(err, page.ErrURLIsNotOwned) ||
+		errors.Is(err, page.ErrDuplicateLinkURLs) {
+		response.WriteJsonErrorResponse(w, err, http.StatusUnprocessableEntity)
+	} else {
+		log.Error("unhandled error", "err", err)
+		response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+	}
+}
+
+func pageFromRequest(body *PageRequest) *page.Page {
+	accent := body.AccentColor
+	if accent == "" {
+		accent = DEFAULT_ACCENT_COLOR
+	}
+
+	return &page.Page{
+		Handle:      body.Handle,
+		Title:       body.Title,
+		Description: body.Description,
+		AvatarURL:   body.AvatarURL,
+		Theme:       body.Theme,
+		AccentColor: accent,
+	}
+}
+
+func pageList(pagesUC *page.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+
+		list, err := pagesUC.ByUser(r.Context(), uid)
+		if err != nil {
+			writePageError(w, r, err)
+			return
+		}
+
+		dtos := make([]PageDTO, 0, len(list))
+		for i := range list {
+			dtos = append(dtos, NewPageDTO(&list[i], nil))
+		}
+
+		response.WriteJsonResponse(w, response.NewResponse(dtos), http.StatusOK)
+	}
+}
+
+func pageCreate(pagesUC *page.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		body, err := request.ParseAndValidateJson(validate, r.Body, PageRequest{})
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		newPage := pageFromRequest(body)
+		newPage.AuthorID = uid
+
+		if err = pagesUC.Create(r.Context(), newPage); err != nil {
+			writePageError(w, r, err)
+			return
+		}
+
+		log.Debug("page created", "page", newPage)
+		response.WriteJsonResponse(w, response.NewResponse(NewPageDTO(newPage, nil)), http.StatusCreated)
+	}
+}
+
+func pageGet(pagesUC *page.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		pageID, err := uuid.Parse(chi.URLParam(r, "page-id"))
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		p, links, err := pagesUC.Get(r.Context(), uid, pageID)
+		if err != nil {
+			writePageError(w, r, err)
+			return
+		}
+
+		response.WriteJsonResponse(w, response.NewResponse(NewPageDTO(p, links)), http.StatusOK)
+	}
+}
+
+func pageUpdate(pagesUC *page.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		pageID, err := uuid.Parse(chi.URLParam(r, "page-id"))
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		body, err := request.ParseAndValidateJson(validate, r.Body, PageRequest{})
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		updated := pageFromRequest(body)
+		updated.ID = pageID
+
+		if err = pagesUC.Update(r.Context(), uid, updated); err != nil {
+			writePageError(w, r, err)
+			return
+		}
+
+		log.Debug("page updated", "page", updated)
+		response.WriteJsonResponse(w, response.NewResponse(NewPageDTO(updated, nil)), http.StatusOK)
+	}
+}
+
+func pageDelete(pagesUC *page.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		pageID, err := uuid.Parse(chi.URLParam(r, "page-id"))
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		if err = pagesUC.Delete(r.Context(), uid, pageID); err != nil {
+			writePageError(w, r, err)
+			return
+		}
+
+		log.Debug("page deleted", "pageID", pageID)
+		response.WriteJsonResponse(w, struct{}{}, http.StatusNoContent)
+	}
+}
+
+func pageSetLinks(pagesUC *page.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		pageID, err := uuid.Parse(chi.URLParam(r, "page-id"))
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		body, err := request.ParseAndValidateJson(validate, r.Body, PageLinksRequest{})
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		links := make([]page.Link, 0, len(body.Links))
+		for _, link := range body.Links {
+			links = append(links, page.Link{
+				URLDomain: customdomain.Normalize(link.URLDomain),
+				URLID:     link.URLID,
+				Title:     link.Title,
+				Icon:      link.Icon,
+			})
+		}
+
+		saved, err := pagesUC.SetLinks(r.Context(), uid, pageID, links)
+		if err != nil {
+			writePageError(w, r, err)
+			return
+		}
+
+		log.Debug("page links updated", "pageID", pageID, "links", len(saved))
+		dtos := NewPageDTO(&page.Page{}, saved).Links
+		if dtos == nil {
+			dtos = []PageLinkDTO{}
+		}
+		response.WriteJsonResponse(w, response.NewResponse(dtos), http.StatusOK)
+	}
+}
+
+func pagePublic(pagesUC *page.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		handle := chi.URLParam(r, "handle")
+
+		p, links, err := pagesUC.Public(r.Context(), handle)
+		if err != nil {
+			if errors.Is(err, page.ErrPageNotFound) {
+				http.NotFound(w, r)
+			} else {
+				log.Error("unhandled error", "err", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		view := pages.LinkInBioPage{
+			Handle:      p.Handle,
+			Title:       p.Title,
+			Description: p.Description,
+			AvatarURL:   p.AvatarURL,
+			Dark:        p.Theme == page.THEME_DARK,
+			AccentColor: p.AccentColor,
+		}
+		if view.Title == "" {
+			view.Title = "@" + p.Handle
+		}
+
+		for i := range links {
+			icon := links[i].Icon
+			isImage := strings.HasPrefix(icon, "https://") || strings.HasPrefix(icon, "http://")
+			href := "/p/" + p.Handle + "/" + neturl.PathEscape(links[i].URLID)
+			if links[i].URLDomain != "" {
+				href += "?domain=" + neturl.QueryEscape(links[i].URLDomain)
+			}
+
+			view.Links = append(view.Links, pages.LinkInBioLink{
+				Href:      href,
+				Title:     links[i].DisplayTitle(),
+				Icon:      icon,
+				IconImage: isImage,
+			})
+		}
+
+		pages.WriteHTML(w, "page.html", view, http.StatusOK)
+	}
+}
+
+// pageLinkRedirect serves a link clicked on a public page. The click is
+// attributed to the page with a "page:<handle>" referer.
+func pageLinkRedirect(pagesUC *page.UseCases, redirector *Redirector) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		handle := chi.URLParam(r, "handle")
+		urlID := chi.URLParam(r, "url-id")
+
+		u, err := pagesUC.PublicLink(r.Context(), handle, urlDomain(r), urlID)
+		if err != nil {
+			if errors.Is(err, page.ErrPageNotFound) ||
+				errors.Is(err, page.ErrLinkNotFound) ||
+				errors.Is(err, url.ErrURLNotFound) {
+				redirector.Fallback(w, r, uuid.Nil, "", urlID, fallback.REASON_NOT_FOUND)
+			} else {
+				log.Error("unhandled error", "err", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		redirector.Serve(w, r, u, PageReferer(strings.ToLower(handle)))
+	}
+}
+
+// PageReferer is the referer recorded for clicks made on a link-in-bio page.
+func PageReferer(handle string) string {
+	return analytics.PAGE_REFERER_PREFIX + handle
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"roadmap.restapi/internal/api/middleware"
+	"roadmap.restapi/internal/page"
+	"roadmap.restapi/internal/token"
+	"roadmap.restapi/internal/user"
+)
+
+func PagesRouter(
+	extractor token.ClaimsExtractor,
+	userRepo user.UserRepository,
+	pagesUC *page.UseCases,
+) chi.Router {
+	r := chi.NewRouter()
+	r.Use(middleware.Auth(extractor, userRepo))
+
+	r.Get("/", http.HandlerFunc(pageList(pagesUC)))
+	r.Post("/", http.HandlerFunc(pageCreate(pagesUC)))
+	r.Get("/{page-id}", http.HandlerFunc(pageGet(pagesUC)))
+	r.Put("/{page-id}", http.HandlerFunc(pageUpdate(pagesUC)))
+	r.Delete("/{page-id}", http.HandlerFunc(pageDelete(pagesUC)))
+	r.Put("/{page-id}/links", http.HandlerFunc(pageSetLinks(pagesUC)))
+
+	return r
+}
+
+// PublicPagesRouter serves link-in-bio pages to visitors.
+func PublicPagesRouter(pagesUC *page.UseCases, redirector *Redirector) chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/{handle}", http.HandlerFunc(pagePublic(pagesUC)))
+	r.Get("/{handle}/{url-id}", http.HandlerFunc(pageLinkRedirect(pagesUC, redirector)))
+
+	return r
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/page"
+)
+
+type PageRequest struct {
+	Handle      string     `json:"handle" validate:"required"`
+	Title       string     `json:"title" validate:"max=120"`
+	Description string     `json:"description" validate:"max=500"`
+	AvatarURL   string     `json:"avatar_url" validate:"omitempty,url"`
+	Theme       page.Theme `json:"theme" validate:"omitempty,oneof=light dark"`
+	AccentColor string     `json:"accent_color" validate:"omitempty,hexcolor"`
+}
+
+type PageLinkRequest struct {
+	URLDomain string `json:"url_domain" validate:"omitempty,fqdn"`
+	URLID     string `json:"url_id" validate:"required"`
+	Title     string `json:"title" validate:"max=120"`
+	Icon      string `json:"icon" validate:"max=500"`
+}
+
+type PageLinksRequest struct {
+	Links []PageLinkRequest `json:"links" validate:"dive"`
+}
+
+type PageLinkDTO struct {
+	URLDomain string `json:"url_domain"`
+	URLID     string `json:"url_id"`
+	Position  int    `json:"position"`
+	Title     string `json:"title"`
+	Icon      string `json:"icon"`
+}
+
+type PageDTO struct {
+	ID          uuid.UUID     `json:"id"`
+	Handle      string        `json:"handle"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	AvatarURL   string        `json:"avatar_url"`
+	Theme       page.Theme    `json:"theme"`
+	AccentColor string        `json:"accent_color"`
+	CreatedAt   time.Time     `json:"created_at"`
+	Links       []PageLinkDTO `json:"links,omitempty"`
+}
+
+func NewPageDTO(p *page.Page, links []page.Link) PageDTO {
+	dto := PageDTO{
+		ID:          p.ID,
+		Handle:      p.Handle,
+		Title:       p.Title,
+		Description: p.Description,
+		AvatarURL:   p.AvatarURL,
+		Theme:       p.Theme,
+		AccentColor: p.AccentColor,
+		CreatedAt:   p.CreatedAt,
+	}
+
+	for _, link := range links {
+		dto.Links = append(dto.Links, PageLinkDTO{
+			URLDomain: link.URLDomain,
+			URLID:     link.URLID,
+			Position:  link.Position,
+			Title:     link.Title,
+			Icon:      link.Icon,
+		})
+	}
+
+	return dto
+}
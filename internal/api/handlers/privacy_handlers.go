@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/api/middleware"
+	"roadmap.restapi/internal/api/request"
+	"roadmap.restapi/internal/api/response"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/privacy"
+)
+
+func privacyGet(privacyUC *privacy.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+
+		settings, err := privacyUC.Settings(r.Context(), uid)
+		if err != nil {
+			log.Error("unhandled error", "err", err)
+			response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		response.WriteJsonResponse(w, response.NewResponse(NewPrivacySettingsDTO(settings)), http.StatusOK)
+	}
+}
+
+func privacySave(privacyUC *privacy.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		body, err := request.ParseAndValidateJson(validate, r.Body, PrivacySettingsRequest{})
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		settings := &privacy.Settings{
+			UserID:         uid,
+			CaptureReferer: *body.CaptureReferer,
+		}
+		if err = privacyUC.Save(r.Context(), settings); err != nil {
+			log.Error("unhandled error", "err", err)
+			response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		log.Debug("privacy settings saved", "captureReferer", settings.CaptureReferer)
+		response.WriteJsonResponse(w, response.NewResponse(NewPrivacySettingsDTO(settings)), http.StatusOK)
+	}
+}
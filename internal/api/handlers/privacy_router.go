@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"roadmap.restapi/internal/api/middleware"
+	"roadmap.restapi/internal/privacy"
+	"roadmap.restapi/internal/token"
+	"roadmap.restapi/internal/user"
+)
+
+func PrivacyRouter(
+	extractor token.ClaimsExtractor,
+	userRepo user.UserRepository,
+	privacyUC *privacy.UseCases,
+) chi.Router {
+	r := chi.NewRouter()
+	r.Use(middleware.Auth(extractor, userRepo))
+
+	r.Get("/", http.HandlerFunc(privacyGet(privacyUC)))
+	r.Put("/", http.HandlerFunc(privacySave(privacyUC)))
+
+	return r
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"time"
+
+	"roadmap.restapi/internal/privacy"
+)
+
+type PrivacySettingsRequest struct {
+	CaptureReferer *bool `json:"capture_referer" validate:"required"`
+}
+
+type PrivacySettingsDTO struct {
+	CaptureReferer bool      `json:"capture_referer"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func NewPrivacySettingsDTO(s *privacy.Settings) PrivacySettingsDTO {
+	return PrivacySettingsDTO{
+		CaptureReferer: s.CaptureReferer,
+		UpdatedAt:      s.UpdatedAt,
+	}
+}
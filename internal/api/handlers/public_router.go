@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"roadmap.restapi/internal/customdomain"
+	"roadmap.restapi/internal/url"
+)
+
+var ErrReservedSlug = errors.New("this id is reserved")
+
+// reservedSlugs are root paths taken by other routers or by well-known
+// locations (RFC 8615) that ACME and other verifiers of custom domains rely on.
+var reservedSlugs = map[string]bool{
+	"api":         true,
+	"p":           true,
+	"t":           true,
+	"debug":       true,
+	".well-known": true,
+}
+
+func isReservedSlug(slug string) bool {
+	return reservedSlugs[strings.ToLower(slug)]
+}
+
+// PublicRouter serves short links at the root of the host: /{slug}.
+func PublicRouter(
+	urlsRepo url.URLRepository,
+	domains *customdomain.UseCases,
+	redirector *Redirector,
+) chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/{slug}", http.HandlerFunc(urlRedirect(urlsRepo, domains, redirector)))
+
+	return r
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/analytics"
+	"roadmap.restapi/internal/api/pages"
+	"roadmap.restapi/internal/crawler"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/fallback"
+	"roadmap.restapi/internal/url"
+)
+
+// Redirector serves a resolved short link to a visitor: expired links get
+// the fallback page of their author, link preview crawlers get the Open
+// Graph page, visitors that have to be warned get the interstitial and
+// everybody else is redirected. Visits of humans are recorded as clicks.
+type Redirector struct {
+	analytics     *analytics.UseCases
+	clicks        *analytics.ClickRecorder
+	fallbacks     *fallback.UseCases
+	interstitial  *url.InterstitialPolicy
+	countdown     time.Duration
+	countryHeader string
+	clientIPs     *ClientIPResolver
+	baseURL       string
+}
+
+func NewRedirector(
+	analytics *analytics.UseCases,
+	clicks *analytics.ClickRecorder,
+	fallbacks *fallback.UseCases,
+	interstitial *url.InterstitialPolicy,
+	countdown time.Duration,
+	countryHeader string,
+	clientIPs *ClientIPResolver,
+	baseURL string,
+) *Redirector {
+	return &Redirector{
+		analytics:     analytics,
+		clicks:        clicks,
+		fallbacks:     fallbacks,
+		interstitial:  interstitial,
+		countdown:     countdown,
+		countryHeader: countryHeader,
+		clientIPs:     clientIPs,
+		baseURL:       baseURL,
+	}
+}
+
+// Serve answers the visit of u. referer is the traffic source recorded with
+// the click, empty when unknown.
+func (rd *Redirector) Serve(w http.ResponseWriter, r *http.Request, u *url.URL, referer string) {
+	log := ctxlogging.Get(r.Context())
+
+	if u.IsExpired(time.Now().UTC()) {
+		rd.Fallback(w, r, u.AuthorID, u.Domain, u.ID, fallback.REASON_EXPIRED)
+		return
+	}
+
+	if crawler.IsPreviewBot(r.UserAgent()) {
+		preview := u.Preview()
+		log.Debug("url preview", "url", u, "userAgent", r.UserAgent())
+		pages.WriteHTML(w, "preview.html", pages.PreviewPage{
+			ShortURL:    u.ShortURL(rd.baseURL),
+			URL:         u.URL,
+			Title:       preview.Title,
+			Description: preview.Description,
+			Image:       preview.Image,
+		}, http.StatusOK)
+		return
+	}
+
+	click := rd.pendingClick(r, u, referer)
+	destination := u.URL
+	if u.AppendClickID && rd.analytics.Trackable(click.Visitor) {
+		// the destination may report a conversion as soon as it loads, the
+		// click has to exist by then
+		if err := rd.clicks.Record(r.Context(), click); err != nil {
+			log.Error("failed to record click", "urlID", u.ID, "err", err)
+		} else {
+			destination = analytics.AppendClickID(u.URL, click.ID)
+		}
+	} else if !rd.clicks.Enqueue(click) {
+		log.Warn("click queue is full, click dropped", "urlID", u.ID)
+	}
+
+	if reason, show := rd.interstitial.Reason(u, time.Now().UTC()); show {
+		host := u.URL
+		if parsed, err := neturl.Parse(u.URL); err == nil {
+			host = parsed.Host
+		}
+
+		log.Debug("url interstitial", "url", u, "reason", reason)
+		w.Header().Set("Cache-Control", "no-store")
+		pages.WriteHTML(w, "interstitial.html", pages.InterstitialPage{
+			URL:       destination,
+			Host:      host,
+			Reason:    string(reason),
+			Countdown: int(rd.countdown.Seconds()),
+		}, http.StatusOK)
+		return
+	}
+
+	log.Debug("url redirect", "url", u)
+	http.Redirect(w, r, destination, http.StatusTemporaryRedirect)
+}
+
+// Fallback answers a visit of a link that can not be served with the page
+// userID configured for domain. uuid.Nil userID or no configured page
+// results in the default error page.
+func (rd *Redirector) Fallback(
+	w http.ResponseWriter,
+	r *http.Request,
+	userID uuid.UUID,
+	domain string,
+	slug string,
+	reason fallback.Reason,
+) {
+	log := ctxlogging.Get(r.Context())
+	status := http.StatusNotFound
+	if reason == fallback.REASON_EXPIRED {
+		status = http.StatusGone
+	}
+	w.Header().Set("Cache-Control", "no-store")
+
+	var page *fallback.Page
+	if userID != uuid.Nil {
+		var err error
+		if page, err = rd.fallbacks.Resolve(r.Context(), userID, domain); err != nil {
+			log.Error("failed to resolve fallback page", "userID", userID, "domain", domain, "err", err)
+		}
+	}
+
+	if page != nil && page.Mode == fallback.MODE_REDIRECT {
+		http.Redirect(w, r, page.RedirectURL, http.StatusFound)
+		return
+	}
+
+	if page != nil && page.Mode == fallback.MODE_TEMPLATE {
+		buf := bytes.Buffer{}
+		err := rd.fallbacks.Render(r.Context(), &buf, page, fallback.TemplateData{
+			Slug:   slug,
+			Domain: domain,
+			Reason: reason,
+		})
+		if err == nil {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(status)
+			w.Write(buf.Bytes())
+			return
+		}
+		log.Warn("fallback template failed, using default page", "pageID", page.ID, "err", err)
+	}
+
+	pages.WriteHTML(w, "fallback.html", pages.FallbackPage{
+		Host:    r.Host,
+		Slug:    slug,
+		Expired: reason == fallback.REASON_EXPIRED,
+	}, status)
+}
+
+func (rd *Redirector) visitor(r *http.Request) analytics.Visitor {
+	return analytics.Visitor{
+		IP:             rd.clientIPs.IP(r),
+		UserAgent:      r.UserAgent(),
+		AcceptLanguage: r.Header.Get("Accept-Language"),
+
+		DoNotTrack:           r.Header.Get("DNT"),
+		GlobalPrivacyControl: r.Header.Get("Sec-GPC"),
+	}
+}
+
+// pendingClick describes the visit of u for the click recorder.
+func (rd *Redirector) pendingClick(r *http.Request, u *url.URL, referer string) analytics.PendingClick {
+	var countryCode *string
+	if country := strings.ToUpper(strings.TrimSpace(r.Header.Get(rd.countryHeader))); country != "" {
+		countryCode = &country
+	}
+
+	return analytics.PendingClick{
+		ID:          uuid.New(),
+		AuthorID:    u.AuthorID,
+		URLDomain:   u.Domain,
+		URLID:       u.ID,
+		Visitor:     rd.visitor(r),
+		CountryCode: countryCode,
+		Referer:     referer,
+	}
+}
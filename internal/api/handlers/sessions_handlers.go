@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/api/middleware"
+	"roadmap.restapi/internal/api/response"
+	"roadmap.restapi/internal/config"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/token"
+)
+
+func sessionClient(r *http.Request, clientIPs *ClientIPResolver) token.SessionClient {
+	return token.SessionClient{
+		IP:        clientIPs.IP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+func sessions(tokens *token.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		sid := r.Context().Value(middleware.CTX_SESSION_ID).(uuid.UUID)
+
+		sessions, err := tokens.Sessions(r.Context(), uid)
+		if err != nil {
+			log.Error("unknown error on sessions get", "err", err)
+			response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		dtos := make([]SessionDTO, 0, len(sessions))
+		for _, s := range sessions {
+			dtos = append(dtos, SessionDTO{
+				ID:              s.ID,
+				CreatedAt:       s.CreatedAt,
+				LastRefreshedAt: s.LastRefreshedAt,
+				IP:              s.IP,
+				UserAgent:       s.UserAgent,
+				Current:         s.ID == sid,
+			})
+		}
+
+		response.WriteJsonResponse(w, response.NewResponse(dtos), http.StatusOK)
+	}
+}
+
+func revokeSession(tokens *token.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+
+		id, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			response.WriteJsonErrorResponse(w, token.ErrSessionNotFound, http.StatusNotFound)
+			return
+		}
+
+		if err = tokens.RevokeSession(r.Context(), uid, id); err != nil {
+			if errors.Is(err, token.ErrSessionNotFound) {
+				response.WriteJsonErrorResponse(w, err, http.StatusNotFound)
+			} else {
+				log.Error("unknown error on session revoke", "err", err)
+				response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		response.WriteJsonResponse(w, response.NewResponse(struct{}{}), http.StatusNoContent)
+	}
+}
+
+// logoutEverywhere revokes all sessions of the user, the current one
+// included.
+func logoutEverywhere(tokens *token.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+
+		if err := tokens.RevokeSessions(r.Context(), uid, uuid.Nil); err != nil {
+			log.Error("unknown error on sessions revoke", "err", err)
+			response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		clearTokenCookies(w)
+
+		response.WriteJsonResponse(w, response.NewResponse(struct{}{}), http.StatusNoContent)
+	}
+}
+
+func clearTokenCookies(w http.ResponseWriter) {
+	for _, name := range []string{middleware.COOKIE_ACCESS, middleware.COOKIE_REFRESH} {
+		http.SetCookie(w, &http.Cookie{
+			Name:     name,
+			Value:    "",
+			Expires:  time.Now(),
+			Secure:   config.Cfg().IsProd(),
+			HttpOnly: config.Cfg().IsProd(),
+			Path:     API_COOKIE_PATH,
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// StreamLimiter caps the long-lived streams a user holds open on this
+// instance.
+type StreamLimiter struct {
+	mu      sync.Mutex
+	max     int
+	streams map[uuid.UUID]int
+}
+
+func NewStreamLimiter(max int) *StreamLimiter {
+	return &StreamLimiter{
+		max:     max,
+		streams: map[uuid.UUID]int{},
+	}
+}
+
+// Acquire reserves a stream for userID. It reports false when the user
+// already holds the maximum, otherwise the stream has to be given back with
+// Release.
+func (l *StreamLimiter) Acquire(userID uuid.UUID) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.streams[userID] >= l.max {
+		return false
+	}
+	l.streams[userID]++
+
+	return true
+}
+
+func (l *StreamLimiter) Release(userID uuid.UUID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.streams[userID] <= 1 {
+		delete(l.streams, userID)
+		return
+	}
+	l.streams[userID]--
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/api/middleware"
+	"roadmap.restapi/internal/api/response"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/url"
+)
+
+func writeTransferError(w http.ResponseWriter, r *http.Request, err error) {
+	log := ctxlogging.Get(r.Context())
+	if errors.Is(err, url.ErrTransferNotFound) || errors.Is(err, url.ErrURLNotFound) {
+		response.WriteJsonErrorResponse(w, err, http.StatusNotFound)
+	} else if errors.Is(err, url.ErrTransferNotPending) || errors.Is(err, url.ErrTransferExpired) {
+		response.WriteJsonErrorResponse(w, err, http.StatusConflict)
+	} else if errors.Is(err, url.ErrRecipientNotOwner) {
+		response.WriteJsonErrorResponse(w, err, http.StatusUnprocessableEntity)
+	} else {
+		log.Error("unhandled error", "err", err)
+		response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+	}
+}
+
+func transfersIncoming(urls *url.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+
+		transfers, err := urls.IncomingTransfers(r.Context(), uid)
+		if err != nil {
+			writeTransferError(w, r, err)
+			return
+		}
+
+		dtos := make([]TransferDTO, 0, len(transfers))
+		for i := range transfers {
+			dtos = append(dtos, NewTransferDTO(&transfers[i]))
+		}
+
+		response.WriteJsonResponse(w, response.NewResponse(dtos), http.StatusOK)
+	}
+}
+
+func transferAccept(urls *url.UseCases, baseURL string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		transferID, err := uuid.Parse(chi.URLParam(r, "transfer-id"))
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		u, err := urls.AcceptTransfer(r.Context(), uid, transferID)
+		if err != nil {
+			writeTransferError(w, r, err)
+			return
+		}
+
+		log.Debug("transfer accepted", "transferID", transferID, "url", u)
+		response.WriteJsonResponse(w, response.NewResponse(NewUrlDTO(u, baseURL)), http.StatusOK)
+	}
+}
+
+func transferDecline(urls *url.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		transferID, err := uuid.Parse(chi.URLParam(r, "transfer-id"))
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		if err = urls.DeclineTransfer(r.Context(), uid, transferID); err != nil {
+			writeTransferError(w, r, err)
+			return
+		}
+
+		log.Debug("transfer declined", "transferID", transferID)
+		response.WriteJsonResponse(w, struct{}{}, http.StatusNoContent)
+	}
+}
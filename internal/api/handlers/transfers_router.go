@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"roadmap.restapi/internal/api/middleware"
+	"roadmap.restapi/internal/token"
+	"roadmap.restapi/internal/url"
+	"roadmap.restapi/internal/user"
+)
+
+func TransfersRouter(
+	extractor token.ClaimsExtractor,
+	userRepo user.UserRepository,
+	urls *url.UseCases,
+	baseURL string,
+) chi.Router {
+	r := chi.NewRouter()
+	r.Use(middleware.Auth(extractor, userRepo))
+
+	r.Get("/", http.HandlerFunc(transfersIncoming(urls)))
+	r.Post("/{transfer-id}/accept", http.HandlerFunc(transferAccept(urls, baseURL)))
+	r.Post("/{transfer-id}/decline", http.HandlerFunc(transferDecline(urls)))
+
+	return r
+}
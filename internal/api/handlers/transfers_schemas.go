@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/url"
+)
+
+type TransferDTO struct {
+	ID         uuid.UUID          `json:"id"`
+	URLDomain  string             `json:"url_domain"`
+	URLID      string             `json:"url_id"`
+	FromUserID uuid.UUID          `json:"from_user_id"`
+	ToUserID   uuid.UUID          `json:"to_user_id"`
+	Status     url.TransferStatus `json:"status"`
+	CreatedAt  time.Time          `json:"created_at"`
+	ExpiresAt  time.Time          `json:"expires_at"`
+}
+
+func NewTransferDTO(t *url.Transfer) TransferDTO {
+	return TransferDTO{
+		ID:         t.ID,
+		URLDomain:  t.URLDomain,
+		URLID:      t.URLID,
+		FromUserID: t.FromUserID,
+		ToUserID:   t.ToUserID,
+		Status:     t.Status,
+		CreatedAt:  t.CreatedAt,
+		ExpiresAt:  t.ExpiresAt,
+	}
+}
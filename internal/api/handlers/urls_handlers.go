@@ -1,25 +1,42 @@
 package handlers
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"roadmap.restapi/internal/analytics"
 	"roadmap.restapi/internal/api/middleware"
 	"roadmap.restapi/internal/api/request"
 	"roadmap.restapi/internal/api/response"
+	"roadmap.restapi/internal/config"
 	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/customdomain"
+	"roadmap.restapi/internal/fallback"
+	"roadmap.restapi/internal/health"
+	"roadmap.restapi/internal/token"
 	"roadmap.restapi/internal/url"
+	"roadmap.restapi/internal/user"
 )
 
+// urlDomain returns the domain of the link addressed by the request, passed
+// as ?domain=. Links of the default domain are addressed without it.
+func urlDomain(r *http.Request) string {
+	return customdomain.Normalize(r.URL.Query().Get("domain"))
+}
+
 func generateUrlID() string {
 	newUrlUUID := strings.ReplaceAll(uuid.New().String(), "-", "")
 	return string([]rune(newUrlUUID)[:10])
 }
 
-func urlCreate(urls url.URLRepository) func(w http.ResponseWriter, r *http.Request) {
+func urlCreate(urls *url.UseCases, baseURL string) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		log := ctxlogging.Get(r.Context())
 		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
@@ -33,17 +50,31 @@ func urlCreate(urls url.URLRepository) func(w http.ResponseWriter, r *http.Reque
 			body.ID = generateUrlID()
 		}
 
+		if isReservedSlug(body.ID) {
+			response.WriteJsonErrorResponse(w, ErrReservedSlug, http.StatusUnprocessableEntity)
+			return
+		}
+
 		newUrl := url.URL{
-			ID:       body.ID,
-			URL:      body.URL,
-			Name:     body.Name,
-			AuthorID: uid,
+			Domain:        customdomain.Normalize(body.Domain),
+			ID:            body.ID,
+			URL:           body.URL,
+			Name:          body.Name,
+			AuthorID:      uid,
+			OGTitle:       body.OGTitle,
+			OGDescription: body.OGDescription,
+			OGImage:       body.OGImage,
+			Interstitial:  body.Interstitial,
+			ExpiresAt:     body.ExpiresAt,
+			AppendClickID: body.AppendClickID,
 		}
 
 		err = urls.Create(r.Context(), &newUrl)
 		if err != nil {
 			if errors.Is(err, url.ErrURLAlreadyExists) {
 				response.WriteJsonErrorResponse(w, err, http.StatusConflict)
+			} else if errors.Is(err, url.ErrDomainNotAllowed) {
+				response.WriteJsonErrorResponse(w, err, http.StatusForbidden)
 			} else {
 				log.Error("unhandled error", "err", err)
 				response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
@@ -54,25 +85,200 @@ func urlCreate(urls url.URLRepository) func(w http.ResponseWriter, r *http.Reque
 		log.Debug("new url created", "url", newUrl)
 		response.WriteJsonResponse(
 			w,
-			response.NewResponse(UrlDTO{
-				ID:        newUrl.ID,
-				Name:      newUrl.Name,
-				URL:       newUrl.URL,
-				CreatedAt: newUrl.CreatedAt,
-			}),
+			response.NewResponse(NewUrlDTO(&newUrl, baseURL)),
 			http.StatusCreated,
 		)
 	}
 }
 
-func urlRedirect(urls url.URLRepository) func(w http.ResponseWriter, r *http.Request) {
+// urlList returns links of the current user with their destination health.
+// With ?broken=true only links flagged as broken are returned.
+func urlList(urls url.URLRepository, healthRepo health.Repository, baseURL string) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		log := ctxlogging.Get(r.Context())
-		urlID := chi.URLParam(r, "url-id")
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+
+		onlyBroken := false
+		if raw := r.URL.Query().Get("broken"); raw != "" {
+			var err error
+			if onlyBroken, err = strconv.ParseBool(raw); err != nil {
+				response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+				return
+			}
+		}
+
+		list, err := urls.ByUser(r.Context(), uid)
+		if err != nil {
+			log.Error("unhandled error", "err", err)
+			response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		statuses, err := healthRepo.ByUser(r.Context(), uid)
+		if err != nil {
+			log.Error("unhandled error", "err", err)
+			response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		byURL := make(map[[2]string]*health.Status, len(statuses))
+		for i := range statuses {
+			byURL[[2]string{statuses[i].URLDomain, statuses[i].URLID}] = &statuses[i]
+		}
+
+		dtos := make([]UrlDTO, 0, len(list))
+		for i := range list {
+			status, checked := byURL[[2]string{list[i].Domain, list[i].ID}]
+			if onlyBroken && (!checked || !status.Broken) {
+				continue
+			}
+
+			dto := NewUrlDTO(&list[i], baseURL)
+			if checked {
+				dto.Health = NewHealthDTO(status)
+			}
+			dtos = append(dtos, dto)
+		}
+
+		response.WriteJsonResponse(w, response.NewResponse(dtos), http.StatusOK)
+	}
+}
+
+// urlRedirect resolves the link in the scope of the requested host, so the
+// same slug can be used on every custom domain. It answers visitors, not API
+// clients, so errors are plain text.
+func urlRedirect(urls url.URLRepository, domains *customdomain.UseCases, redirector *Redirector) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		slug := chi.URLParam(r, "slug")
+
+		domain, err := domains.ResolveHost(r.Context(), r.Host)
+		if err != nil {
+			log.Error("unhandled error", "err", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
 
-		u, err := urls.ByID(r.Context(), urlID)
+		// links of the default domain have no owner to take the fallback from
+		name, owner := "", uuid.Nil
+		if domain != nil {
+			name, owner = domain.Name, domain.UserID
+		}
+
+		u, err := urls.ByID(r.Context(), name, slug)
 		if err != nil {
 			if errors.Is(err, url.ErrURLNotFound) {
+				redirector.Fallback(w, r, owner, name, slug, fallback.REASON_NOT_FOUND)
+			} else {
+				log.Error("unhandled error", "err", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		redirector.Serve(w, r, u, r.Referer())
+	}
+}
+
+// statsFilter reads the optional include_bots flag and the from and to days
+// of the stats query, both inclusive.
+func statsFilter(r *http.Request) (analytics.StatsFilter, error) {
+	filter := analytics.StatsFilter{}
+	query := r.URL.Query()
+
+	if raw := query.Get("include_bots"); raw != "" {
+		includeBots, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, err
+		}
+		filter.IncludeBots = includeBots
+	}
+
+	if raw := query.Get("from"); raw != "" {
+		from, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			return filter, err
+		}
+		filter.From = from
+	}
+
+	if raw := query.Get("to"); raw != "" {
+		to, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			return filter, err
+		}
+		filter.To = to.AddDate(0, 0, 1)
+	}
+
+	return filter, nil
+}
+
+func urlStats(urls *url.UseCases, stats *analytics.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		urlID := chi.URLParam(r, "url-id")
+
+		domain := urlDomain(r)
+
+		filter, err := statsFilter(r)
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		if _, err := urls.Get(r.Context(), uid, domain, urlID); err != nil {
+			if errors.Is(err, url.ErrUserIsNotAuthor) {
+				response.WriteJsonErrorResponse(w, err, http.StatusForbidden)
+			} else if errors.Is(err, url.ErrURLNotFound) {
+				response.WriteJsonErrorResponse(w, err, http.StatusNotFound)
+			} else {
+				log.Error("unhandled error", "err", err)
+				response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		result, err := stats.Stats(r.Context(), domain, urlID, filter)
+		if err != nil {
+			log.Error("unhandled error", "err", err)
+			response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		response.WriteJsonResponse(w, response.NewResponse(result), http.StatusOK)
+	}
+}
+
+// clickStreamHeartbeat keeps idle streams from being closed by proxies.
+const clickStreamHeartbeat = 15 * time.Second
+
+// clickStreamRecheck is how often an open stream checks that its user still
+// owns the url and that the access token it was opened with is still valid.
+const clickStreamRecheck = 30 * time.Second
+
+var ErrTooManyStreams = errors.New("too many open click streams")
+
+// urlClickStream pushes clicks on the url to its author as Server-Sent
+// Events until the client disconnects, the url changes hands or the access
+// token expires or is revoked.
+func urlClickStream(
+	urls *url.UseCases,
+	stats *analytics.UseCases,
+	extractor token.ClaimsExtractor,
+	streams *StreamLimiter,
+) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		urlID := chi.URLParam(r, "url-id")
+
+		domain := urlDomain(r)
+
+		if _, err := urls.Get(r.Context(), uid, domain, urlID); err != nil {
+			if errors.Is(err, url.ErrUserIsNotAuthor) {
+				response.WriteJsonErrorResponse(w, err, http.StatusForbidden)
+			} else if errors.Is(err, url.ErrURLNotFound) {
 				response.WriteJsonErrorResponse(w, err, http.StatusNotFound)
 			} else {
 				log.Error("unhandled error", "err", err)
@@ -81,8 +287,81 @@ func urlRedirect(urls url.URLRepository) func(w http.ResponseWriter, r *http.Req
 			return
 		}
 
-		log.Debug("url redirect", "url", u)
-		http.Redirect(w, r, u.URL, http.StatusTemporaryRedirect)
+		// Auth has already found it
+		accessToken, _ := middleware.Token(r, middleware.COOKIE_ACCESS, config.Cfg().TokensConfig.Sources)
+
+		if !streams.Acquire(uid) {
+			response.WriteJsonErrorResponse(w, ErrTooManyStreams, http.StatusTooManyRequests)
+			return
+		}
+		defer streams.Release(uid)
+
+		events, unsubscribe, err := stats.SubscribeClicks(r.Context(), domain, urlID)
+		if err != nil {
+			log.Error("unhandled error", "err", err)
+			response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			return
+		}
+		defer unsubscribe()
+
+		// the stream outlives the write timeout of the server
+		rc := http.NewResponseController(w)
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			log.Warn("failed to clear write deadline of click stream", "err", err)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		if err := rc.Flush(); err != nil {
+			log.Error("click stream is not flushable", "err", err)
+			return
+		}
+
+		heartbeat := time.NewTicker(clickStreamHeartbeat)
+		defer heartbeat.Stop()
+		recheck := time.NewTicker(clickStreamRecheck)
+		defer recheck.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-recheck.C:
+				if _, err := extractor.ParseAndValidate(r.Context(), accessToken); err != nil {
+					log.Debug("click stream closed, token is no longer valid", "err", err)
+					return
+				}
+				if _, err := urls.Get(r.Context(), uid, domain, urlID); err != nil {
+					log.Debug("click stream closed, url is no longer available", "urlID", urlID, "err", err)
+					return
+				}
+				continue
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+
+				payload, err := json.Marshal(event)
+				if err != nil {
+					log.Error("failed to encode click event", "err", err)
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "event: click\ndata: %s\n\n", payload); err != nil {
+					return
+				}
+			}
+
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
 	}
 }
 
@@ -92,7 +371,7 @@ func urlDelete(urls *url.UseCases) func(w http.ResponseWriter, r *http.Request)
 		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
 		urlID := chi.URLParam(r, "url-id")
 
-		err := urls.Delete(r.Context(), uid, urlID)
+		err := urls.Delete(r.Context(), uid, urlDomain(r), urlID)
 		if err != nil {
 			if errors.Is(err, url.ErrUserIsNotAuthor) {
 				response.WriteJsonErrorResponse(w, err, http.StatusForbidden)
@@ -113,3 +392,200 @@ func urlDelete(urls *url.UseCases) func(w http.ResponseWriter, r *http.Request)
 		)
 	}
 }
+
+func urlUpdate(urls *url.UseCases, baseURL string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		urlID := chi.URLParam(r, "url-id")
+		body, err := request.ParseAndValidateJson(validate, r.Body, UrlUpdateRequest{})
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		updated := url.URL{
+			Domain:        urlDomain(r),
+			ID:            urlID,
+			URL:           body.URL,
+			Name:          body.Name,
+			OGTitle:       body.OGTitle,
+			OGDescription: body.OGDescription,
+			OGImage:       body.OGImage,
+			Interstitial:  body.Interstitial,
+			ExpiresAt:     body.ExpiresAt,
+			AppendClickID: body.AppendClickID,
+		}
+
+		err = urls.Update(r.Context(), uid, &updated)
+		if err != nil {
+			if errors.Is(err, url.ErrUserIsNotAuthor) {
+				response.WriteJsonErrorResponse(w, err, http.StatusForbidden)
+			} else if errors.Is(err, url.ErrURLNotFound) {
+				response.WriteJsonErrorResponse(w, err, http.StatusNotFound)
+			} else {
+				log.Error("unhandled error", "err", err)
+				response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		log.Debug("url updated", "url", updated)
+		response.WriteJsonResponse(
+			w,
+			response.NewResponse(NewUrlDTO(&updated, baseURL)),
+			http.StatusOK,
+		)
+	}
+}
+
+func urlRevisions(urls *url.UseCases) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		urlID := chi.URLParam(r, "url-id")
+
+		revisions, err := urls.Revisions(r.Context(), uid, urlDomain(r), urlID)
+		if err != nil {
+			if errors.Is(err, url.ErrUserIsNotAuthor) {
+				response.WriteJsonErrorResponse(w, err, http.StatusForbidden)
+			} else if errors.Is(err, url.ErrURLNotFound) {
+				response.WriteJsonErrorResponse(w, err, http.StatusNotFound)
+			} else {
+				log.Error("unhandled error", "err", err)
+				response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		dtos := make([]RevisionDTO, 0, len(revisions))
+		for i := range revisions {
+			dtos = append(dtos, NewRevisionDTO(&revisions[i]))
+		}
+
+		response.WriteJsonResponse(w, response.NewResponse(dtos), http.StatusOK)
+	}
+}
+
+func urlRollback(urls *url.UseCases, baseURL string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		urlID := chi.URLParam(r, "url-id")
+		revisionID, err := uuid.Parse(chi.URLParam(r, "revision-id"))
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		restored, err := urls.Rollback(r.Context(), uid, urlDomain(r), urlID, revisionID)
+		if err != nil {
+			if errors.Is(err, url.ErrUserIsNotAuthor) {
+				response.WriteJsonErrorResponse(w, err, http.StatusForbidden)
+			} else if errors.Is(err, url.ErrURLNotFound) || errors.Is(err, url.ErrRevisionNotFound) {
+				response.WriteJsonErrorResponse(w, err, http.StatusNotFound)
+			} else {
+				log.Error("unhandled error", "err", err)
+				response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		log.Debug("url rolled back", "url", restored, "revisionID", revisionID)
+		response.WriteJsonResponse(
+			w,
+			response.NewResponse(NewUrlDTO(restored, baseURL)),
+			http.StatusOK,
+		)
+	}
+}
+
+func urlDuplicate(urls *url.UseCases, baseURL string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		urlID := chi.URLParam(r, "url-id")
+		body, err := request.ParseAndValidateJson(validate, r.Body, UrlDuplicateRequest{})
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		if body.ID == "" {
+			body.ID = generateUrlID()
+		}
+
+		if isReservedSlug(body.ID) {
+			response.WriteJsonErrorResponse(w, ErrReservedSlug, http.StatusUnprocessableEntity)
+			return
+		}
+
+		clone, err := urls.Duplicate(r.Context(), uid, urlDomain(r), urlID, body.ID)
+		if err != nil {
+			if errors.Is(err, url.ErrUserIsNotAuthor) {
+				response.WriteJsonErrorResponse(w, err, http.StatusForbidden)
+			} else if errors.Is(err, url.ErrURLNotFound) {
+				response.WriteJsonErrorResponse(w, err, http.StatusNotFound)
+			} else if errors.Is(err, url.ErrURLAlreadyExists) {
+				response.WriteJsonErrorResponse(w, err, http.StatusConflict)
+			} else {
+				log.Error("unhandled error", "err", err)
+				response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		log.Debug("url duplicated", "from", urlID, "url", clone)
+		response.WriteJsonResponse(
+			w,
+			response.NewResponse(NewUrlDTO(clone, baseURL)),
+			http.StatusCreated,
+		)
+	}
+}
+
+func urlTransfer(urls *url.UseCases, userRepo user.UserRepository) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := ctxlogging.Get(r.Context())
+		uid := r.Context().Value(middleware.CTX_USER_ID).(uuid.UUID)
+		urlID := chi.URLParam(r, "url-id")
+		body, err := request.ParseAndValidateJson(validate, r.Body, UrlTransferRequest{})
+		if err != nil {
+			response.WriteJsonErrorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		recipient, err := userRepo.ByEmail(r.Context(), body.Email)
+		if err != nil {
+			if errors.Is(err, user.ErrUserNotFound) {
+				response.WriteJsonErrorResponse(w, err, http.StatusNotFound)
+			} else {
+				log.Error("unhandled error", "err", err)
+				response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		transfer, err := urls.Transfer(r.Context(), uid, urlDomain(r), urlID, recipient.ID)
+		if err != nil {
+			if errors.Is(err, url.ErrUserIsNotAuthor) {
+				response.WriteJsonErrorResponse(w, err, http.StatusForbidden)
+			} else if errors.Is(err, url.ErrURLNotFound) {
+				response.WriteJsonErrorResponse(w, err, http.StatusNotFound)
+			} else if errors.Is(err, url.ErrTransferToSelf) || errors.Is(err, url.ErrRecipientNotOwner) {
+				response.WriteJsonErrorResponse(w, err, http.StatusUnprocessableEntity)
+			} else {
+				log.Error("unhandled error", "err", err)
+				response.WriteJsonErrorResponse(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		log.Debug("url transfer requested", "transfer", transfer)
+		response.WriteJsonResponse(
+			w,
+			response.NewResponse(NewTransferDTO(transfer)),
+			http.StatusCreated,
+		)
+	}
+}
@@ -4,7 +4,11 @@ import (
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
+	"roadmap.restapi/internal/analytics"
 	"roadmap.restapi/internal/api/middleware"
+	"roadmap.restapi/internal/config"
+	"roadmap.restapi/internal/export"
+	"roadmap.restapi/internal/health"
 	"roadmap.restapi/internal/token"
 	"roadmap.restapi/internal/url"
 	"roadmap.restapi/internal/user"
@@ -15,14 +19,26 @@ func UrlsRouter(
 	userRepo user.UserRepository,
 	urlsRepo url.URLRepository,
 	urls *url.UseCases,
+	healthRepo health.Repository,
+	stats *analytics.UseCases,
+	exports *export.UseCases,
+	baseURL string,
 ) chi.Router {
 	r := chi.NewRouter()
 	authMW := middleware.Auth(extractor, userRepo)
+	streams := NewStreamLimiter(config.Cfg().AnalyticsConfig.ClickStreamsPerUser)
 
-	r.Get("/{url-id}", http.HandlerFunc(urlRedirect(urlsRepo)))
-
-	r.With(authMW).Post("/", http.HandlerFunc(urlCreate(urlsRepo)))
+	r.With(authMW).Get("/", http.HandlerFunc(urlList(urlsRepo, healthRepo, baseURL)))
+	r.With(authMW).Post("/", http.HandlerFunc(urlCreate(urls, baseURL)))
+	r.With(authMW).Put("/{url-id}", http.HandlerFunc(urlUpdate(urls, baseURL)))
 	r.With(authMW).Delete("/{url-id}", http.HandlerFunc(urlDelete(urls)))
+	r.With(authMW).Get("/{url-id}/stats", http.HandlerFunc(urlStats(urls, stats)))
+	r.With(authMW).Get("/{url-id}/stats/export", http.HandlerFunc(urlStatsExport(urls, exports)))
+	r.With(authMW).Get("/{url-id}/clicks/stream", http.HandlerFunc(urlClickStream(urls, stats, extractor, streams)))
+	r.With(authMW).Get("/{url-id}/revisions", http.HandlerFunc(urlRevisions(urls)))
+	r.With(authMW).Post("/{url-id}/revisions/{revision-id}/rollback", http.HandlerFunc(urlRollback(urls, baseURL)))
+	r.With(authMW).Post("/{url-id}/duplicate", http.HandlerFunc(urlDuplicate(urls, baseURL)))
+	r.With(authMW).Post("/{url-id}/transfer", http.HandlerFunc(urlTransfer(urls, userRepo)))
 
 	return r
 }
@@ -1,16 +1,162 @@
 package handlers
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/health"
+	"roadmap.restapi/internal/url"
+)
 
 type UrlCreateRequest struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
-	URL  string `json:"url" validate:"required,url"`
+	Domain        string     `json:"domain" validate:"omitempty,fqdn"`
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	URL           string     `json:"url" validate:"required,url"`
+	OGTitle       string     `json:"og_title"`
+	OGDescription string     `json:"og_description"`
+	OGImage       string     `json:"og_image" validate:"omitempty,url"`
+	Interstitial  bool       `json:"interstitial"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	AppendClickID bool       `json:"append_click_id"`
+}
+
+type UrlUpdateRequest struct {
+	Name          string     `json:"name"`
+	URL           string     `json:"url" validate:"required,url"`
+	OGTitle       string     `json:"og_title"`
+	OGDescription string     `json:"og_description"`
+	OGImage       string     `json:"og_image" validate:"omitempty,url"`
+	Interstitial  bool       `json:"interstitial"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	AppendClickID bool       `json:"append_click_id"`
 }
 
 type UrlDTO struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	URL       string    `json:"url"`
-	CreatedAt time.Time `json:"created_at"`
+	Domain        string      `json:"domain"`
+	ID            string      `json:"id"`
+	ShortURL      string      `json:"short_url"`
+	Name          string      `json:"name"`
+	URL           string      `json:"url"`
+	CreatedAt     time.Time   `json:"created_at"`
+	Metadata      MetadataDTO `json:"metadata"`
+	OG            MetadataDTO `json:"og"`
+	Interstitial  bool        `json:"interstitial"`
+	ExpiresAt     *time.Time  `json:"expires_at"`
+	AppendClickID bool        `json:"append_click_id"`
+	Health        *HealthDTO  `json:"health,omitempty"`
+}
+
+type MetadataDTO struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Image       string `json:"image"`
+}
+
+func NewUrlDTO(u *url.URL, baseURL string) UrlDTO {
+	return UrlDTO{
+		Domain:    u.Domain,
+		ID:        u.ID,
+		ShortURL:  u.ShortURL(baseURL),
+		Name:      u.Name,
+		URL:       u.URL,
+		CreatedAt: u.CreatedAt,
+		Metadata: MetadataDTO{
+			Title:       u.MetaTitle,
+			Description: u.MetaDescription,
+			Image:       u.MetaImage,
+		},
+		OG: MetadataDTO{
+			Title:       u.OGTitle,
+			Description: u.OGDescription,
+			Image:       u.OGImage,
+		},
+		Interstitial:  u.Interstitial,
+		ExpiresAt:     u.ExpiresAt,
+		AppendClickID: u.AppendClickID,
+	}
+}
+
+type HealthDTO struct {
+	Broken      bool      `json:"broken"`
+	StatusCode  int       `json:"status_code"`
+	LatencyMs   int64     `json:"latency_ms"`
+	Error       string    `json:"error,omitempty"`
+	CheckedAt   time.Time `json:"checked_at"`
+	NextCheckAt time.Time `json:"next_check_at"`
+}
+
+func NewHealthDTO(s *health.Status) *HealthDTO {
+	return &HealthDTO{
+		Broken:      s.Broken,
+		StatusCode:  s.StatusCode,
+		LatencyMs:   s.LatencyMs,
+		Error:       s.Error,
+		CheckedAt:   s.CheckedAt,
+		NextCheckAt: s.NextCheckAt,
+	}
+}
+
+type RevisionDTO struct {
+	ID        uuid.UUID            `json:"id"`
+	AuthorID  uuid.UUID            `json:"author_id"`
+	OldURL    string               `json:"old_url"`
+	NewURL    string               `json:"new_url"`
+	OldName   string               `json:"old_name"`
+	NewName   string               `json:"new_name"`
+	Old       *RevisionSettingsDTO `json:"old_settings"`
+	New       *RevisionSettingsDTO `json:"new_settings"`
+	CreatedAt time.Time            `json:"created_at"`
+}
+
+// RevisionSettingsDTO holds the other author editable values of a revision,
+// revisions made before they were recorded have none.
+type RevisionSettingsDTO struct {
+	OGTitle       string     `json:"og_title"`
+	OGDescription string     `json:"og_description"`
+	OGImage       string     `json:"og_image"`
+	Interstitial  bool       `json:"interstitial"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	AppendClickID bool       `json:"append_click_id"`
+}
+
+func NewRevisionDTO(r *url.Revision) RevisionDTO {
+	dto := RevisionDTO{
+		ID:        r.ID,
+		AuthorID:  r.AuthorID,
+		OldURL:    r.OldURL,
+		NewURL:    r.NewURL,
+		OldName:   r.OldName,
+		NewName:   r.NewName,
+		CreatedAt: r.CreatedAt,
+	}
+
+	if r.HasSettings {
+		dto.Old = &RevisionSettingsDTO{
+			OGTitle:       r.OldOGTitle,
+			OGDescription: r.OldOGDescription,
+			OGImage:       r.OldOGImage,
+			Interstitial:  r.OldInterstitial,
+			ExpiresAt:     r.OldExpiresAt,
+			AppendClickID: r.OldAppendClickID,
+		}
+		dto.New = &RevisionSettingsDTO{
+			OGTitle:       r.NewOGTitle,
+			OGDescription: r.NewOGDescription,
+			OGImage:       r.NewOGImage,
+			Interstitial:  r.NewInterstitial,
+			ExpiresAt:     r.NewExpiresAt,
+			AppendClickID: r.NewAppendClickID,
+		}
+	}
+
+	return dto
+}
+
+type UrlDuplicateRequest struct {
+	ID string `json:"id"`
+}
+
+type UrlTransferRequest struct {
+	Email string `json:"email" validate:"required,email"`
 }
@@ -3,9 +3,12 @@ package middleware
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"roadmap.restapi/internal/api/response"
+	"roadmap.restapi/internal/config"
 	"roadmap.restapi/internal/ctxlogging"
 	"roadmap.restapi/internal/token"
 	"roadmap.restapi/internal/user"
@@ -15,27 +18,75 @@ const (
 	COOKIE_ACCESS  = "access-token"
 	COOKIE_REFRESH = "refresh-token"
 
-	CTX_USER_ID = "user_id"
+	CTX_USER_ID    = "user_id"
+	CTX_SESSION_ID = "session_id"
+
+	TOKEN_SOURCE_HEADER = "header"
+	TOKEN_SOURCE_COOKIE = "cookie"
 )
 
 var ErrNotAuthenticated = errors.New("Unauthenticated")
+var ErrUnknownTokenSource = errors.New("unknown token source")
+
+// CheckTokenSources validates the configured token sources.
+func CheckTokenSources(sources []string) error {
+	if len(sources) == 0 {
+		return fmt.Errorf("%w: none configured", ErrUnknownTokenSource)
+	}
+
+	for _, source := range sources {
+		if source != TOKEN_SOURCE_HEADER && source != TOKEN_SOURCE_COOKIE {
+			return fmt.Errorf("%w: %q", ErrUnknownTokenSource, source)
+		}
+	}
+
+	return nil
+}
+
+// Token returns the token the request carries in the first of sources that
+// has one: an Authorization: Bearer header or the cookie.
+func Token(r *http.Request, cookie string, sources []string) (string, error) {
+	for _, source := range sources {
+		switch source {
+		case TOKEN_SOURCE_HEADER:
+			scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+			if found && strings.EqualFold(scheme, "Bearer") && strings.TrimSpace(token) != "" {
+				return strings.TrimSpace(token), nil
+			}
+		case TOKEN_SOURCE_COOKIE:
+			if c, err := r.Cookie(cookie); err == nil && c.Value != "" {
+				return c.Value, nil
+			}
+		}
+	}
+
+	return "", ErrNotAuthenticated
+}
 
 func Auth(extractor token.ClaimsExtractor, userRepo user.UserRepository) func(next http.Handler) http.Handler {
+	sources := config.Cfg().TokensConfig.Sources
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			log := ctxlogging.Get(r.Context())
-			cookie, err := r.Cookie(COOKIE_ACCESS)
+			accessToken, err := Token(r, COOKIE_ACCESS, sources)
 			if err != nil {
 				response.WriteJsonResponse(w, response.NewErrorResponse(err.Error()), http.StatusUnauthorized)
 				return
 			}
 
-			claims, err := extractor.ParseAndValidate(r.Context(), cookie.Value)
+			claims, err := extractor.ParseAndValidate(r.Context(), accessToken)
 			if err != nil {
 				response.WriteJsonResponse(w, response.NewErrorResponse(err.Error()), http.StatusUnauthorized)
 				return
 			}
 
+			// refresh tokens are signed with the same keys, they must not
+			// open the API
+			if claims.Type != token.ACCESS {
+				response.WriteJsonResponse(w, response.NewErrorResponse(ErrNotAuthenticated.Error()), http.StatusUnauthorized)
+				return
+			}
+
 			log.Info("claims", "claims", claims)
 
 			_, err = userRepo.ByID(r.Context(), claims.UID)
@@ -45,6 +96,7 @@ func Auth(extractor token.ClaimsExtractor, userRepo user.UserRepository) func(ne
 			}
 
 			newCtx := context.WithValue(r.Context(), CTX_USER_ID, claims.UID)
+			newCtx = context.WithValue(newCtx, CTX_SESSION_ID, claims.SID)
 
 			next.ServeHTTP(w, r.WithContext(newCtx))
 		})
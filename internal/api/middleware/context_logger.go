@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/ctxlogging"
+)
+
+// ContextLogger only puts a logger with trace id into the request context.
+// Unlike Logging it does not wrap the response writer and does not log every
+// served request, so it is cheap enough for the public redirect path.
+func ContextLogger(log *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			traceID := uuid.New().String()[0:13]
+			log := log.With(
+				"traceID", traceID,
+			)
+
+			next.ServeHTTP(w, req.WithContext(ctxlogging.Add(req.Context(), log)))
+		})
+	}
+}
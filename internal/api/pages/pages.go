@@ -0,0 +1,72 @@
+package pages
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"net/http"
+)
+
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+var templates = template.Must(template.ParseFS(templatesFS, "templates/*.html"))
+
+type PreviewPage struct {
+	// ShortURL is the canonical url of the page, URL is the destination.
+	ShortURL    string
+	URL         string
+	Title       string
+	Description string
+	Image       string
+}
+
+type InterstitialPage struct {
+	URL    string
+	Host   string
+	Reason string
+	// Countdown is the number of seconds before automatic redirect, zero
+	// disables it.
+	Countdown int
+}
+
+// WriteHTML renders the named template into w. The page is rendered into a
+// buffer first so a template error never produces a half-written response.
+func WriteHTML(w http.ResponseWriter, name string, data any, status int) error {
+	buf := bytes.Buffer{}
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+type LinkInBioLink struct {
+	Href  string
+	Title string
+	Icon  string
+	// IconImage reports whether Icon is an image url rather than a text
+	// glyph.
+	IconImage bool
+}
+
+type LinkInBioPage struct {
+	Handle      string
+	Title       string
+	Description string
+	AvatarURL   string
+	Dark        bool
+	AccentColor string
+	Links       []LinkInBioLink
+}
+
+type FallbackPage struct {
+	Host    string
+	Slug    string
+	Expired bool
+}
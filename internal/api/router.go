@@ -5,8 +5,15 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
+	"roadmap.restapi/internal/analytics"
 	"roadmap.restapi/internal/api/handlers"
 	"roadmap.restapi/internal/api/middleware"
+	"roadmap.restapi/internal/customdomain"
+	"roadmap.restapi/internal/export"
+	"roadmap.restapi/internal/fallback"
+	"roadmap.restapi/internal/health"
+	"roadmap.restapi/internal/page"
+	"roadmap.restapi/internal/privacy"
 	"roadmap.restapi/internal/token"
 	"roadmap.restapi/internal/url"
 	"roadmap.restapi/internal/user"
@@ -18,8 +25,19 @@ func NewRouter(
 	userRepo user.UserRepository,
 	tokens *token.UseCases,
 	tokenExtractor token.ClaimsExtractor,
+	signingKeys *token.KeyRing,
 	urlsRepo url.URLRepository,
 	urls *url.UseCases,
+	healthRepo health.Repository,
+	stats *analytics.UseCases,
+	redirector *handlers.Redirector,
+	pagesUC *page.UseCases,
+	domains *customdomain.UseCases,
+	fallbacks *fallback.UseCases,
+	privacyUC *privacy.UseCases,
+	exports *export.UseCases,
+	clientIPs *handlers.ClientIPResolver,
+	publicBaseURL string,
 ) chi.Router {
 	r := chi.NewRouter()
 
@@ -38,6 +56,7 @@ func NewRouter(
 			userRepo,
 			users,
 			tokens,
+			clientIPs,
 		))
 
 		r.Mount("/urls", handlers.UrlsRouter(
@@ -45,7 +64,90 @@ func NewRouter(
 			userRepo,
 			urlsRepo,
 			urls,
+			healthRepo,
+			stats,
+			exports,
+			publicBaseURL,
 		))
+
+		r.Mount("/transfers", handlers.TransfersRouter(
+			tokenExtractor,
+			userRepo,
+			urls,
+			publicBaseURL,
+		))
+
+		r.Mount("/domains", handlers.DomainsRouter(
+			tokenExtractor,
+			userRepo,
+			domains,
+		))
+
+		r.Mount("/fallbacks", handlers.FallbacksRouter(
+			tokenExtractor,
+			userRepo,
+			fallbacks,
+		))
+
+		r.Mount("/conversions", handlers.ConversionsRouter(
+			tokenExtractor,
+			userRepo,
+			urls,
+			stats,
+		))
+
+		r.Mount("/dashboard", handlers.DashboardRouter(
+			tokenExtractor,
+			userRepo,
+			stats,
+		))
+
+		r.Mount("/exports", handlers.ExportsRouter(
+			tokenExtractor,
+			userRepo,
+			exports,
+		))
+
+		r.Mount("/privacy", handlers.PrivacyRouter(
+			tokenExtractor,
+			userRepo,
+			privacyUC,
+		))
+
+		r.Mount("/pages", handlers.PagesRouter(
+			tokenExtractor,
+			userRepo,
+			pagesUC,
+		))
+	})
+
+	r.Route("/p", func(r chi.Router) {
+		r.Use(middleware.Logging(log))
+		r.Use(middleware.Recover())
+
+		r.Mount("/", handlers.PublicPagesRouter(pagesUC, redirector))
+	})
+
+	r.Route("/t", func(r chi.Router) {
+		r.Use(middleware.Logging(log))
+		r.Use(middleware.Recover())
+
+		r.Mount("/", handlers.TrackingRouter(stats))
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.ContextLogger(log))
+		r.Use(middleware.Recover())
+
+		r.Get("/.well-known/jwks.json", handlers.JWKS(signingKeys))
+	})
+
+	// short links are served at the root, outside of the versioned API
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.ContextLogger(log))
+		r.Use(middleware.Recover())
+
+		r.Mount("/", handlers.PublicRouter(urlsRepo, domains, redirector))
 	})
 
 	return r
@@ -3,17 +3,68 @@ package config
 import "time"
 
 type Config struct {
-	Env              string `yaml:"env" env:"ENV" env-default:"local"`
-	PostgresConfig   `yaml:"postgres"`
-	HTTPServerConfig `yaml:"http_server"`
-	RedisConfig      `yaml:"redis"`
-	TokensConfig     `yaml:"tokens"`
+	Env                string `yaml:"env" env:"ENV" env-default:"local"`
+	PostgresConfig     `yaml:"postgres"`
+	HTTPServerConfig   `yaml:"http_server"`
+	RedisConfig        `yaml:"redis"`
+	TokensConfig       `yaml:"tokens"`
+	URLsConfig         `yaml:"urls"`
+	OutboxConfig       `yaml:"outbox"`
+	HealthConfig       `yaml:"health"`
+	PreviewConfig      `yaml:"preview"`
+	InterstitialConfig `yaml:"interstitial"`
+	AnalyticsConfig    `yaml:"analytics"`
+	DomainsConfig      `yaml:"domains"`
+	PrivacyConfig      `yaml:"privacy"`
+	ExportConfig       `yaml:"export"`
+}
+
+type URLsConfig struct {
+	TransferTTL time.Duration `yaml:"transfer_ttl" env:"URLS_TRANSFER_TTL" env-default:"168h"`
+	// PublicBaseURL is the origin short links of the default domain are
+	// served from.
+	PublicBaseURL string `yaml:"public_base_url" env:"URLS_PUBLIC_BASE_URL" env-default:"http://localhost:8000"`
+}
+
+type OutboxConfig struct {
+	PollInterval time.Duration `yaml:"poll_interval" env:"OUTBOX_POLL_INTERVAL" env-default:"5s"`
+	BatchSize    int           `yaml:"batch_size" env:"OUTBOX_BATCH_SIZE" env-default:"100"`
 }
 
 type TokensConfig struct {
 	AccessTTL  time.Duration `yaml:"access_ttl" env:"TOKENS_ACCESS_TTL_MINUTES" env-default:"15m"`
 	RefreshTTL time.Duration `yaml:"refresh_ttl" env:"TOKENS_REFRESH_TTL_MINUTES" env-default:"43200m"`
 	SecretKey  string        `yaml:"secret_key" env:"TOKENS_SECRET_KEY"`
+	// Algorithm is HS256/384/512 signing with SecretKey or one of RS256/384/512,
+	// PS256/384/512, ES256/384/512 and EdDSA signing with the PEM encoded
+	// PrivateKeyFile, whose public key is served at /.well-known/jwks.json.
+	Algorithm      string `yaml:"algorithm" env:"TOKENS_ALGORITHM" env-default:"HS512"`
+	PrivateKeyFile string `yaml:"private_key_file" env:"TOKENS_PRIVATE_KEY_FILE"`
+	// Keys rotate in after the key above, each signing from its ActiveFrom
+	// on. Leave SecretKey and PrivateKeyFile empty to only use these.
+	Keys []TokenKeyConfig `yaml:"keys"`
+	// RotationGrace is how long a key still verifies tokens after the next
+	// key took over. Shorter than RefreshTTL logs users out on rotation.
+	RotationGrace time.Duration `yaml:"rotation_grace" env:"TOKENS_ROTATION_GRACE" env-default:"720h"`
+	// DenylistCapacity is how many revoked tokens the in-memory filter in
+	// front of the Redis denylist is sized for, more raise its false
+	// positives until they expire.
+	DenylistCapacity        int           `yaml:"denylist_capacity" env:"TOKENS_DENYLIST_CAPACITY" env-default:"100000"`
+	DenylistRebuildInterval time.Duration `yaml:"denylist_rebuild_interval" env:"TOKENS_DENYLIST_REBUILD_INTERVAL" env-default:"5m"`
+	// Sources are where requests carry their tokens, "header" for an
+	// Authorization: Bearer header and "cookie" for the token cookies. The
+	// first source present wins.
+	Sources []string `yaml:"sources" env:"TOKENS_SOURCES" env-default:"header,cookie"`
+}
+
+// TokenKeyConfig is a signing key of the key ring. ID is the kid of its
+// tokens, asymmetric keys default to their thumbprint.
+type TokenKeyConfig struct {
+	ID             string    `yaml:"id"`
+	Algorithm      string    `yaml:"algorithm"`
+	SecretKey      string    `yaml:"secret_key"`
+	PrivateKeyFile string    `yaml:"private_key_file"`
+	ActiveFrom     time.Time `yaml:"active_from"`
 }
 
 type RedisConfig struct {
@@ -27,6 +78,89 @@ type PostgresConfig struct {
 	DSN string `yaml:"dsn" env:"POSTGRES_DSN"`
 }
 
+type HealthConfig struct {
+	Enabled          bool          `yaml:"enabled" env:"HEALTH_ENABLED" env-default:"true"`
+	Tick             time.Duration `yaml:"tick" env:"HEALTH_TICK" env-default:"1m"`
+	Interval         time.Duration `yaml:"interval" env:"HEALTH_INTERVAL" env-default:"6h"`
+	RetryBackoff     time.Duration `yaml:"retry_backoff" env:"HEALTH_RETRY_BACKOFF" env-default:"5m"`
+	MaxBackoff       time.Duration `yaml:"max_backoff" env:"HEALTH_MAX_BACKOFF" env-default:"24h"`
+	FailureThreshold int           `yaml:"failure_threshold" env:"HEALTH_FAILURE_THRESHOLD" env-default:"2"`
+	Timeout          time.Duration `yaml:"timeout" env:"HEALTH_TIMEOUT" env-default:"10s"`
+	Concurrency      int           `yaml:"concurrency" env:"HEALTH_CONCURRENCY" env-default:"8"`
+	HostInterval     time.Duration `yaml:"host_interval" env:"HEALTH_HOST_INTERVAL" env-default:"1s"`
+	BatchSize        int           `yaml:"batch_size" env:"HEALTH_BATCH_SIZE" env-default:"200"`
+	HistoryRetention time.Duration `yaml:"history_retention" env:"HEALTH_HISTORY_RETENTION" env-default:"720h"`
+	PruneInterval    time.Duration `yaml:"prune_interval" env:"HEALTH_PRUNE_INTERVAL" env-default:"1h"`
+	UserAgent        string        `yaml:"user_agent" env:"HEALTH_USER_AGENT" env-default:"url-shortener-health-checker/1.0"`
+}
+
+type PreviewConfig struct {
+	FetchTimeout time.Duration `yaml:"fetch_timeout" env:"PREVIEW_FETCH_TIMEOUT" env-default:"3s"`
+	MaxBodyBytes int64         `yaml:"max_body_bytes" env:"PREVIEW_MAX_BODY_BYTES" env-default:"524288"`
+	UserAgent    string        `yaml:"user_agent" env:"PREVIEW_USER_AGENT" env-default:"url-shortener-unfurler/1.0"`
+}
+
+type InterstitialConfig struct {
+	Domains            []string      `yaml:"domains" env:"INTERSTITIAL_DOMAINS" env-separator:","`
+	SuspiciousPatterns []string      `yaml:"suspicious_patterns" env:"INTERSTITIAL_SUSPICIOUS_PATTERNS" env-separator:","`
+	RecentChangeWindow time.Duration `yaml:"recent_change_window" env:"INTERSTITIAL_RECENT_CHANGE_WINDOW" env-default:"72h"`
+	Countdown          time.Duration `yaml:"countdown" env:"INTERSTITIAL_COUNTDOWN" env-default:"5s"`
+}
+
+type AnalyticsConfig struct {
+	CountryHeader string `yaml:"country_header" env:"ANALYTICS_COUNTRY_HEADER" env-default:"CF-IPCountry"`
+	// IPHeader carries the client address when running behind a proxy.
+	// Empty means the remote address of the connection is used. It is read
+	// only on connections from TrustedProxies, addresses or CIDR networks.
+	IPHeader          string        `yaml:"ip_header" env:"ANALYTICS_IP_HEADER"`
+	TrustedProxies    []string      `yaml:"trusted_proxies" env:"ANALYTICS_TRUSTED_PROXIES" env-separator:","`
+	UniqueVisitorsTTL time.Duration `yaml:"unique_visitors_ttl" env:"ANALYTICS_UNIQUE_VISITORS_TTL" env-default:"2160h"`
+	// BotIPRangesFile lists networks of crawlers, see configs/bot_ip_ranges.txt
+	BotIPRangesFile string        `yaml:"bot_ip_ranges_file" env:"ANALYTICS_BOT_IP_RANGES_FILE"`
+	BotRateLimit    int64         `yaml:"bot_rate_limit" env:"ANALYTICS_BOT_RATE_LIMIT" env-default:"20"`
+	BotRateWindow   time.Duration `yaml:"bot_rate_window" env:"ANALYTICS_BOT_RATE_WINDOW" env-default:"1m"`
+	RollupInterval  time.Duration `yaml:"rollup_interval" env:"ANALYTICS_ROLLUP_INTERVAL" env-default:"10m"`
+	// RollupDelay is how long after its end an hour is rolled up.
+	RollupDelay time.Duration `yaml:"rollup_delay" env:"ANALYTICS_ROLLUP_DELAY" env-default:"5m"`
+	// RawRetention is how long raw clicks are kept, zero keeps them forever.
+	RawRetention time.Duration `yaml:"raw_retention" env:"ANALYTICS_RAW_RETENTION" env-default:"720h"`
+	// DashboardCacheTTL is how long account dashboards are served from cache.
+	DashboardCacheTTL time.Duration `yaml:"dashboard_cache_ttl" env:"ANALYTICS_DASHBOARD_CACHE_TTL" env-default:"1m"`
+	// RefererSourcesFile groups referers into sources, see configs/referer_sources.txt
+	RefererSourcesFile string `yaml:"referer_sources_file" env:"ANALYTICS_REFERER_SOURCES_FILE"`
+	// ClickQueueSize bounds clicks waiting to be recorded, more are dropped.
+	ClickQueueSize int `yaml:"click_queue_size" env:"ANALYTICS_CLICK_QUEUE_SIZE" env-default:"10000"`
+	ClickWorkers   int `yaml:"click_workers" env:"ANALYTICS_CLICK_WORKERS" env-default:"8"`
+	// ClickStreamsPerUser caps live click streams a user holds open on one
+	// instance.
+	ClickStreamsPerUser int `yaml:"click_streams_per_user" env:"ANALYTICS_CLICK_STREAMS_PER_USER" env-default:"5"`
+}
+
+// PrivacyConfig defaults follow the GDPR guidance of EU data protection
+// authorities: addresses are truncated like in common analytics tools and
+// both opt-out signals are honored.
+type PrivacyConfig struct {
+	IPv4PrefixBits int  `yaml:"ipv4_prefix_bits" env:"PRIVACY_IPV4_PREFIX_BITS" env-default:"24"`
+	IPv6PrefixBits int  `yaml:"ipv6_prefix_bits" env:"PRIVACY_IPV6_PREFIX_BITS" env-default:"48"`
+	HonorDNT       bool `yaml:"honor_dnt" env:"PRIVACY_HONOR_DNT" env-default:"true"`
+	HonorGPC       bool `yaml:"honor_gpc" env:"PRIVACY_HONOR_GPC" env-default:"true"`
+}
+
+type ExportConfig struct {
+	// Dir keeps result files of export jobs. It has to be shared by all
+	// instances.
+	Dir            string        `yaml:"dir" env:"EXPORT_DIR" env-default:"exports"`
+	AsyncThreshold int64         `yaml:"async_threshold" env:"EXPORT_ASYNC_THRESHOLD" env-default:"100000"`
+	PollInterval   time.Duration `yaml:"poll_interval" env:"EXPORT_POLL_INTERVAL" env-default:"10s"`
+	Retention      time.Duration `yaml:"retention" env:"EXPORT_RETENTION" env-default:"168h"`
+}
+
+type DomainsConfig struct {
+	LookupTimeout time.Duration `yaml:"lookup_timeout" env:"DOMAINS_LOOKUP_TIMEOUT" env-default:"5s"`
+	// HostCacheTTL is how long the domain of a request host is cached.
+	HostCacheTTL time.Duration `yaml:"host_cache_ttl" env:"DOMAINS_HOST_CACHE_TTL" env-default:"1m"`
+}
+
 type HTTPServerConfig struct {
 	Addr         string        `yaml:"addr" env:"HTTP_SERVER_ADDR" env-default:"localhost:8000"`
 	ReadTimeout  time.Duration `yaml:"read_timeout" env:"HTTP_SERVER_READ_TIMEOUT" env-default:"10s"`
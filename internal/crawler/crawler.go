@@ -0,0 +1,49 @@
+package crawler
+
+import "strings"
+
+// previewBots are user agent fragments of crawlers that follow links to build
+// chat and social network previews. Search engine crawlers are left out: they
+// index the destination and must get the same redirect as users.
+var previewBots = []string{
+	"facebookexternalhit",
+	"facebookcatalog",
+	"twitterbot",
+	"slackbot",
+	"slack-imgproxy",
+	"discordbot",
+	"telegrambot",
+	"whatsapp",
+	"linkedinbot",
+	"skypeuripreview",
+	"pinterestbot",
+	"redditbot",
+	"embedly",
+	"vkshare",
+	"viber",
+	"mastodon",
+	"bitlybot",
+	"google-pagerenderer",
+	"bingpreview",
+	"snapchat",
+	"iframely",
+	"outbrain",
+	"microsoftpreview",
+}
+
+// IsPreviewBot reports whether the user agent belongs to a known link preview
+// crawler.
+func IsPreviewBot(userAgent string) bool {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return false
+	}
+
+	for _, bot := range previewBots {
+		if strings.Contains(ua, bot) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,151 @@
+package customdomain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/database"
+	"roadmap.restapi/internal/errormapper"
+	"roadmap.restapi/internal/postgres"
+)
+
+type PostgresRepository struct {
+	db     *sqlx.DB
+	errMap *errormapper.ErrorMapper
+}
+
+func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db: db,
+		errMap: errormapper.NewErrorMapper(
+			errormapper.NewMapping(database.ErrNotFound, ErrDomainNotFound),
+			errormapper.NewMapping(database.ErrUniqueViolation, ErrDomainAlreadyExists),
+		),
+	}
+}
+
+func (r *PostgresRepository) Create(ctx context.Context, domain *Domain) error {
+	log := ctxlogging.Get(ctx)
+	rows, err := r.db.NamedQueryContext(ctx, `INSERT INTO domains (name, user_id, token)
+	VALUES (:name, :user_id, :token)
+	RETURNING *
+	`, domain)
+
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+	defer rows.Close()
+
+	rows.Next()
+	if err = rows.Err(); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	if err = rows.StructScan(domain); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) ByID(ctx context.Context, id uuid.UUID) (*Domain, error) {
+	log := ctxlogging.Get(ctx)
+	var domain Domain
+	err := r.db.GetContext(ctx, &domain, r.db.Rebind(`SELECT * FROM domains WHERE id = ?`), id)
+	if err != nil {
+		return nil, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return &domain, nil
+}
+
+func (r *PostgresRepository) ByUser(ctx context.Context, userID uuid.UUID) ([]Domain, error) {
+	log := ctxlogging.Get(ctx)
+	domains := []Domain{}
+	err := r.db.SelectContext(ctx, &domains, r.db.Rebind(`SELECT * FROM domains WHERE user_id = ? ORDER BY created_at`), userID)
+	if err != nil {
+		return domains, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return domains, nil
+}
+
+func (r *PostgresRepository) Verified(ctx context.Context, name string) (*Domain, error) {
+	log := ctxlogging.Get(ctx)
+	var domain Domain
+	err := r.db.GetContext(ctx, &domain, r.db.Rebind(`SELECT * FROM domains
+	WHERE name = ? AND verified_at IS NOT NULL`), name)
+	if err != nil {
+		return nil, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return &domain, nil
+}
+
+func (r *PostgresRepository) MarkVerified(ctx context.Context, id uuid.UUID, at time.Time) error {
+	log := ctxlogging.Get(ctx)
+	res, err := r.db.ExecContext(ctx, r.db.Rebind(`UPDATE domains SET verified_at = ? WHERE id = ?`), at, id)
+	if err != nil {
+		err = postgres.TranslateError(err, log)
+		if errors.Is(err, database.ErrUniqueViolation) {
+			return ErrDomainTaken
+		}
+		return r.errMap.MapAndLogUnmatched(err, log)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	if rows == 0 {
+		return ErrDomainNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	log := ctxlogging.Get(ctx)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+	defer tx.Rollback()
+
+	var deleted Domain
+	err = tx.GetContext(ctx, &deleted, tx.Rebind(`DELETE FROM domains WHERE id = ? RETURNING *`), id)
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	// links can only be placed on the verified registration of a name, the
+	// unverified ones of other users must not take them along
+	if deleted.IsVerified() {
+		// links transferred away from the owner are not the owner's to delete
+		var foreign bool
+		err = tx.GetContext(ctx, &foreign, tx.Rebind(`SELECT EXISTS (SELECT 1 FROM urls WHERE domain = ? AND author_id <> ?)`), deleted.Name, deleted.UserID)
+		if err != nil {
+			return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+		}
+
+		if foreign {
+			return ErrDomainHasForeignLinks
+		}
+
+		_, err = tx.ExecContext(ctx, tx.Rebind(`DELETE FROM urls WHERE domain = ? AND author_id = ?`), deleted.Name, deleted.UserID)
+		if err != nil {
+			return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
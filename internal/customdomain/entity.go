@@ -0,0 +1,35 @@
+package customdomain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VERIFICATION_PREFIX is the label under which the owner of a domain
+// publishes the verification TXT record.
+const VERIFICATION_PREFIX = "_shortener-verification"
+
+// Domain is a custom domain registered by a user to serve short links from.
+// Links can be placed on the domain only after its ownership is verified.
+type Domain struct {
+	ID         uuid.UUID  `db:"id"`
+	Name       string     `db:"name"`
+	UserID     uuid.UUID  `db:"user_id"`
+	Token      string     `db:"token"`
+	VerifiedAt *time.Time `db:"verified_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+func (d *Domain) IsVerified() bool {
+	return d.VerifiedAt != nil
+}
+
+// RecordName is the name of the TXT record that has to hold RecordValue.
+func (d *Domain) RecordName() string {
+	return VERIFICATION_PREFIX + "." + d.Name
+}
+
+func (d *Domain) RecordValue() string {
+	return "shortener-verification=" + d.Token
+}
@@ -0,0 +1,13 @@
+package customdomain
+
+import "errors"
+
+var (
+	ErrDomainNotFound        = errors.New("domain not found")
+	ErrDomainAlreadyExists   = errors.New("domain is already registered")
+	ErrDomainTaken           = errors.New("domain is verified by another user")
+	ErrDomainHasForeignLinks = errors.New("domain has links owned by other users")
+	ErrInvalidDomain         = errors.New("invalid domain name")
+	ErrUserIsNotOwner        = errors.New("this user is not owner of domain")
+	ErrVerificationFailed    = errors.New("verification record not found")
+)
@@ -0,0 +1,56 @@
+package customdomain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const hostKeyPrefix = "domain-host:"
+
+// RedisHostCache keeps resolved hosts as JSON for ttl. Hosts that are not
+// custom domains are cached as JSON null.
+type RedisHostCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewRedisHostCache(client *redis.Client, ttl time.Duration) *RedisHostCache {
+	return &RedisHostCache{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func (c *RedisHostCache) Get(ctx context.Context, host string) (*Domain, bool, error) {
+	raw, err := c.client.Get(ctx, hostKeyPrefix+host).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var domain *Domain
+	if err := json.Unmarshal(raw, &domain); err != nil {
+		return nil, false, err
+	}
+
+	return domain, true, nil
+}
+
+func (c *RedisHostCache) Set(ctx context.Context, host string, domain *Domain) error {
+	raw, err := json.Marshal(domain)
+	if err != nil {
+		return err
+	}
+
+	return c.client.Set(ctx, hostKeyPrefix+host, raw, c.ttl).Err()
+}
+
+func (c *RedisHostCache) Forget(ctx context.Context, host string) error {
+	return c.client.Del(ctx, hostKeyPrefix+host).Err()
+}
@@ -0,0 +1,36 @@
+package customdomain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	Create(ctx context.Context, domain *Domain) error
+	ByID(ctx context.Context, id uuid.UUID) (*Domain, error)
+	ByUser(ctx context.Context, userID uuid.UUID) ([]Domain, error)
+	// Verified returns the verified domain with name.
+	Verified(ctx context.Context, name string) (*Domain, error)
+	// MarkVerified returns ErrDomainTaken if another user has already
+	// verified the same name.
+	MarkVerified(ctx context.Context, id uuid.UUID, at time.Time) error
+	// Delete removes the domain together with the links placed on it. It
+	// returns ErrDomainHasForeignLinks while other users own links on it.
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// HostCache keeps results of ResolveHost for a short time.
+type HostCache interface {
+	// Get reports false when host is not cached. A cached nil domain means
+	// host is not a custom domain.
+	Get(ctx context.Context, host string) (*Domain, bool, error)
+	Set(ctx context.Context, host string, domain *Domain) error
+	Forget(ctx context.Context, host string) error
+}
+
+// Resolver looks up DNS TXT records.
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
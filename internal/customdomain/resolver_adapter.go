@@ -0,0 +1,27 @@
+package customdomain
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// NetResolver looks up TXT records with the system DNS resolver.
+type NetResolver struct {
+	resolver *net.Resolver
+	timeout  time.Duration
+}
+
+func NewNetResolver(timeout time.Duration) *NetResolver {
+	return &NetResolver{
+		resolver: net.DefaultResolver,
+		timeout:  timeout,
+	}
+}
+
+func (r *NetResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return r.resolver.LookupTXT(ctx, name)
+}
@@ -0,0 +1,200 @@
+package customdomain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/ctxlogging"
+)
+
+var nameRe = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+type UseCases struct {
+	repo     Repository
+	resolver Resolver
+	hosts    HostCache
+}
+
+func NewUseCases(repo Repository, resolver Resolver, hosts HostCache) *UseCases {
+	return &UseCases{
+		repo:     repo,
+		resolver: resolver,
+		hosts:    hosts,
+	}
+}
+
+// Normalize converts a domain name or a Host header value to the form domains
+// are stored in: lower case, without port and trailing dot.
+func Normalize(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	return strings.TrimSuffix(host, ".")
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// Register adds an unverified domain to the user. The returned domain holds
+// the TXT record the user has to publish to prove ownership.
+func (u *UseCases) Register(ctx context.Context, userID uuid.UUID, name string) (*Domain, error) {
+	name = Normalize(name)
+	if !nameRe.MatchString(name) {
+		return nil, ErrInvalidDomain
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	domain := &Domain{
+		Name:   name,
+		UserID: userID,
+		Token:  token,
+	}
+
+	if err = u.repo.Create(ctx, domain); err != nil {
+		return nil, err
+	}
+
+	return domain, nil
+}
+
+func (u *UseCases) ByUser(ctx context.Context, userID uuid.UUID) ([]Domain, error) {
+	return u.repo.ByUser(ctx, userID)
+}
+
+// Verify checks that the verification TXT record of the domain is published
+// and marks the domain as verified.
+func (u *UseCases) Verify(ctx context.Context, userID uuid.UUID, domainID uuid.UUID) (*Domain, error) {
+	domain, err := u.owned(ctx, userID, domainID)
+	if err != nil {
+		return nil, err
+	}
+
+	if domain.IsVerified() {
+		return domain, nil
+	}
+
+	records, err := u.resolver.LookupTXT(ctx, domain.RecordName())
+	if err != nil {
+		ctxlogging.Get(ctx).Debug("txt lookup failed", "record", domain.RecordName(), "err", err)
+		return nil, ErrVerificationFailed
+	}
+
+	found := false
+	for _, record := range records {
+		if strings.TrimSpace(record) == domain.RecordValue() {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return nil, ErrVerificationFailed
+	}
+
+	verifiedAt := time.Now().UTC()
+	if err = u.repo.MarkVerified(ctx, domain.ID, verifiedAt); err != nil {
+		return nil, err
+	}
+	domain.VerifiedAt = &verifiedAt
+	u.forgetHost(ctx, domain.Name)
+
+	return domain, nil
+}
+
+// Delete removes the domain of userID. Links placed on a verified domain are
+// deleted with it, the domain is kept while other users own links on it.
+func (u *UseCases) Delete(ctx context.Context, userID uuid.UUID, domainID uuid.UUID) error {
+	domain, err := u.owned(ctx, userID, domainID)
+	if err != nil {
+		return err
+	}
+
+	if err = u.repo.Delete(ctx, domainID); err != nil {
+		return err
+	}
+	u.forgetHost(ctx, domain.Name)
+
+	return nil
+}
+
+// ResolveHost returns the verified custom domain serving host. It returns
+// nil for hosts that are not custom domains, they serve links of the
+// default domain "". Results are cached, so it is cheap to call on every
+// redirect.
+func (u *UseCases) ResolveHost(ctx context.Context, host string) (*Domain, error) {
+	log := ctxlogging.Get(ctx)
+	host = Normalize(host)
+
+	domain, cached, err := u.hosts.Get(ctx, host)
+	if err != nil {
+		log.Warn("failed to read cached host", "host", host, "err", err)
+	}
+	if cached {
+		return domain, nil
+	}
+
+	domain, err = u.repo.Verified(ctx, host)
+	if err != nil && !errors.Is(err, ErrDomainNotFound) {
+		return nil, err
+	}
+
+	if err = u.hosts.Set(ctx, host, domain); err != nil {
+		log.Warn("failed to cache host", "host", host, "err", err)
+	}
+
+	return domain, nil
+}
+
+// forgetHost drops the cached resolution of name once its domain changed.
+// Until it expires, other instances may still serve the cached one if this
+// fails.
+func (u *UseCases) forgetHost(ctx context.Context, name string) {
+	if err := u.hosts.Forget(ctx, name); err != nil {
+		ctxlogging.Get(ctx).Warn("failed to forget cached host", "host", name, "err", err)
+	}
+}
+
+// IsVerifiedOwner reports whether userID can place links on the domain name.
+func (u *UseCases) IsVerifiedOwner(ctx context.Context, userID uuid.UUID, name string) (bool, error) {
+	domain, err := u.repo.Verified(ctx, Normalize(name))
+	if err != nil {
+		if errors.Is(err, ErrDomainNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return domain.UserID == userID, nil
+}
+
+func (u *UseCases) owned(ctx context.Context, userID uuid.UUID, domainID uuid.UUID) (*Domain, error) {
+	domain, err := u.repo.ByID(ctx, domainID)
+	if err != nil {
+		return nil, err
+	}
+
+	if domain.UserID != userID {
+		return nil, ErrUserIsNotOwner
+	}
+
+	return domain, nil
+}
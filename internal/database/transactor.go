@@ -0,0 +1,10 @@
+package database
+
+import "context"
+
+// Transactor runs fn in one transaction. Repositories called with the
+// context passed to fn take part in it, nested calls join the outer
+// transaction.
+type Transactor interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,139 @@
+package export
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Format string
+
+const (
+	FORMAT_CSV    Format = "csv"
+	FORMAT_NDJSON Format = "ndjson"
+)
+
+// Granularity selects the table rows are exported from.
+type Granularity string
+
+const (
+	GRANULARITY_RAW    Granularity = "raw"
+	GRANULARITY_HOURLY Granularity = "hourly"
+	GRANULARITY_DAILY  Granularity = "daily"
+)
+
+type Status string
+
+const (
+	STATUS_PENDING Status = "pending"
+	STATUS_RUNNING Status = "running"
+	STATUS_DONE    Status = "done"
+	STATUS_FAILED  Status = "failed"
+)
+
+var rawColumns = []string{
+	"url_domain", "url_id", "clicked_at", "country_code", "referer",
+	"device_type", "os_family", "browser_family", "is_bot",
+}
+
+var rollupColumns = []string{
+	"url_domain", "url_id", "bucket", "dimension", "key", "is_bot", "clicks",
+}
+
+// Columns returns the exportable columns of the granularity in their
+// default order.
+func Columns(granularity Granularity) []string {
+	if granularity == GRANULARITY_RAW {
+		return slices.Clone(rawColumns)
+	}
+	return slices.Clone(rollupColumns)
+}
+
+// Query selects the clicks of an export.
+type Query struct {
+	UserID uuid.UUID
+	// URLDomain and URLID select a single url, an empty URLID all urls of
+	// the user.
+	URLDomain   string
+	URLID       string
+	Granularity Granularity
+	Format      Format
+	Columns     []string
+	// From is inclusive, To exclusive.
+	From time.Time
+	To   time.Time
+}
+
+// Job is an export running in background. Its result is kept as a file.
+type Job struct {
+	ID          uuid.UUID   `db:"id"`
+	UserID      uuid.UUID   `db:"user_id"`
+	URLDomain   string      `db:"url_domain"`
+	URLID       string      `db:"url_id"`
+	Granularity Granularity `db:"granularity"`
+	Format      Format      `db:"format"`
+	Columns     string      `db:"columns"`
+	From        time.Time   `db:"from_time"`
+	To          time.Time   `db:"to_time"`
+	Status      Status      `db:"status"`
+	RowCount    int64       `db:"row_count"`
+	Error       *string     `db:"error"`
+	CreatedAt   time.Time   `db:"created_at"`
+	FinishedAt  *time.Time  `db:"finished_at"`
+	// StartedAt and HeartbeatAt are set while the job is running, the
+	// worker running it moves HeartbeatAt forward.
+	StartedAt   *time.Time `db:"started_at"`
+	HeartbeatAt *time.Time `db:"heartbeat_at"`
+	// Attempts counts the claims of the job. Only the worker of the last
+	// attempt may update it.
+	Attempts int `db:"attempts"`
+}
+
+func NewJob(q Query) *Job {
+	return &Job{
+		UserID:      q.UserID,
+		URLDomain:   q.URLDomain,
+		URLID:       q.URLID,
+		Granularity: q.Granularity,
+		Format:      q.Format,
+		Columns:     strings.Join(q.Columns, ","),
+		From:        q.From,
+		To:          q.To,
+		Status:      STATUS_PENDING,
+	}
+}
+
+func (j *Job) Query() Query {
+	return Query{
+		UserID:      j.UserID,
+		URLDomain:   j.URLDomain,
+		URLID:       j.URLID,
+		Granularity: j.Granularity,
+		Format:      j.Format,
+		Columns:     strings.Split(j.Columns, ","),
+		From:        j.From,
+		To:          j.To,
+	}
+}
+
+// FileName is the name of the result file of the job.
+func (j *Job) FileName() string {
+	return j.ID.String() + "." + string(j.Format)
+}
+
+// TempFileName is the name of the file attempt writes to before it is
+// renamed to FileName.
+func (j *Job) TempFileName(attempt int) string {
+	return j.FileName() + "." + strconv.Itoa(attempt) + ".tmp"
+}
+
+// ContentType returns the media type of exports in format.
+func ContentType(format Format) string {
+	if format == FORMAT_NDJSON {
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
@@ -0,0 +1,15 @@
+package export
+
+import "errors"
+
+var (
+	ErrJobNotFound        = errors.New("export job not found")
+	ErrUserIsNotOwner     = errors.New("this user is not owner of export job")
+	ErrJobNotFinished     = errors.New("export job is not finished")
+	ErrInvalidFormat      = errors.New("format must be csv or ndjson")
+	ErrInvalidGranularity = errors.New("granularity must be raw, hourly or daily")
+	ErrInvalidColumn      = errors.New("unknown export column")
+	ErrInvalidRange       = errors.New("from must be before to")
+	ErrJobLeaseLost       = errors.New("export job was taken over by another worker")
+	ErrJobAbandoned       = errors.New("export job was interrupted too many times")
+)
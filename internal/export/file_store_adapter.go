@@ -0,0 +1,49 @@
+package export
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// DiskFileStore keeps files in a directory. When several instances run, the
+// directory has to be shared so any of them can serve a download.
+type DiskFileStore struct {
+	dir string
+}
+
+func NewDiskFileStore(dir string) (*DiskFileStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &DiskFileStore{
+		dir: dir,
+	}, nil
+}
+
+func (s *DiskFileStore) path(name string) string {
+	return filepath.Join(s.dir, filepath.Base(name))
+}
+
+func (s *DiskFileStore) Create(name string) (io.WriteCloser, error) {
+	return os.OpenFile(s.path(name), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+}
+
+func (s *DiskFileStore) Open(name string) (io.ReadCloser, error) {
+	return os.Open(s.path(name))
+}
+
+func (s *DiskFileStore) Rename(from string, to string) error {
+	return os.Rename(s.path(from), s.path(to))
+}
+
+func (s *DiskFileStore) Remove(name string) error {
+	err := os.Remove(s.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
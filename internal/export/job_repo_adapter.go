@@ -0,0 +1,169 @@
+package export
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/database"
+	"roadmap.restapi/internal/errormapper"
+	"roadmap.restapi/internal/postgres"
+)
+
+type PostgresJobRepository struct {
+	db     *sqlx.DB
+	errMap *errormapper.ErrorMapper
+}
+
+func NewPostgresJobRepository(db *sqlx.DB) *PostgresJobRepository {
+	return &PostgresJobRepository{
+		db: db,
+		errMap: errormapper.NewErrorMapper(
+			errormapper.NewMapping(database.ErrNotFound, ErrJobNotFound),
+		),
+	}
+}
+
+func (r *PostgresJobRepository) Create(ctx context.Context, job *Job) error {
+	log := ctxlogging.Get(ctx)
+	rows, err := r.db.NamedQueryContext(ctx, `INSERT INTO export_jobs (user_id, url_domain, url_id, granularity, format, columns, from_time, to_time)
+	VALUES (:user_id, :url_domain, :url_id, :granularity, :format, :columns, :from_time, :to_time)
+	RETURNING *
+	`, job)
+
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+	defer rows.Close()
+
+	rows.Next()
+	if err = rows.Err(); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	if err = rows.StructScan(job); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
+
+func (r *PostgresJobRepository) ByID(ctx context.Context, id uuid.UUID) (*Job, error) {
+	log := ctxlogging.Get(ctx)
+	var job Job
+	err := r.db.GetContext(ctx, &job, r.db.Rebind(`SELECT * FROM export_jobs WHERE id = ?`), id)
+	if err != nil {
+		return nil, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return &job, nil
+}
+
+func (r *PostgresJobRepository) ByUser(ctx context.Context, userID uuid.UUID) ([]Job, error) {
+	log := ctxlogging.Get(ctx)
+	jobs := []Job{}
+	err := r.db.SelectContext(ctx, &jobs, r.db.Rebind(`SELECT * FROM export_jobs
+	WHERE user_id = ? ORDER BY created_at DESC`), userID)
+	if err != nil {
+		return jobs, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return jobs, nil
+}
+
+func (r *PostgresJobRepository) ClaimPending(ctx context.Context, now time.Time, staleBefore time.Time, maxAttempts int) (*Job, error) {
+	log := ctxlogging.Get(ctx)
+	var job Job
+	err := r.db.GetContext(ctx, &job, r.db.Rebind(`UPDATE export_jobs
+	SET status = ?, started_at = ?, heartbeat_at = ?, attempts = attempts + 1
+	WHERE id = (
+		SELECT id FROM export_jobs
+		WHERE status = ? OR (status = ? AND heartbeat_at < ? AND attempts < ?)
+		ORDER BY created_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING *`), STATUS_RUNNING, now, now, STATUS_PENDING, STATUS_RUNNING, staleBefore, maxAttempts)
+	if err != nil {
+		return nil, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return &job, nil
+}
+
+func (r *PostgresJobRepository) FailAbandoned(ctx context.Context, now time.Time, staleBefore time.Time, maxAttempts int, reason string) error {
+	log := ctxlogging.Get(ctx)
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(`UPDATE export_jobs
+	SET status = ?, error = ?, finished_at = ?
+	WHERE status = ? AND heartbeat_at < ? AND attempts >= ?`), STATUS_FAILED, reason, now, STATUS_RUNNING, staleBefore, maxAttempts)
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
+
+func (r *PostgresJobRepository) Heartbeat(ctx context.Context, id uuid.UUID, attempt int, at time.Time) error {
+	log := ctxlogging.Get(ctx)
+	res, err := r.db.ExecContext(ctx, r.db.Rebind(`UPDATE export_jobs SET heartbeat_at = ?
+	WHERE id = ? AND status = ? AND attempts = ?`), at, id, STATUS_RUNNING, attempt)
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	if rows == 0 {
+		return ErrJobLeaseLost
+	}
+
+	return nil
+}
+
+func (r *PostgresJobRepository) Finish(ctx context.Context, job *Job) error {
+	log := ctxlogging.Get(ctx)
+	res, err := r.db.NamedExecContext(ctx, `UPDATE export_jobs
+	SET status = :status, row_count = :row_count, error = :error, finished_at = :finished_at
+	WHERE id = :id AND status = 'running' AND attempts = :attempts`, job)
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	if rows == 0 {
+		return ErrJobLeaseLost
+	}
+
+	return nil
+}
+
+func (r *PostgresJobRepository) FinishedBefore(ctx context.Context, t time.Time) ([]Job, error) {
+	log := ctxlogging.Get(ctx)
+	jobs := []Job{}
+	err := r.db.SelectContext(ctx, &jobs, r.db.Rebind(`SELECT * FROM export_jobs
+	WHERE finished_at < ?`), t)
+	if err != nil {
+		return jobs, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return jobs, nil
+}
+
+func (r *PostgresJobRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	log := ctxlogging.Get(ctx)
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(`DELETE FROM export_jobs WHERE id = ?`), id)
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
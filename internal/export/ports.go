@@ -0,0 +1,51 @@
+package export
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RowSource reads the rows of an export.
+type RowSource interface {
+	Count(ctx context.Context, q Query) (int64, error)
+	// Rows calls fn with the values of the selected columns of each row,
+	// one row at a time, and stops on the first error.
+	Rows(ctx context.Context, q Query, fn func(values []any) error) error
+}
+
+type JobRepository interface {
+	Create(ctx context.Context, job *Job) error
+	ByID(ctx context.Context, id uuid.UUID) (*Job, error)
+	ByUser(ctx context.Context, userID uuid.UUID) ([]Job, error)
+	// ClaimPending marks the oldest pending job running at now, counts the
+	// attempt and returns the job, ErrJobNotFound if there is none. Running
+	// jobs whose last heartbeat is before staleBefore are claimed again while
+	// they have less than maxAttempts, their worker is considered dead. A job
+	// is claimed by one instance at a time.
+	ClaimPending(ctx context.Context, now time.Time, staleBefore time.Time, maxAttempts int) (*Job, error)
+	// FailAbandoned fails running jobs whose last heartbeat is before
+	// staleBefore and that can not be claimed again, with reason as error.
+	FailAbandoned(ctx context.Context, now time.Time, staleBefore time.Time, maxAttempts int, reason string) error
+	// Heartbeat records that attempt of the running job is still worked on.
+	// It returns ErrJobLeaseLost if the job was claimed again since.
+	Heartbeat(ctx context.Context, id uuid.UUID, attempt int, at time.Time) error
+	// Finish stores status, row count, error and finish time of the job. It
+	// returns ErrJobLeaseLost if the job was claimed again since its
+	// attempt.
+	Finish(ctx context.Context, job *Job) error
+	// FinishedBefore returns jobs finished before t.
+	FinishedBefore(ctx context.Context, t time.Time) ([]Job, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// FileStore keeps result files of export jobs.
+type FileStore interface {
+	Create(name string) (io.WriteCloser, error)
+	Open(name string) (io.ReadCloser, error)
+	// Rename replaces the file to with the file from.
+	Rename(from string, to string) error
+	Remove(name string) error
+}
@@ -0,0 +1,85 @@
+package export
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/errormapper"
+	"roadmap.restapi/internal/postgres"
+)
+
+type PostgresRowSource struct {
+	db     *sqlx.DB
+	errMap *errormapper.ErrorMapper
+}
+
+func NewPostgresRowSource(db *sqlx.DB) *PostgresRowSource {
+	return &PostgresRowSource{
+		db:     db,
+		errMap: errormapper.NewErrorMapper(),
+	}
+}
+
+// from returns the FROM and WHERE clauses selecting the rows of q, their
+// arguments and the time column rows are ordered by.
+func (r *PostgresRowSource) from(q Query) (string, []any, string) {
+	table, timeColumn := "clicks", "clicked_at"
+	switch q.Granularity {
+	case GRANULARITY_HOURLY:
+		table, timeColumn = "click_rollups_hourly", "bucket"
+	case GRANULARITY_DAILY:
+		table, timeColumn = "click_rollups_daily", "bucket"
+	}
+
+	if q.URLID == "" {
+		return table + ` WHERE (url_domain, url_id) IN (SELECT domain, id FROM urls WHERE author_id = ?)
+		AND ` + timeColumn + ` >= ? AND ` + timeColumn + ` < ?`, []any{q.UserID, q.From, q.To}, timeColumn
+	}
+
+	return table + ` WHERE url_domain = ? AND url_id = ?
+		AND ` + timeColumn + ` >= ? AND ` + timeColumn + ` < ?`, []any{q.URLDomain, q.URLID, q.From, q.To}, timeColumn
+}
+
+func (r *PostgresRowSource) Count(ctx context.Context, q Query) (int64, error) {
+	log := ctxlogging.Get(ctx)
+	from, args, _ := r.from(q)
+
+	var count int64
+	err := r.db.GetContext(ctx, &count, r.db.Rebind(`SELECT COUNT(*) FROM `+from), args...)
+	if err != nil {
+		return 0, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return count, nil
+}
+
+func (r *PostgresRowSource) Rows(ctx context.Context, q Query, fn func(values []any) error) error {
+	log := ctxlogging.Get(ctx)
+	from, args, timeColumn := r.from(q)
+
+	// columns were validated against Columns, only known names end up here
+	rows, err := r.db.QueryxContext(ctx, r.db.Rebind(`SELECT `+strings.Join(q.Columns, ", ")+` FROM `+from+` ORDER BY `+timeColumn), args...)
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		values, err := rows.SliceScan()
+		if err != nil {
+			return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+		}
+
+		if err = fn(values); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
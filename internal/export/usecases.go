@@ -0,0 +1,135 @@
+package export
+
+import (
+	"context"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UseCases struct {
+	source         RowSource
+	jobs           JobRepository
+	files          FileStore
+	asyncThreshold int64
+}
+
+// NewUseCases creates the exports. Exports of more than asyncThreshold rows
+// run as background jobs.
+func NewUseCases(source RowSource, jobs JobRepository, files FileStore, asyncThreshold int64) *UseCases {
+	return &UseCases{
+		source:         source,
+		jobs:           jobs,
+		files:          files,
+		asyncThreshold: asyncThreshold,
+	}
+}
+
+// Validate checks q and fills in the defaults: all columns of the
+// granularity and an open end of today.
+func (u *UseCases) Validate(q *Query) error {
+	if q.Format != FORMAT_CSV && q.Format != FORMAT_NDJSON {
+		return ErrInvalidFormat
+	}
+
+	if q.Granularity != GRANULARITY_RAW && q.Granularity != GRANULARITY_HOURLY && q.Granularity != GRANULARITY_DAILY {
+		return ErrInvalidGranularity
+	}
+
+	known := Columns(q.Granularity)
+	if len(q.Columns) == 0 {
+		q.Columns = known
+	}
+	for _, column := range q.Columns {
+		if !slices.Contains(known, column) {
+			return ErrInvalidColumn
+		}
+	}
+
+	if q.To.IsZero() {
+		q.To = time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	}
+	if !q.From.Before(q.To) {
+		return ErrInvalidRange
+	}
+
+	return nil
+}
+
+// IsLarge tells whether q selects too many rows to be streamed right away.
+func (u *UseCases) IsLarge(ctx context.Context, q Query) (bool, error) {
+	count, err := u.source.Count(ctx, q)
+	if err != nil {
+		return false, err
+	}
+
+	return count > u.asyncThreshold, nil
+}
+
+// Stream writes the rows of a validated query to w as they are read, so
+// memory use does not depend on the size of the export.
+func (u *UseCases) Stream(ctx context.Context, q Query, w io.Writer) (int64, error) {
+	writer, err := NewWriter(q.Format, w, q.Columns)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = u.source.Rows(ctx, q, func(values []any) error {
+		count++
+		return writer.WriteRow(values)
+	})
+	if err != nil {
+		return count, err
+	}
+
+	return count, writer.Flush()
+}
+
+// Schedule creates a background job for a validated query.
+func (u *UseCases) Schedule(ctx context.Context, q Query) (*Job, error) {
+	job := NewJob(q)
+	if err := u.jobs.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (u *UseCases) Jobs(ctx context.Context, userID uuid.UUID) ([]Job, error) {
+	return u.jobs.ByUser(ctx, userID)
+}
+
+func (u *UseCases) Job(ctx context.Context, userID uuid.UUID, jobID uuid.UUID) (*Job, error) {
+	job, err := u.jobs.ByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.UserID != userID {
+		return nil, ErrUserIsNotOwner
+	}
+
+	return job, nil
+}
+
+// Download opens the result file of a finished job of the user.
+func (u *UseCases) Download(ctx context.Context, userID uuid.UUID, jobID uuid.UUID) (*Job, io.ReadCloser, error) {
+	job, err := u.Job(ctx, userID, jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if job.Status != STATUS_DONE {
+		return nil, nil, ErrJobNotFinished
+	}
+
+	file, err := u.files.Open(job.FileName())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return job, file, nil
+}
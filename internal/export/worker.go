@@ -0,0 +1,204 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/ctxlogging"
+)
+
+const (
+	// JOB_HEARTBEAT is how often a running job reports it is alive.
+	JOB_HEARTBEAT = 30 * time.Second
+	// JOB_LEASE is how long a job may go without a heartbeat before another
+	// worker takes it over.
+	JOB_LEASE = 5 * JOB_HEARTBEAT
+	// JOB_MAX_ATTEMPTS bounds how often a job is claimed. A job that keeps
+	// taking its worker down fails instead of being retried forever.
+	JOB_MAX_ATTEMPTS = 3
+)
+
+// Worker runs pending export jobs and removes jobs and files once they are
+// older than the retention. Several workers may run on different instances,
+// every job is claimed by one of them.
+type Worker struct {
+	uc        *UseCases
+	retention time.Duration
+}
+
+func NewWorker(uc *UseCases, retention time.Duration) *Worker {
+	return &Worker{
+		uc:        uc,
+		retention: retention,
+	}
+}
+
+func (w *Worker) Run(ctx context.Context, interval time.Duration) {
+	log := ctxlogging.Get(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.RunPending(ctx); err != nil {
+				log.Error("export jobs failed", "err", err)
+			}
+			if err := w.Cleanup(ctx, time.Now().UTC()); err != nil {
+				log.Error("export cleanup failed", "err", err)
+			}
+		}
+	}
+}
+
+// RunPending runs jobs until none is pending, including jobs left running
+// by a worker that died.
+func (w *Worker) RunPending(ctx context.Context) error {
+	log := ctxlogging.Get(ctx)
+	now := time.Now().UTC()
+	err := w.uc.jobs.FailAbandoned(ctx, now, now.Add(-JOB_LEASE), JOB_MAX_ATTEMPTS, ErrJobAbandoned.Error())
+	if err != nil {
+		return err
+	}
+
+	for {
+		now = time.Now().UTC()
+		job, err := w.uc.jobs.ClaimPending(ctx, now, now.Add(-JOB_LEASE), JOB_MAX_ATTEMPTS)
+		if errors.Is(err, ErrJobNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		runCtx, stop := w.heartbeat(ctx, job.ID, job.Attempts)
+		w.run(runCtx, job)
+		stop()
+
+		err = w.uc.jobs.Finish(ctx, job)
+		if errors.Is(err, ErrJobLeaseLost) {
+			log.Warn("export job was taken over", "jobID", job.ID, "attempt", job.Attempts)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// heartbeat keeps the lease of attempt until the returned func is called.
+// The returned context is canceled once the job was taken over.
+func (w *Worker) heartbeat(ctx context.Context, jobID uuid.UUID, attempt int) (context.Context, func()) {
+	log := ctxlogging.Get(ctx)
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(JOB_HEARTBEAT)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := w.uc.jobs.Heartbeat(ctx, jobID, attempt, time.Now().UTC())
+				if errors.Is(err, ErrJobLeaseLost) {
+					cancel()
+					return
+				}
+				if err != nil {
+					log.Error("export job heartbeat failed", "jobID", jobID, "err", err)
+				}
+			}
+		}
+	}()
+
+	return ctx, func() {
+		cancel()
+		<-done
+	}
+}
+
+// run writes the result file of job and records the outcome on it.
+func (w *Worker) run(ctx context.Context, job *Job) {
+	log := ctxlogging.Get(ctx)
+
+	count, err := w.write(ctx, job)
+	now := time.Now().UTC()
+	job.RowCount = count
+	job.FinishedAt = &now
+	if err != nil {
+		log.Error("export job failed", "jobID", job.ID, "err", err)
+		msg := err.Error()
+		job.Status = STATUS_FAILED
+		job.Error = &msg
+		return
+	}
+
+	job.Status = STATUS_DONE
+	log.Debug("export job done", "jobID", job.ID, "rows", count)
+}
+
+// write streams the rows into the file of the attempt and publishes it as
+// the result file, unless the job was taken over in the meantime.
+func (w *Worker) write(ctx context.Context, job *Job) (int64, error) {
+	log := ctxlogging.Get(ctx)
+	tmp := job.TempFileName(job.Attempts)
+	file, err := w.uc.files.Create(tmp)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := w.uc.Stream(ctx, job.Query(), file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = w.uc.jobs.Heartbeat(ctx, job.ID, job.Attempts, time.Now().UTC())
+	}
+
+	if err == nil {
+		err = w.uc.files.Rename(tmp, job.FileName())
+	}
+
+	if err != nil {
+		if err := w.uc.files.Remove(tmp); err != nil {
+			log.Error("failed to remove export file", "jobID", job.ID, "err", err)
+		}
+	}
+
+	return count, err
+}
+
+// Cleanup deletes jobs finished before now minus the retention together
+// with their files.
+func (w *Worker) Cleanup(ctx context.Context, now time.Time) error {
+	jobs, err := w.uc.jobs.FinishedBefore(ctx, now.Add(-w.retention))
+	if err != nil {
+		return err
+	}
+
+	for i := range jobs {
+		if err = w.uc.files.Remove(jobs[i].FileName()); err != nil {
+			return err
+		}
+		// attempts of workers that died leave their files behind
+		for attempt := 1; attempt <= jobs[i].Attempts; attempt++ {
+			if err = w.uc.files.Remove(jobs[i].TempFileName(attempt)); err != nil {
+				return err
+			}
+		}
+		if err = w.uc.jobs.Delete(ctx, jobs[i].ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Writer encodes export rows one at a time.
+type Writer interface {
+	WriteRow(values []any) error
+	// Flush writes buffered rows to the underlying writer.
+	Flush() error
+}
+
+// NewWriter creates the writer of format. CSV exports start with a header
+// line.
+func NewWriter(format Format, w io.Writer, columns []string) (Writer, error) {
+	switch format {
+	case FORMAT_CSV:
+		cw := &csvWriter{w: csv.NewWriter(w), record: make([]string, len(columns))}
+		if err := cw.w.Write(columns); err != nil {
+			return nil, err
+		}
+		return cw, nil
+	case FORMAT_NDJSON:
+		keys := make([][]byte, len(columns))
+		for i, column := range columns {
+			key, err := json.Marshal(column)
+			if err != nil {
+				return nil, err
+			}
+			keys[i] = key
+		}
+		return &ndjsonWriter{w: bufio.NewWriter(w), keys: keys}, nil
+	default:
+		return nil, ErrInvalidFormat
+	}
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func (c *csvWriter) WriteRow(values []any) error {
+	for i, v := range values {
+		c.record[i] = csvValue(v)
+	}
+	return c.w.Write(c.record)
+}
+
+// neutralizeFormula keeps spreadsheets from evaluating text values such as
+// referers as formulas (CSV injection) by prefixing them with a quote.
+func neutralizeFormula(value string) string {
+	if value == "" {
+		return value
+	}
+
+	switch value[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + value
+	}
+
+	return value
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func csvValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return neutralizeFormula(v)
+	case []byte:
+		return neutralizeFormula(string(v))
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type ndjsonWriter struct {
+	w    *bufio.Writer
+	keys [][]byte
+	line bytes.Buffer
+}
+
+// WriteRow writes the row as an object keeping the column order.
+func (n *ndjsonWriter) WriteRow(values []any) error {
+	n.line.Reset()
+	n.line.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			n.line.WriteByte(',')
+		}
+		n.line.Write(n.keys[i])
+		n.line.WriteByte(':')
+
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		if t, ok := v.(time.Time); ok {
+			v = t.UTC().Format(time.RFC3339)
+		}
+		value, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		n.line.Write(value)
+	}
+	n.line.WriteString("}\n")
+
+	_, err := n.w.Write(n.line.Bytes())
+	return err
+}
+
+func (n *ndjsonWriter) Flush() error {
+	return n.w.Flush()
+}
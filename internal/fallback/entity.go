@@ -0,0 +1,46 @@
+package fallback
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Mode string
+
+const (
+	// MODE_NOT_FOUND answers with the default error page.
+	MODE_NOT_FOUND Mode = "not_found"
+	// MODE_REDIRECT sends visitors to RedirectURL, e.g. a homepage.
+	MODE_REDIRECT Mode = "redirect"
+	// MODE_TEMPLATE renders the user provided Template.
+	MODE_TEMPLATE Mode = "template"
+)
+
+type Reason string
+
+const (
+	REASON_NOT_FOUND Reason = "not_found"
+	REASON_EXPIRED   Reason = "expired"
+)
+
+// Page is the behavior configured by a user for links that can not be
+// served. Domain is empty for the user wide default, otherwise it is a
+// custom domain of the user and takes precedence over the default.
+type Page struct {
+	ID          uuid.UUID `db:"id"`
+	UserID      uuid.UUID `db:"user_id"`
+	Domain      string    `db:"domain"`
+	Mode        Mode      `db:"mode"`
+	RedirectURL string    `db:"redirect_url"`
+	Template    string    `db:"template"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+// TemplateData is what a user template can access.
+type TemplateData struct {
+	Slug   string
+	Domain string
+	Reason Reason
+}
@@ -0,0 +1,12 @@
+package fallback
+
+import "errors"
+
+var (
+	ErrPageNotFound     = errors.New("fallback page not found")
+	ErrInvalidMode      = errors.New("invalid fallback mode")
+	ErrInvalidRedirect  = errors.New("redirect url must be an absolute http(s) url")
+	ErrInvalidTemplate  = errors.New("invalid fallback template")
+	ErrTemplateTooLarge = errors.New("fallback template is too large")
+	ErrDomainNotAllowed = errors.New("domain is not verified by this user")
+)
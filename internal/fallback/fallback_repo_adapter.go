@@ -0,0 +1,100 @@
+package fallback
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/database"
+	"roadmap.restapi/internal/errormapper"
+	"roadmap.restapi/internal/postgres"
+)
+
+type PostgresRepository struct {
+	db     *sqlx.DB
+	errMap *errormapper.ErrorMapper
+}
+
+func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db: db,
+		errMap: errormapper.NewErrorMapper(
+			errormapper.NewMapping(database.ErrNotFound, ErrPageNotFound),
+			errormapper.NewMapping(database.ErrCheckViolation, ErrInvalidMode),
+		),
+	}
+}
+
+func (r *PostgresRepository) Save(ctx context.Context, page *Page) error {
+	log := ctxlogging.Get(ctx)
+	rows, err := r.db.NamedQueryContext(ctx, `INSERT INTO fallback_pages (user_id, domain, mode, redirect_url, template)
+	VALUES (:user_id, :domain, :mode, :redirect_url, :template)
+	ON CONFLICT (user_id, domain) DO UPDATE
+	SET mode = EXCLUDED.mode,
+		redirect_url = EXCLUDED.redirect_url,
+		template = EXCLUDED.template,
+		updated_at = CURRENT_TIMESTAMP
+	RETURNING *
+	`, page)
+
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+	defer rows.Close()
+
+	rows.Next()
+	if err = rows.Err(); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	if err = rows.StructScan(page); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) Get(ctx context.Context, userID uuid.UUID, domain string) (*Page, error) {
+	log := ctxlogging.Get(ctx)
+	var page Page
+	err := r.db.GetContext(ctx, &page, r.db.Rebind(`SELECT * FROM fallback_pages
+	WHERE user_id = ? AND domain = ?`), userID, domain)
+	if err != nil {
+		return nil, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return &page, nil
+}
+
+func (r *PostgresRepository) ByUser(ctx context.Context, userID uuid.UUID) ([]Page, error) {
+	log := ctxlogging.Get(ctx)
+	pages := []Page{}
+	err := r.db.SelectContext(ctx, &pages, r.db.Rebind(`SELECT * FROM fallback_pages
+	WHERE user_id = ? ORDER BY domain`), userID)
+	if err != nil {
+		return pages, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return pages, nil
+}
+
+func (r *PostgresRepository) Delete(ctx context.Context, userID uuid.UUID, domain string) error {
+	log := ctxlogging.Get(ctx)
+	res, err := r.db.ExecContext(ctx, r.db.Rebind(`DELETE FROM fallback_pages
+	WHERE user_id = ? AND domain = ?`), userID, domain)
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	if rows == 0 {
+		return ErrPageNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package fallback
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	// Save creates the page or replaces the page of the same user and
+	// domain.
+	Save(ctx context.Context, page *Page) error
+	Get(ctx context.Context, userID uuid.UUID, domain string) (*Page, error)
+	ByUser(ctx context.Context, userID uuid.UUID) ([]Page, error)
+	Delete(ctx context.Context, userID uuid.UUID, domain string) error
+}
+
+// DomainVerifier tells whether a user owns a custom domain.
+type DomainVerifier interface {
+	IsVerifiedOwner(ctx context.Context, userID uuid.UUID, domain string) (bool, error)
+}
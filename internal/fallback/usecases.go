@@ -0,0 +1,142 @@
+package fallback
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html/template"
+	"io"
+	neturl "net/url"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/ctxlogging"
+)
+
+// MAX_TEMPLATE_SIZE limits both the stored template and its rendered output.
+const MAX_TEMPLATE_SIZE = 64 * 1024
+
+type UseCases struct {
+	repo    Repository
+	domains DomainVerifier
+}
+
+func NewUseCases(repo Repository, domains DomainVerifier) *UseCases {
+	return &UseCases{
+		repo:    repo,
+		domains: domains,
+	}
+}
+
+// parseTemplate parses a user template. html/template escapes every value
+// according to its context, and since templates only get TemplateData
+// they can not reach anything else.
+func parseTemplate(text string) (*template.Template, error) {
+	if len(text) > MAX_TEMPLATE_SIZE {
+		return nil, ErrTemplateTooLarge
+	}
+
+	tmpl, err := template.New("fallback").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidTemplate, err)
+	}
+
+	return tmpl, nil
+}
+
+// Save validates and stores the fallback page of the user. A page for a
+// custom domain can be set only by the owner of the domain.
+func (u *UseCases) Save(ctx context.Context, page *Page) error {
+	switch page.Mode {
+	case MODE_NOT_FOUND:
+		page.RedirectURL = ""
+		page.Template = ""
+	case MODE_REDIRECT:
+		parsed, err := neturl.Parse(page.RedirectURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return ErrInvalidRedirect
+		}
+		page.Template = ""
+	case MODE_TEMPLATE:
+		if _, err := parseTemplate(page.Template); err != nil {
+			return err
+		}
+		page.RedirectURL = ""
+	default:
+		return ErrInvalidMode
+	}
+
+	if page.Domain != "" {
+		allowed, err := u.domains.IsVerifiedOwner(ctx, page.UserID, page.Domain)
+		if err != nil {
+			return err
+		}
+
+		if !allowed {
+			return ErrDomainNotAllowed
+		}
+	}
+
+	return u.repo.Save(ctx, page)
+}
+
+func (u *UseCases) ByUser(ctx context.Context, userID uuid.UUID) ([]Page, error) {
+	return u.repo.ByUser(ctx, userID)
+}
+
+func (u *UseCases) Delete(ctx context.Context, userID uuid.UUID, domain string) error {
+	return u.repo.Delete(ctx, userID, domain)
+}
+
+// Resolve returns the page configured for the domain of the user, falling
+// back to the user wide page. It returns nil if the user configured
+// nothing.
+func (u *UseCases) Resolve(ctx context.Context, userID uuid.UUID, domain string) (*Page, error) {
+	domains := []string{""}
+	if domain != "" {
+		domains = []string{domain, ""}
+	}
+
+	for _, d := range domains {
+		page, err := u.repo.Get(ctx, userID, d)
+		if err == nil {
+			return page, nil
+		}
+
+		if !errors.Is(err, ErrPageNotFound) {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+// Render executes the template of page into w. Nothing is written if the
+// template fails or its output exceeds MAX_TEMPLATE_SIZE.
+func (u *UseCases) Render(ctx context.Context, w io.Writer, page *Page, data TemplateData) error {
+	tmpl, err := parseTemplate(page.Template)
+	if err != nil {
+		return err
+	}
+
+	buf := limitedBuffer{limit: MAX_TEMPLATE_SIZE}
+	if err = tmpl.Execute(&buf, data); err != nil {
+		ctxlogging.Get(ctx).Debug("failed to render fallback template", "pageID", page.ID, "err", err)
+		return errors.Join(ErrInvalidTemplate, err)
+	}
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, ErrTemplateTooLarge
+	}
+
+	return b.Buffer.Write(p)
+}
@@ -0,0 +1,207 @@
+package health
+
+import (
+	"context"
+	neturl "net/url"
+	"sync"
+	"time"
+
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/database"
+	"roadmap.restapi/internal/outbox"
+)
+
+const (
+	TOPIC_URL_BROKEN    = "url.health.broken"
+	TOPIC_URL_RECOVERED = "url.health.recovered"
+
+	// CLAIM_LEASE bounds how long a link claimed by an instance that died
+	// mid-batch is kept from the other instances.
+	CLAIM_LEASE = 10 * time.Minute
+)
+
+type CheckerOptions struct {
+	// Interval between checks of a healthy destination.
+	Interval time.Duration
+	// RetryBackoff is the delay before re-checking a failed destination. It
+	// doubles with every consecutive failure up to MaxBackoff.
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+	// FailureThreshold is the number of consecutive failures after which a
+	// link is flagged as broken.
+	FailureThreshold int
+	Concurrency      int
+	// HostInterval is the minimal gap between two requests to one host.
+	HostInterval     time.Duration
+	BatchSize        int
+	HistoryRetention time.Duration
+	// PruneInterval is the gap between two deletions of the history older
+	// than HistoryRetention.
+	PruneInterval time.Duration
+}
+
+// Checker periodically probes link destinations and flags broken ones.
+type Checker struct {
+	tx      database.Transactor
+	repo    Repository
+	prober  Prober
+	outbox  outbox.Repository
+	opts    CheckerOptions
+	limiter *hostLimiter
+}
+
+func NewChecker(tx database.Transactor, repo Repository, prober Prober, outbox outbox.Repository, opts CheckerOptions) *Checker {
+	return &Checker{
+		tx:      tx,
+		repo:    repo,
+		prober:  prober,
+		outbox:  outbox,
+		opts:    opts,
+		limiter: newHostLimiter(opts.HostInterval),
+	}
+}
+
+func (c *Checker) Run(ctx context.Context, tick time.Duration) {
+	log := ctxlogging.Get(ctx)
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	prune := time.NewTicker(max(c.opts.PruneInterval, tick))
+	defer prune.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.CheckDue(ctx); err != nil {
+				log.Error("health check run failed", "err", err)
+			}
+		case <-prune.C:
+			if err := c.repo.PruneHistory(ctx, time.Now().UTC().Add(-c.opts.HistoryRetention)); err != nil {
+				log.Error("health history prune failed", "err", err)
+			}
+		}
+	}
+}
+
+// CheckDue probes one batch of due links, at most Concurrency at a time.
+func (c *Checker) CheckDue(ctx context.Context) error {
+	targets, err := c.repo.Due(ctx, time.Now().UTC(), CLAIM_LEASE, c.opts.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	sem := make(chan struct{}, max(c.opts.Concurrency, 1))
+	wg := sync.WaitGroup{}
+	for _, target := range targets {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			c.check(ctx, target)
+		}()
+	}
+	wg.Wait()
+	c.limiter.forget()
+
+	return nil
+}
+
+func (c *Checker) check(ctx context.Context, target Target) {
+	log := ctxlogging.Get(ctx).With("urlDomain", target.URLDomain, "urlID", target.URLID)
+
+	var result ProbeResult
+	parsed, err := neturl.Parse(target.URL)
+	if err != nil {
+		result = ProbeResult{Err: err}
+	} else if err = c.limiter.Wait(ctx, parsed.Host); err != nil {
+		return
+	} else {
+		result = c.prober.Probe(ctx, target.URL)
+	}
+
+	now := time.Now().UTC()
+	failures := 0
+	next := now.Add(c.opts.Interval)
+	if result.Failed() {
+		failures = target.ConsecutiveFailures + 1
+		next = now.Add(c.backoff(failures))
+	}
+	broken := failures >= max(c.opts.FailureThreshold, 1)
+
+	errText := ""
+	if result.Err != nil {
+		errText = result.Err.Error()
+	}
+
+	check := &Check{
+		URLDomain:  target.URLDomain,
+		URLID:      target.URLID,
+		StatusCode: result.StatusCode,
+		LatencyMs:  result.Latency.Milliseconds(),
+		Error:      errText,
+		Broken:     broken,
+		CheckedAt:  now,
+	}
+	status := &Status{
+		URLDomain:           target.URLDomain,
+		URLID:               target.URLID,
+		StatusCode:          check.StatusCode,
+		LatencyMs:           check.LatencyMs,
+		Error:               errText,
+		Broken:              broken,
+		ConsecutiveFailures: failures,
+		CheckedAt:           now,
+		NextCheckAt:         next,
+	}
+
+	// the alert is published with the status it reports
+	err = c.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := c.repo.Record(ctx, check, status); err != nil {
+			return err
+		}
+
+		if broken != target.Broken {
+			return c.alert(ctx, target, status)
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to record health check", "err", err)
+	}
+}
+
+func (c *Checker) backoff(failures int) time.Duration {
+	delay := c.opts.RetryBackoff
+	for i := 1; i < failures && delay < c.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, c.opts.MaxBackoff)
+}
+
+func (c *Checker) alert(ctx context.Context, target Target, status *Status) error {
+	topic := TOPIC_URL_RECOVERED
+	if status.Broken {
+		topic = TOPIC_URL_BROKEN
+	}
+
+	msg, err := outbox.NewMessage(topic, map[string]any{
+		"url_domain":  target.URLDomain,
+		"url_id":      target.URLID,
+		"url":         target.URL,
+		"status_code": status.StatusCode,
+		"error":       status.Error,
+		"checked_at":  status.CheckedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.outbox.Add(ctx, msg)
+}
@@ -0,0 +1,53 @@
+package health
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Check is a single probe of a link destination kept as history.
+type Check struct {
+	ID         uuid.UUID `db:"id"`
+	URLDomain  string    `db:"url_domain"`
+	URLID      string    `db:"url_id"`
+	StatusCode int       `db:"status_code"`
+	LatencyMs  int64     `db:"latency_ms"`
+	Error      string    `db:"error"`
+	Broken     bool      `db:"broken"`
+	CheckedAt  time.Time `db:"checked_at"`
+}
+
+// Status is the latest known health of a link destination.
+type Status struct {
+	URLDomain           string    `db:"url_domain"`
+	URLID               string    `db:"url_id"`
+	StatusCode          int       `db:"status_code"`
+	LatencyMs           int64     `db:"latency_ms"`
+	Error               string    `db:"error"`
+	Broken              bool      `db:"broken"`
+	ConsecutiveFailures int       `db:"consecutive_failures"`
+	CheckedAt           time.Time `db:"checked_at"`
+	NextCheckAt         time.Time `db:"next_check_at"`
+}
+
+// Target is a link that is due for a check.
+type Target struct {
+	URLDomain           string `db:"domain"`
+	URLID               string `db:"id"`
+	URL                 string `db:"url"`
+	Broken              bool   `db:"broken"`
+	ConsecutiveFailures int    `db:"consecutive_failures"`
+}
+
+type ProbeResult struct {
+	StatusCode int
+	Latency    time.Duration
+	Err        error
+}
+
+// Failed reports whether the destination timed out, was unreachable or
+// answered with 4xx/5xx.
+func (r ProbeResult) Failed() bool {
+	return r.Err != nil || r.StatusCode >= 400
+}
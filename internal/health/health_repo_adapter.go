@@ -0,0 +1,125 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/errormapper"
+	"roadmap.restapi/internal/postgres"
+)
+
+type PostgresRepository struct {
+	db     *sqlx.DB
+	errMap *errormapper.ErrorMapper
+}
+
+func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db:     db,
+		errMap: errormapper.NewErrorMapper(),
+	}
+}
+
+// Due claims up to limit due links for lease. Rows locked or claimed by
+// another instance are skipped, and a claim that is still active is never
+// taken over even if its row was unlocked in the meantime.
+func (r *PostgresRepository) Due(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Target, error) {
+	log := ctxlogging.Get(ctx)
+	targets := []Target{}
+	err := r.db.SelectContext(ctx, &targets, r.db.Rebind(`WITH due AS (
+		SELECT urls.domain, urls.id
+		FROM urls
+		LEFT JOIN url_health h ON h.url_domain = urls.domain AND h.url_id = urls.id
+		WHERE (h.next_check_at IS NULL OR h.next_check_at <= ?)
+		AND NOT EXISTS (
+			SELECT 1 FROM url_health_claims c
+			WHERE c.url_domain = urls.domain AND c.url_id = urls.id AND c.claimed_until > ?
+		)
+		ORDER BY h.next_check_at NULLS FIRST
+		LIMIT ?
+		FOR UPDATE OF urls SKIP LOCKED
+	), claimed AS (
+		INSERT INTO url_health_claims (url_domain, url_id, claimed_until)
+		SELECT domain, id, ? FROM due
+		ON CONFLICT (url_domain, url_id) DO UPDATE
+		SET claimed_until = EXCLUDED.claimed_until
+		WHERE url_health_claims.claimed_until <= ?
+		RETURNING url_domain, url_id
+	)
+	SELECT
+		urls.domain,
+		urls.id,
+		urls.url,
+		COALESCE(h.broken, FALSE) AS broken,
+		COALESCE(h.consecutive_failures, 0) AS consecutive_failures
+	FROM claimed
+	JOIN urls ON urls.domain = claimed.url_domain AND urls.id = claimed.url_id
+	LEFT JOIN url_health h ON h.url_domain = urls.domain AND h.url_id = urls.id
+	ORDER BY h.next_check_at NULLS FIRST`), now, now, limit, now.Add(lease), now)
+
+	if err != nil {
+		return targets, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return targets, nil
+}
+
+func (r *PostgresRepository) Record(ctx context.Context, check *Check, status *Status) error {
+	log := ctxlogging.Get(ctx)
+	db := postgres.Conn(ctx, r.db)
+	_, err := db.NamedExecContext(ctx, `INSERT INTO url_health_checks (url_domain, url_id, status_code, latency_ms, error, broken, checked_at)
+	VALUES (:url_domain, :url_id, :status_code, :latency_ms, :error, :broken, :checked_at)
+	`, check)
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	_, err = db.NamedExecContext(ctx, `INSERT INTO url_health (url_domain, url_id, status_code, latency_ms, error, broken, consecutive_failures, checked_at, next_check_at)
+	VALUES (:url_domain, :url_id, :status_code, :latency_ms, :error, :broken, :consecutive_failures, :checked_at, :next_check_at)
+	ON CONFLICT (url_domain, url_id) DO UPDATE
+	SET status_code = EXCLUDED.status_code,
+		latency_ms = EXCLUDED.latency_ms,
+		error = EXCLUDED.error,
+		broken = EXCLUDED.broken,
+		consecutive_failures = EXCLUDED.consecutive_failures,
+		checked_at = EXCLUDED.checked_at,
+		next_check_at = EXCLUDED.next_check_at
+	`, status)
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	_, err = db.ExecContext(ctx, db.Rebind(`DELETE FROM url_health_claims WHERE url_domain = ? AND url_id = ?`), status.URLDomain, status.URLID)
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) ByUser(ctx context.Context, userID uuid.UUID) ([]Status, error) {
+	log := ctxlogging.Get(ctx)
+	statuses := []Status{}
+	err := r.db.SelectContext(ctx, &statuses, r.db.Rebind(`SELECT h.* FROM url_health h
+	JOIN urls ON urls.domain = h.url_domain AND urls.id = h.url_id
+	WHERE urls.author_id = ?`), userID)
+
+	if err != nil {
+		return statuses, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return statuses, nil
+}
+
+func (r *PostgresRepository) PruneHistory(ctx context.Context, olderThan time.Time) error {
+	log := ctxlogging.Get(ctx)
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(`DELETE FROM url_health_checks WHERE checked_at < ?`), olderThan)
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
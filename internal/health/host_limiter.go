@@ -0,0 +1,61 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// hostLimiter spaces requests to the same host at least gap apart.
+type hostLimiter struct {
+	mu   sync.Mutex
+	gap  time.Duration
+	next map[string]time.Time
+}
+
+func newHostLimiter(gap time.Duration) *hostLimiter {
+	return &hostLimiter{
+		gap:  gap,
+		next: map[string]time.Time{},
+	}
+}
+
+func (l *hostLimiter) Wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[host] = at.Add(l.gap)
+	l.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// forget drops hosts whose slots are in the past so the map does not grow
+// with every destination ever checked.
+func (l *hostLimiter) forget() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for host, at := range l.next {
+		if at.Before(now) {
+			delete(l.next, host)
+		}
+	}
+}
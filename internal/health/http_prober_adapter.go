@@ -0,0 +1,66 @@
+package health
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"roadmap.restapi/internal/safehttp"
+)
+
+const probeBodyLimit = 64 * 1024
+
+type HTTPProber struct {
+	client    *http.Client
+	userAgent string
+}
+
+// NewHTTPProber returns a prober that refuses destinations and redirects
+// pointing to private, loopback or link-local addresses.
+func NewHTTPProber(timeout time.Duration, userAgent string) *HTTPProber {
+	return NewHTTPProberWithClient(safehttp.NewClient(timeout), userAgent)
+}
+
+// NewHTTPProberWithClient returns a prober that sends requests with client.
+// Unlike NewHTTPProber it reaches any address the client allows.
+func NewHTTPProberWithClient(client *http.Client, userAgent string) *HTTPProber {
+	return &HTTPProber{
+		client:    client,
+		userAgent: userAgent,
+	}
+}
+
+// Probe issues a HEAD request and falls back to GET for servers that do not
+// support HEAD.
+func (p *HTTPProber) Probe(ctx context.Context, target string) ProbeResult {
+	start := time.Now()
+	status, err := p.do(ctx, http.MethodHead, target)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = p.do(ctx, http.MethodGet, target)
+	}
+
+	return ProbeResult{
+		StatusCode: status,
+		Latency:    time.Since(start),
+		Err:        err,
+	}
+}
+
+func (p *HTTPProber) do(ctx context.Context, method string, target string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", p.userAgent)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, probeBodyLimit))
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,24 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	// Due claims links that were never checked or whose next check time has
+	// come, oldest first. Claimed links are not returned to other callers
+	// until they are recorded or lease runs out.
+	Due(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Target, error)
+	// Record stores check in the history, replaces the latest status and
+	// releases the claim. Run it in a transaction to do it all at once.
+	Record(ctx context.Context, check *Check, status *Status) error
+	ByUser(ctx context.Context, userID uuid.UUID) ([]Status, error)
+	PruneHistory(ctx context.Context, olderThan time.Time) error
+}
+
+type Prober interface {
+	Probe(ctx context.Context, target string) ProbeResult
+}
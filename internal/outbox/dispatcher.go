@@ -0,0 +1,31 @@
+package outbox
+
+import "context"
+
+// Dispatcher is a Sender that hands every message to the sender registered
+// for its topic, or to the fallback sender.
+type Dispatcher struct {
+	senders  map[string]Sender
+	fallback Sender
+}
+
+func NewDispatcher(fallback Sender) *Dispatcher {
+	return &Dispatcher{
+		senders:  map[string]Sender{},
+		fallback: fallback,
+	}
+}
+
+// Handle registers sender for topic. It is not safe to call once the relay
+// runs.
+func (d *Dispatcher) Handle(topic string, sender Sender) {
+	d.senders[topic] = sender
+}
+
+func (d *Dispatcher) Send(ctx context.Context, msg *Message) error {
+	if sender, ok := d.senders[msg.Topic]; ok {
+		return sender.Send(ctx, msg)
+	}
+
+	return d.fallback.Send(ctx, msg)
+}
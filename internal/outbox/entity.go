@@ -0,0 +1,30 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Message struct {
+	ID          uuid.UUID  `db:"id"`
+	Topic       string     `db:"topic"`
+	Payload     string     `db:"payload"`
+	CreatedAt   time.Time  `db:"created_at"`
+	ProcessedAt *time.Time `db:"processed_at"`
+	// ClaimedUntil is set while a relay sends the message.
+	ClaimedUntil *time.Time `db:"claimed_until"`
+}
+
+func NewMessage(topic string, payload any) (*Message, error) {
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		Topic:   topic,
+		Payload: string(bytes),
+	}, nil
+}
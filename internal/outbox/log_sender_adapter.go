@@ -0,0 +1,26 @@
+package outbox
+
+import (
+	"context"
+
+	"roadmap.restapi/internal/ctxlogging"
+)
+
+// LogSender only writes messages to the log. It is used until a real
+// notification channel is configured.
+type LogSender struct{}
+
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+func (s *LogSender) Send(ctx context.Context, msg *Message) error {
+	ctxlogging.Get(ctx).Info(
+		"outbox message",
+		"id", msg.ID,
+		"topic", msg.Topic,
+		"payload", msg.Payload,
+	)
+
+	return nil
+}
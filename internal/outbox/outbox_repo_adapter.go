@@ -0,0 +1,114 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/errormapper"
+	"roadmap.restapi/internal/postgres"
+)
+
+type PostgresRepository struct {
+	db     *sqlx.DB
+	errMap *errormapper.ErrorMapper
+}
+
+func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db:     db,
+		errMap: errormapper.NewErrorMapper(),
+	}
+}
+
+func (r *PostgresRepository) Add(ctx context.Context, msg *Message) error {
+	log := ctxlogging.Get(ctx)
+	rows, err := sqlx.NamedQueryContext(ctx, postgres.Conn(ctx, r.db), `INSERT INTO outbox_messages (topic, payload)
+	VALUES (:topic, :payload)
+	RETURNING *
+	`, msg)
+
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+	defer rows.Close()
+
+	rows.Next()
+	if err = rows.Err(); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	if err = rows.StructScan(msg); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Message, error) {
+	log := ctxlogging.Get(ctx)
+	db := postgres.Conn(ctx, r.db)
+	msgs := []Message{}
+	err := db.SelectContext(
+		ctx,
+		&msgs,
+		db.Rebind(`WITH claimed AS (
+			UPDATE outbox_messages SET claimed_until = ?
+			WHERE id IN (
+				SELECT id FROM outbox_messages
+				WHERE processed_at IS NULL AND (claimed_until IS NULL OR claimed_until <= ?)
+				ORDER BY created_at
+				LIMIT ?
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT * FROM claimed ORDER BY created_at`),
+		now.Add(lease),
+		now,
+		limit,
+	)
+
+	if err != nil {
+		return msgs, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return msgs, nil
+}
+
+func (r *PostgresRepository) MarkProcessed(ctx context.Context, id uuid.UUID) error {
+	log := ctxlogging.Get(ctx)
+	db := postgres.Conn(ctx, r.db)
+	_, err := db.ExecContext(
+		ctx,
+		db.Rebind(`UPDATE outbox_messages SET processed_at = CURRENT_TIMESTAMP WHERE id = ?`),
+		id,
+	)
+
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) Release(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	log := ctxlogging.Get(ctx)
+	db := postgres.Conn(ctx, r.db)
+	query, args, err := sqlx.In(`UPDATE outbox_messages SET claimed_until = NULL WHERE id IN (?)`, ids)
+	if err != nil {
+		return err
+	}
+
+	if _, err = db.ExecContext(ctx, db.Rebind(query), args...); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	Add(ctx context.Context, msg *Message) error
+	// Claim returns the oldest unprocessed messages in order and claims them
+	// until now+lease. Messages claimed by others are skipped, so concurrent
+	// relays never get the same message, unless the claim lapsed.
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Message, error)
+	MarkProcessed(ctx context.Context, id uuid.UUID) error
+	// Release drops the claims of messages that were not sent, the next
+	// relay picks them up again.
+	Release(ctx context.Context, ids []uuid.UUID) error
+}
+
+// Sender delivers a message to the outside world (email, chat, webhook...).
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
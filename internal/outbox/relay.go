@@ -0,0 +1,84 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/ctxlogging"
+)
+
+// CLAIM_LEASE bounds how long messages claimed by a relay that died wait
+// before another relay sends them.
+const CLAIM_LEASE = 5 * time.Minute
+
+// Relay periodically hands pending messages to Sender. Delivery is
+// at-least-once: a message is marked processed only after it was sent, so
+// senders must tolerate duplicates.
+type Relay struct {
+	repo      Repository
+	sender    Sender
+	batchSize int
+}
+
+func NewRelay(repo Repository, sender Sender, batchSize int) *Relay {
+	return &Relay{
+		repo:      repo,
+		sender:    sender,
+		batchSize: batchSize,
+	}
+}
+
+func (r *Relay) Run(ctx context.Context, interval time.Duration) {
+	log := ctxlogging.Get(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Flush(ctx); err != nil {
+				log.Error("outbox relay flush failed", "err", err)
+			}
+		}
+	}
+}
+
+// Flush sends one batch of pending messages. It stops on the first failed
+// send so messages keep their order. The batch is claimed up front, no
+// transaction is held open while senders talk to the outside world.
+func (r *Relay) Flush(ctx context.Context) error {
+	msgs, err := r.repo.Claim(ctx, time.Now().UTC(), CLAIM_LEASE, r.batchSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range msgs {
+		if err = r.sender.Send(ctx, &msgs[i]); err != nil {
+			return r.release(ctx, msgs[i:], err)
+		}
+
+		if err = r.repo.MarkProcessed(ctx, msgs[i].ID); err != nil {
+			return r.release(ctx, msgs[i+1:], err)
+		}
+	}
+
+	return nil
+}
+
+// release hands the unsent messages back and returns the error that
+// stopped the batch.
+func (r *Relay) release(ctx context.Context, msgs []Message, cause error) error {
+	ids := make([]uuid.UUID, 0, len(msgs))
+	for _, msg := range msgs {
+		ids = append(ids, msg.ID)
+	}
+
+	if err := r.repo.Release(ctx, ids); err != nil {
+		ctxlogging.Get(ctx).Error("failed to release outbox messages", "err", err)
+	}
+
+	return cause
+}
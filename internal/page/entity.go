@@ -0,0 +1,53 @@
+package page
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Theme string
+
+const (
+	THEME_LIGHT Theme = "light"
+	THEME_DARK  Theme = "dark"
+)
+
+// Page is a public link-in-bio page listing links of its author.
+type Page struct {
+	ID          uuid.UUID `db:"id"`
+	Handle      string    `db:"handle"`
+	AuthorID    uuid.UUID `db:"author_id"`
+	Title       string    `db:"title"`
+	Description string    `db:"description"`
+	AvatarURL   string    `db:"avatar_url"`
+	Theme       Theme     `db:"theme"`
+	AccentColor string    `db:"accent_color"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// Link is a short link placed on a page. Title and Icon are page specific,
+// when Title is empty the name of the short link is shown.
+type Link struct {
+	PageID    uuid.UUID `db:"page_id"`
+	URLDomain string    `db:"url_domain"`
+	URLID     string    `db:"url_id"`
+	Position  int       `db:"position"`
+	Title     string    `db:"title"`
+	Icon      string    `db:"icon"`
+
+	// URLName is the name of the linked url, filled when links are read.
+	URLName string `db:"url_name"`
+}
+
+func (l *Link) DisplayTitle() string {
+	if l.Title != "" {
+		return l.Title
+	}
+
+	if l.URLName != "" {
+		return l.URLName
+	}
+
+	return l.URLID
+}
@@ -0,0 +1,13 @@
+package page
+
+import "errors"
+
+var (
+	ErrPageNotFound      = errors.New("page not found")
+	ErrInvalidHandle     = errors.New("handle must be 3-32 characters of latin letters, digits, '_' or '-'")
+	ErrHandleTaken       = errors.New("page handle is already taken")
+	ErrUserIsNotAuthor   = errors.New("this user is not author of page")
+	ErrURLIsNotOwned     = errors.New("only own urls can be placed on a page")
+	ErrLinkNotFound      = errors.New("link is not placed on the page")
+	ErrDuplicateLinkURLs = errors.New("url is placed on the page more than once")
+)
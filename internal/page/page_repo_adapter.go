@@ -0,0 +1,203 @@
+package page
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/database"
+	"roadmap.restapi/internal/errormapper"
+	"roadmap.restapi/internal/postgres"
+)
+
+type PostgresRepository struct {
+	db     *sqlx.DB
+	errMap *errormapper.ErrorMapper
+}
+
+func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db: db,
+		errMap: errormapper.NewErrorMapper(
+			errormapper.NewMapping(database.ErrNotFound, ErrPageNotFound),
+			errormapper.NewMapping(database.ErrUniqueViolation, ErrHandleTaken),
+			errormapper.NewMapping(database.ErrForeignKeyViolation, ErrURLIsNotOwned),
+		),
+	}
+}
+
+func (r *PostgresRepository) Create(ctx context.Context, page *Page) error {
+	log := ctxlogging.Get(ctx)
+	rows, err := r.db.NamedQueryContext(ctx, `INSERT INTO pages (handle, author_id, title, description, avatar_url, theme, accent_color)
+	VALUES (:handle, :author_id, :title, :description, :avatar_url, :theme, :accent_color)
+	RETURNING *
+	`, page)
+
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+	defer rows.Close()
+
+	rows.Next()
+	if err = rows.Err(); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	if err = rows.StructScan(page); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) Update(ctx context.Context, page *Page) error {
+	log := ctxlogging.Get(ctx)
+	rows, err := r.db.NamedQueryContext(ctx, `UPDATE pages
+	SET handle = :handle,
+		title = :title,
+		description = :description,
+		avatar_url = :avatar_url,
+		theme = :theme,
+		accent_color = :accent_color
+	WHERE id = :id
+	RETURNING *
+	`, page)
+
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+		}
+		return ErrPageNotFound
+	}
+
+	if err = rows.StructScan(page); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	log := ctxlogging.Get(ctx)
+	res, err := r.db.ExecContext(ctx, r.db.Rebind(`DELETE FROM pages WHERE id = ?`), id)
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	if rows == 0 {
+		return ErrPageNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) ByID(ctx context.Context, id uuid.UUID) (*Page, error) {
+	log := ctxlogging.Get(ctx)
+	var page Page
+	err := r.db.GetContext(ctx, &page, r.db.Rebind(`SELECT * FROM pages WHERE id = ?`), id)
+	if err != nil {
+		return nil, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return &page, nil
+}
+
+func (r *PostgresRepository) ByHandle(ctx context.Context, handle string) (*Page, error) {
+	log := ctxlogging.Get(ctx)
+	var page Page
+	err := r.db.GetContext(ctx, &page, r.db.Rebind(`SELECT * FROM pages WHERE handle = ?`), handle)
+	if err != nil {
+		return nil, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return &page, nil
+}
+
+func (r *PostgresRepository) ByUser(ctx context.Context, userID uuid.UUID) ([]Page, error) {
+	log := ctxlogging.Get(ctx)
+	pages := []Page{}
+	err := r.db.SelectContext(ctx, &pages, r.db.Rebind(`SELECT * FROM pages WHERE author_id = ? ORDER BY created_at`), userID)
+	if err != nil {
+		return pages, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return pages, nil
+}
+
+func (r *PostgresRepository) Links(ctx context.Context, pageID uuid.UUID) ([]Link, error) {
+	log := ctxlogging.Get(ctx)
+	links := []Link{}
+	err := r.db.SelectContext(ctx, &links, r.db.Rebind(`SELECT
+		page_links.*,
+		COALESCE(urls.name, '') AS url_name
+	FROM page_links
+	JOIN pages ON pages.id = page_links.page_id
+	JOIN urls ON urls.domain = page_links.url_domain AND urls.id = page_links.url_id
+	WHERE page_links.page_id = ? AND urls.author_id = pages.author_id
+	ORDER BY page_links.position`), pageID)
+	if err != nil {
+		return links, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return links, nil
+}
+
+func (r *PostgresRepository) Link(ctx context.Context, pageID uuid.UUID, domain string, urlID string) (*Link, error) {
+	log := ctxlogging.Get(ctx)
+	var link Link
+	err := r.db.GetContext(ctx, &link, r.db.Rebind(`SELECT
+		page_links.*,
+		COALESCE(urls.name, '') AS url_name
+	FROM page_links
+	JOIN urls ON urls.domain = page_links.url_domain AND urls.id = page_links.url_id
+	WHERE page_links.page_id = ? AND page_links.url_domain = ? AND page_links.url_id = ?`), pageID, domain, urlID)
+	if err != nil {
+		err = postgres.TranslateError(err, log)
+		if err == database.ErrNotFound {
+			return nil, ErrLinkNotFound
+		}
+		return nil, r.errMap.MapAndLogUnmatched(err, log)
+	}
+
+	return &link, nil
+}
+
+func (r *PostgresRepository) SetLinks(ctx context.Context, pageID uuid.UUID, links []Link) error {
+	log := ctxlogging.Get(ctx)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, tx.Rebind(`DELETE FROM page_links WHERE page_id = ?`), pageID); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	for i := range links {
+		links[i].PageID = pageID
+		_, err = tx.NamedExecContext(ctx, `INSERT INTO page_links (page_id, url_domain, url_id, position, title, icon)
+		VALUES (:page_id, :url_domain, :url_id, :position, :title, :icon)
+		`, links[i])
+		if err != nil {
+			return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
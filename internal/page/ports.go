@@ -0,0 +1,23 @@
+package page
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	Create(ctx context.Context, page *Page) error
+	Update(ctx context.Context, page *Page) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	ByID(ctx context.Context, id uuid.UUID) (*Page, error)
+	ByHandle(ctx context.Context, handle string) (*Page, error)
+	ByUser(ctx context.Context, userID uuid.UUID) ([]Page, error)
+
+	// Links returns links of the page ordered by position, leaving out urls
+	// that no longer belong to the page author.
+	Links(ctx context.Context, pageID uuid.UUID) ([]Link, error)
+	Link(ctx context.Context, pageID uuid.UUID, domain string, urlID string) (*Link, error)
+	// SetLinks atomically replaces all links of the page.
+	SetLinks(ctx context.Context, pageID uuid.UUID, links []Link) error
+}
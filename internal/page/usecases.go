@@ -0,0 +1,178 @@
+package page
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/url"
+)
+
+var handleRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{2,31}$`)
+
+type UseCases struct {
+	repo Repository
+	urls url.URLRepository
+}
+
+func NewUseCases(repo Repository, urls url.URLRepository) *UseCases {
+	return &UseCases{
+		repo: repo,
+		urls: urls,
+	}
+}
+
+func normalizeHandle(handle string) (string, error) {
+	handle = strings.ToLower(strings.TrimSpace(handle))
+	if !handleRe.MatchString(handle) {
+		return "", ErrInvalidHandle
+	}
+
+	return handle, nil
+}
+
+func (u *UseCases) Create(ctx context.Context, page *Page) error {
+	handle, err := normalizeHandle(page.Handle)
+	if err != nil {
+		return err
+	}
+	page.Handle = handle
+
+	if page.Theme == "" {
+		page.Theme = THEME_LIGHT
+	}
+
+	return u.repo.Create(ctx, page)
+}
+
+func (u *UseCases) Update(ctx context.Context, authorID uuid.UUID, page *Page) error {
+	if _, err := u.authored(ctx, authorID, page.ID); err != nil {
+		return err
+	}
+
+	handle, err := normalizeHandle(page.Handle)
+	if err != nil {
+		return err
+	}
+	page.Handle = handle
+
+	if page.Theme == "" {
+		page.Theme = THEME_LIGHT
+	}
+
+	return u.repo.Update(ctx, page)
+}
+
+func (u *UseCases) Delete(ctx context.Context, authorID uuid.UUID, pageID uuid.UUID) error {
+	if _, err := u.authored(ctx, authorID, pageID); err != nil {
+		return err
+	}
+
+	return u.repo.Delete(ctx, pageID)
+}
+
+func (u *UseCases) ByUser(ctx context.Context, userID uuid.UUID) ([]Page, error) {
+	return u.repo.ByUser(ctx, userID)
+}
+
+func (u *UseCases) Get(ctx context.Context, authorID uuid.UUID, pageID uuid.UUID) (*Page, []Link, error) {
+	page, err := u.authored(ctx, authorID, pageID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	links, err := u.repo.Links(ctx, pageID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return page, links, nil
+}
+
+// SetLinks replaces the links of the page keeping the given order. Only urls
+// of the page author can be placed on the page.
+func (u *UseCases) SetLinks(ctx context.Context, authorID uuid.UUID, pageID uuid.UUID, links []Link) ([]Link, error) {
+	if _, err := u.authored(ctx, authorID, pageID); err != nil {
+		return nil, err
+	}
+
+	seen := map[[2]string]bool{}
+	for i := range links {
+		key := [2]string{links[i].URLDomain, links[i].URLID}
+		if seen[key] {
+			return nil, ErrDuplicateLinkURLs
+		}
+		seen[key] = true
+
+		linked, err := u.urls.ByID(ctx, links[i].URLDomain, links[i].URLID)
+		if err != nil {
+			return nil, err
+		}
+
+		if linked.AuthorID != authorID {
+			return nil, ErrURLIsNotOwned
+		}
+
+		links[i].Position = i
+	}
+
+	if err := u.repo.SetLinks(ctx, pageID, links); err != nil {
+		return nil, err
+	}
+
+	return u.repo.Links(ctx, pageID)
+}
+
+// Public returns a page with its links for public rendering. Links to urls
+// the page author no longer owns are left out by the repository.
+func (u *UseCases) Public(ctx context.Context, handle string) (*Page, []Link, error) {
+	page, err := u.repo.ByHandle(ctx, strings.ToLower(handle))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	links, err := u.repo.Links(ctx, page.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return page, links, nil
+}
+
+// PublicLink resolves a url that is placed on the page with handle. A url
+// transferred to another user since it was placed is no longer served.
+func (u *UseCases) PublicLink(ctx context.Context, handle string, domain string, urlID string) (*url.URL, error) {
+	page, err := u.repo.ByHandle(ctx, strings.ToLower(handle))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = u.repo.Link(ctx, page.ID, domain, urlID); err != nil {
+		return nil, err
+	}
+
+	linked, err := u.urls.ByID(ctx, domain, urlID)
+	if err != nil {
+		return nil, err
+	}
+
+	if linked.AuthorID != page.AuthorID {
+		return nil, ErrLinkNotFound
+	}
+
+	return linked, nil
+}
+
+func (u *UseCases) authored(ctx context.Context, authorID uuid.UUID, pageID uuid.UUID) (*Page, error) {
+	page, err := u.repo.ByID(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.AuthorID != authorID {
+		return nil, ErrUserIsNotAuthor
+	}
+
+	return page, nil
+}
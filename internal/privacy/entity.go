@@ -0,0 +1,36 @@
+package privacy
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Settings are the analytics privacy choices of an account.
+type Settings struct {
+	UserID uuid.UUID `db:"user_id"`
+	// CaptureReferer controls whether referers of clicks on the links of the
+	// account are recorded.
+	CaptureReferer bool      `db:"capture_referer"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+// DefaultSettings apply to accounts that never changed their settings.
+func DefaultSettings(userID uuid.UUID) *Settings {
+	return &Settings{
+		UserID:         userID,
+		CaptureReferer: true,
+	}
+}
+
+// Policy is the deployment wide privacy configuration.
+type Policy struct {
+	// IPv4PrefixBits and IPv6PrefixBits are kept of client addresses, the
+	// rest is zeroed.
+	IPv4PrefixBits int
+	IPv6PrefixBits int
+	// HonorDNT and HonorGPC make visitors sending DNT: 1 or Sec-GPC: 1 count
+	// anonymously.
+	HonorDNT bool
+	HonorGPC bool
+}
@@ -0,0 +1,7 @@
+package privacy
+
+import "errors"
+
+var (
+	ErrSettingsNotFound = errors.New("privacy settings not found")
+)
@@ -0,0 +1,13 @@
+package privacy
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type SettingsRepository interface {
+	// Save creates or replaces the settings of the user.
+	Save(ctx context.Context, settings *Settings) error
+	Get(ctx context.Context, userID uuid.UUID) (*Settings, error)
+}
@@ -0,0 +1,64 @@
+package privacy
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/database"
+	"roadmap.restapi/internal/errormapper"
+	"roadmap.restapi/internal/postgres"
+)
+
+type PostgresSettingsRepository struct {
+	db     *sqlx.DB
+	errMap *errormapper.ErrorMapper
+}
+
+func NewPostgresSettingsRepository(db *sqlx.DB) *PostgresSettingsRepository {
+	return &PostgresSettingsRepository{
+		db: db,
+		errMap: errormapper.NewErrorMapper(
+			errormapper.NewMapping(database.ErrNotFound, ErrSettingsNotFound),
+		),
+	}
+}
+
+func (r *PostgresSettingsRepository) Save(ctx context.Context, settings *Settings) error {
+	log := ctxlogging.Get(ctx)
+	rows, err := r.db.NamedQueryContext(ctx, `INSERT INTO privacy_settings (user_id, capture_referer)
+	VALUES (:user_id, :capture_referer)
+	ON CONFLICT (user_id) DO UPDATE
+	SET capture_referer = EXCLUDED.capture_referer,
+		updated_at = CURRENT_TIMESTAMP
+	RETURNING *
+	`, settings)
+
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+	defer rows.Close()
+
+	rows.Next()
+	if err = rows.Err(); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	if err = rows.StructScan(settings); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
+
+func (r *PostgresSettingsRepository) Get(ctx context.Context, userID uuid.UUID) (*Settings, error) {
+	log := ctxlogging.Get(ctx)
+	var settings Settings
+	err := r.db.GetContext(ctx, &settings, r.db.Rebind(`SELECT * FROM privacy_settings WHERE user_id = ?`), userID)
+	if err != nil {
+		return nil, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return &settings, nil
+}
@@ -0,0 +1,79 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"strings"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/ctxlogging"
+)
+
+type UseCases struct {
+	repo   SettingsRepository
+	policy Policy
+}
+
+func NewUseCases(repo SettingsRepository, policy Policy) *UseCases {
+	return &UseCases{
+		repo:   repo,
+		policy: policy,
+	}
+}
+
+// AnonymizeIP zeroes the host part of ip so it no longer identifies a
+// single client. Invalid addresses are dropped entirely.
+func (u *UseCases) AnonymizeIP(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap().WithZone("")
+
+	bits := u.policy.IPv6PrefixBits
+	if addr.Is4() {
+		bits = u.policy.IPv4PrefixBits
+	}
+
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return ""
+	}
+
+	return prefix.Addr().String()
+}
+
+// RefusesTracking reports whether the DNT and Sec-GPC header values of a
+// visitor ask not to be tracked.
+func (u *UseCases) RefusesTracking(dnt string, gpc string) bool {
+	return (u.policy.HonorDNT && strings.TrimSpace(dnt) == "1") ||
+		(u.policy.HonorGPC && strings.TrimSpace(gpc) == "1")
+}
+
+// Settings returns the settings of the user, the defaults if they were never
+// changed.
+func (u *UseCases) Settings(ctx context.Context, userID uuid.UUID) (*Settings, error) {
+	settings, err := u.repo.Get(ctx, userID)
+	if errors.Is(err, ErrSettingsNotFound) {
+		return DefaultSettings(userID), nil
+	}
+
+	return settings, err
+}
+
+func (u *UseCases) Save(ctx context.Context, settings *Settings) error {
+	return u.repo.Save(ctx, settings)
+}
+
+// CaptureReferer tells whether referers may be recorded for the links of the
+// user. When the settings can't be read it errs on the side of privacy.
+func (u *UseCases) CaptureReferer(ctx context.Context, userID uuid.UUID) bool {
+	settings, err := u.Settings(ctx, userID)
+	if err != nil {
+		ctxlogging.Get(ctx).Warn("failed to read privacy settings", "err", err, "user_id", userID)
+		return false
+	}
+
+	return settings.CaptureReferer
+}
@@ -0,0 +1,117 @@
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	neturl "net/url"
+	"syscall"
+	"time"
+)
+
+const MAX_REDIRECTS = 10
+
+var (
+	ErrForbiddenAddress = errors.New("destination address is not public")
+	ErrForbiddenScheme  = errors.New("destination scheme is not http or https")
+	ErrTooManyRedirects = fmt.Errorf("stopped after %d redirects", MAX_REDIRECTS)
+)
+
+// reservedPrefixes are ranges that are not private in the strict sense but
+// still do not belong to the public internet.
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// IsPublic reports whether addr is a public unicast address. Private,
+// loopback, link-local, multicast and reserved ranges are not public.
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewClient returns a client for requests to user supplied destinations. Every
+// connection is checked after DNS resolution, so host names pointing to
+// internal addresses are refused as well, and so is every redirect hop.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: control,
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// a proxy would make the dialer check the proxy address only
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   timeout,
+			ExpectContinueTimeout: time.Second,
+		},
+		CheckRedirect: CheckRedirect,
+	}
+}
+
+// CheckURL rejects destinations that are not http(s) or whose host is an
+// address literal outside of the public internet.
+func CheckURL(u *neturl.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ErrForbiddenScheme
+	}
+
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil && !IsPublic(addr) {
+		return ErrForbiddenAddress
+	}
+
+	return nil
+}
+
+// CheckRedirect is an http.Client redirect policy that applies CheckURL to
+// every hop.
+func CheckRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= MAX_REDIRECTS {
+		return ErrTooManyRedirects
+	}
+
+	return CheckURL(req.URL)
+}
+
+// control runs right before a connection is made, once the address has been
+// resolved.
+func control(network string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+
+	if !IsPublic(addrPort.Addr()) {
+		return ErrForbiddenAddress
+	}
+
+	return nil
+}
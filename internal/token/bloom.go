@@ -0,0 +1,60 @@
+package token
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+const bloomFalsePositiveRate = 0.01
+
+// bloomFilter tells ids that are surely not in a set from those that might be.
+// It is not safe for concurrent use.
+type bloomFilter struct {
+	bits   []uint64
+	hashes uint64
+}
+
+// newBloomFilter sizes a filter to keep the false positive rate for up to
+// capacity ids.
+func newBloomFilter(capacity int) *bloomFilter {
+	n := math.Max(float64(capacity), 1)
+	m := math.Ceil(-n * math.Log(bloomFalsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := math.Max(math.Round(m/n*math.Ln2), 1)
+
+	return &bloomFilter{
+		bits:   make([]uint64, (uint64(m)+63)/64),
+		hashes: uint64(k),
+	}
+}
+
+func (f *bloomFilter) add(data []byte) {
+	h1, h2 := bloomHashes(data)
+	size := uint64(len(f.bits)) * 64
+	for i := uint64(0); i < f.hashes; i++ {
+		bit := (h1 + i*h2) % size
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+func (f *bloomFilter) mayContain(data []byte) bool {
+	h1, h2 := bloomHashes(data)
+	size := uint64(len(f.bits)) * 64
+	for i := uint64(0); i < f.hashes; i++ {
+		bit := (h1 + i*h2) % size
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// bloomHashes derives the two hashes the bit positions are combined from.
+func bloomHashes(data []byte) (uint64, uint64) {
+	a := fnv.New64a()
+	a.Write(data)
+	b := fnv.New64()
+	b.Write(data)
+
+	return a.Sum64(), b.Sum64() | 1
+}
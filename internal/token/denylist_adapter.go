@@ -0,0 +1,168 @@
+package token
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"roadmap.restapi/internal/ctxlogging"
+)
+
+const (
+	// denyKey is a sorted set of the denied ids scored by the unix
+	// milliseconds they are denied until
+	denyKey     = "token-denied"
+	denyChannel = "token-deny"
+)
+
+// RedisDenylist keeps revoked token ids in Redis until the tokens expire. A
+// bloom filter of the denied ids in memory answers most lookups without a
+// round trip, only possible hits are checked in Redis. The filters of all
+// instances learn about new ids over pub/sub and are rebuilt from Redis
+// periodically, dropping expired ids and anything a lost message missed.
+type RedisDenylist struct {
+	client   *redis.Client
+	capacity int
+
+	mu sync.RWMutex
+	// filter is nil until first loaded, sending every lookup to Redis
+	filter *bloomFilter
+	// next is the filter being rebuilt, it receives new ids too
+	next *bloomFilter
+}
+
+func NewRedisDenylist(client *redis.Client, capacity int) *RedisDenylist {
+	return &RedisDenylist{
+		client:   client,
+		capacity: capacity,
+	}
+}
+
+// Run keeps the filter in sync until ctx is done.
+func (d *RedisDenylist) Run(ctx context.Context, rebuildInterval time.Duration) {
+	log := ctxlogging.Get(ctx)
+
+	sub := d.client.Subscribe(ctx, denyChannel)
+	defer sub.Close()
+	// subscribe before loading so no id denied meanwhile is missed
+	if _, err := sub.Receive(ctx); err != nil {
+		log.Error("token denylist subscription failed", "err", err)
+	}
+
+	if err := d.Rebuild(ctx); err != nil {
+		log.Error("token denylist load failed", "err", err)
+	}
+
+	ticker := time.NewTicker(rebuildInterval)
+	defer ticker.Stop()
+
+	msgs := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-msgs:
+			if !ok {
+				return
+			}
+			d.remember(msg.Payload)
+		case <-ticker.C:
+			if err := d.Rebuild(ctx); err != nil {
+				log.Error("token denylist rebuild failed", "err", err)
+			}
+		}
+	}
+}
+
+// Rebuild drops expired ids from Redis and loads the filter from the ones
+// still denied.
+func (d *RedisDenylist) Rebuild(ctx context.Context) error {
+	d.mu.Lock()
+	d.next = newBloomFilter(d.capacity)
+	d.mu.Unlock()
+
+	var ids []string
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	err := d.client.ZRemRangeByScore(ctx, denyKey, "-inf", now).Err()
+	if err == nil {
+		ids, err = d.client.ZRangeByScore(ctx, denyKey, &redis.ZRangeBy{Min: "(" + now, Max: "+inf"}).Result()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err != nil {
+		d.next = nil
+		return err
+	}
+
+	for _, id := range ids {
+		d.next.add([]byte(id))
+	}
+	d.filter, d.next = d.next, nil
+
+	return nil
+}
+
+// Deny revokes the token id until expiresAt, ids of expired tokens are
+// skipped. An id denied twice stays denied until the later expiry.
+func (d *RedisDenylist) Deny(ctx context.Context, id uuid.UUID, expiresAt time.Time) error {
+	if !expiresAt.After(time.Now()) {
+		return nil
+	}
+
+	err := d.client.ZAddGT(ctx, denyKey, redis.Z{Score: float64(expiresAt.UnixMilli()), Member: id.String()}).Err()
+	if err != nil {
+		return err
+	}
+
+	d.remember(id.String())
+
+	return d.client.Publish(ctx, denyChannel, id.String()).Err()
+}
+
+// Denied reports whether any of the ids is revoked. Expired ids left in the
+// set until the next rebuild are not.
+func (d *RedisDenylist) Denied(ctx context.Context, ids ...uuid.UUID) (bool, error) {
+	members := make([]string, 0, len(ids))
+
+	d.mu.RLock()
+	for _, id := range ids {
+		if d.filter == nil || d.filter.mayContain([]byte(id.String())) {
+			members = append(members, id.String())
+		}
+	}
+	d.mu.RUnlock()
+
+	if len(members) == 0 {
+		return false, nil
+	}
+
+	scores, err := d.client.ZMScore(ctx, denyKey, members...).Result()
+	if err != nil {
+		return false, err
+	}
+
+	now := float64(time.Now().UnixMilli())
+	for _, score := range scores {
+		if score > now {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (d *RedisDenylist) remember(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.filter != nil {
+		d.filter.add([]byte(id))
+	}
+	if d.next != nil {
+		d.next.add([]byte(id))
+	}
+}
@@ -0,0 +1,44 @@
+package token
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// DenylistClaimsExtractor refuses tokens revoked before they expired, either
+// on their own or with their session.
+type DenylistClaimsExtractor struct {
+	extractor ClaimsExtractor
+	denylist  Denylist
+}
+
+func NewDenylistClaimsExtractor(extractor ClaimsExtractor, denylist Denylist) *DenylistClaimsExtractor {
+	return &DenylistClaimsExtractor{
+		extractor: extractor,
+		denylist:  denylist,
+	}
+}
+
+func (e *DenylistClaimsExtractor) ParseAndValidate(ctx context.Context, token string) (*TokenClaims, error) {
+	claims, err := e.extractor.ParseAndValidate(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []uuid.UUID{claims.ID}
+	if claims.SID != uuid.Nil {
+		ids = append(ids, claims.SID)
+	}
+
+	denied, err := e.denylist.Denied(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	if denied {
+		return nil, ErrTokenRevoked
+	}
+
+	return claims, nil
+}
@@ -1,6 +1,8 @@
 package token
 
 import (
+	"time"
+
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 )
@@ -14,6 +16,8 @@ const (
 
 type UserClaims struct {
 	UID uuid.UUID `json:"uid"`
+	// SID is the session the token was issued for.
+	SID uuid.UUID `json:"sid,omitempty"`
 }
 
 type TokenClaims struct {
@@ -27,3 +31,20 @@ type TokenPair struct {
 	Access  string `json:"access"`
 	Refresh string `json:"refresh"`
 }
+
+// Session is a login of a user on one device. It lives as long as its
+// refresh token family, whose id it shares.
+type Session struct {
+	ID              uuid.UUID `json:"id"`
+	UserID          uuid.UUID `json:"user_id"`
+	CreatedAt       time.Time `json:"created_at"`
+	LastRefreshedAt time.Time `json:"last_refreshed_at"`
+	IP              string    `json:"ip"`
+	UserAgent       string    `json:"user_agent"`
+}
+
+// SessionClient describes the device a session is used from.
+type SessionClient struct {
+	IP        string
+	UserAgent string
+}
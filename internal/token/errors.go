@@ -14,6 +14,18 @@ var (
 	ErrTokenInvalid             = errors.New("token invalid")
 	ErrTokenSignatureInvalid    = errors.New("token signature is invalid")
 	ErrRefreshNotWhitelisted    = errors.New("refresh token is not in whitelist")
+	ErrRefreshReused            = errors.New("refresh token was already used")
+	ErrSessionNotFound          = errors.New("session not found")
+	ErrTokenRevoked             = errors.New("token revoked")
+
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	ErrMissingKey           = errors.New("signing key missing")
+	ErrInvalidKey           = errors.New("invalid signing key")
+	ErrKeyMismatch          = errors.New("signing key does not fit the algorithm")
+	ErrNoPublicKey          = errors.New("signing key has no public key")
+	ErrDuplicateKeyID       = errors.New("signing key id is not unique")
+	ErrNoActiveKey          = errors.New("no signing key is active")
+	ErrUnknownKey           = errors.New("token signing key is unknown or retired")
 )
 
 var jwtErrMapper = errormapper.NewErrorMapper(
@@ -2,27 +2,37 @@ package token
 
 import (
 	"context"
+	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
 type JWTClaimsExtractor struct {
-	pubKey        any
-	signingMethod jwt.SigningMethod
+	keys *KeyRing
 }
 
 func NewJWTClaimsExtractor(pubKey any, signingMethod jwt.SigningMethod) *JWTClaimsExtractor {
-	return &JWTClaimsExtractor{
-		pubKey:        pubKey,
-		signingMethod: signingMethod,
-	}
+	return NewJWTClaimsExtractorWithKeys(SingleKeyRing(&SigningKey{Method: signingMethod, Public: pubKey}))
+}
+
+// NewJWTClaimsExtractorWithKeys creates an extractor verifying tokens with
+// the key of keys their kid header names.
+func NewJWTClaimsExtractorWithKeys(keys *KeyRing) *JWTClaimsExtractor {
+	return &JWTClaimsExtractor{keys: keys}
 }
 
 func (e *JWTClaimsExtractor) ParseAndValidate(ctx context.Context, token string) (*TokenClaims, error) {
 	tokenClaims := &TokenClaims{}
+	// the key decides the algorithm, a token must not pick how the key is
+	// used
 	parsedToken, err := jwt.ParseWithClaims(token, tokenClaims, func(t *jwt.Token) (any, error) {
-		return e.pubKey, nil
-	})
+		kid, _ := t.Header["kid"].(string)
+		key, err := e.keys.Verification(kid, t.Method.Alg(), time.Now())
+		if err != nil {
+			return nil, err
+		}
+		return key.Public, nil
+	}, jwt.WithValidMethods(e.keys.Algorithms()))
 
 	if err != nil {
 		return nil, jwtErrMapper.Map(err)
@@ -10,11 +10,10 @@ import (
 )
 
 type JWTGenerator struct {
-	secretKey     any
-	signingMethod jwt.SigningMethod
-	accessExp     time.Duration
-	refreshExp    time.Duration
-	errMap        *errormapper.ErrorMapper
+	keys       *KeyRing
+	accessExp  time.Duration
+	refreshExp time.Duration
+	errMap     *errormapper.ErrorMapper
 }
 
 func NewJWTGenerator(
@@ -22,12 +21,26 @@ func NewJWTGenerator(
 	signingMethod jwt.SigningMethod,
 	accessExpirationTime time.Duration,
 	refreshExpirationTime time.Duration,
+) *JWTGenerator {
+	return NewJWTGeneratorWithKeys(
+		SingleKeyRing(&SigningKey{Method: signingMethod, Private: secret}),
+		accessExpirationTime,
+		refreshExpirationTime,
+	)
+}
+
+// NewJWTGeneratorWithKeys creates a generator signing with the active key of
+// keys. Tokens name the key in their kid header so verifiers can pick it
+// from the JWKS.
+func NewJWTGeneratorWithKeys(
+	keys *KeyRing,
+	accessExpirationTime time.Duration,
+	refreshExpirationTime time.Duration,
 ) *JWTGenerator {
 	return &JWTGenerator{
-		secretKey:     secret,
-		signingMethod: signingMethod,
-		accessExp:     accessExpirationTime,
-		refreshExp:    refreshExpirationTime,
+		keys:       keys,
+		accessExp:  accessExpirationTime,
+		refreshExp: refreshExpirationTime,
 	}
 }
 
@@ -52,15 +65,31 @@ func (g *JWTGenerator) Generate(
 		},
 	}
 
+	key, err := g.keys.Signing(time.Now())
+	if err != nil {
+		return "", err
+	}
+
 	token := jwt.NewWithClaims(
-		g.signingMethod,
+		key.Method,
 		claims,
 	)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
 
-	tokenString, err := token.SignedString(g.secretKey)
+	tokenString, err := token.SignedString(key.Private)
 	if err != nil {
 		return "", jwtErrMapper.Map(err)
 	}
 
 	return tokenString, nil
 }
+
+func (g *JWTGenerator) TTL(tokenType TokenType) time.Duration {
+	if tokenType == REFRESH {
+		return g.refreshExp
+	}
+
+	return g.accessExp
+}
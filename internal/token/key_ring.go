@@ -0,0 +1,121 @@
+package token
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RingKey is a key of a KeyRing, signing from ActiveFrom on until the next
+// key becomes active.
+type RingKey struct {
+	Key        *SigningKey
+	ActiveFrom time.Time
+}
+
+// KeyRing holds the keys tokens are signed and verified with, so keys can
+// rotate without invalidating issued tokens. Keys are published and
+// accepted before they become active, so verifiers know them in time, and
+// stay accepted for a grace period after the next key took over.
+type KeyRing struct {
+	// keys ordered by ActiveFrom
+	keys  []RingKey
+	grace time.Duration
+}
+
+// NewKeyRing creates a ring of keys with distinct IDs. A key without ID
+// verifies tokens without kid header, like the ones issued before keys
+// rotated.
+func NewKeyRing(keys []RingKey, grace time.Duration) (*KeyRing, error) {
+	if len(keys) == 0 {
+		return nil, ErrMissingKey
+	}
+
+	ids := map[string]bool{}
+	for _, k := range keys {
+		if ids[k.Key.ID] {
+			return nil, fmt.Errorf("%w: %q", ErrDuplicateKeyID, k.Key.ID)
+		}
+		ids[k.Key.ID] = true
+	}
+
+	sorted := append([]RingKey{}, keys...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ActiveFrom.Before(sorted[j].ActiveFrom) })
+
+	return &KeyRing{keys: sorted, grace: grace}, nil
+}
+
+// SingleKeyRing is a ring of key only, active forever.
+func SingleKeyRing(key *SigningKey) *KeyRing {
+	return &KeyRing{keys: []RingKey{{Key: key}}}
+}
+
+// Signing returns the key active at now.
+func (r *KeyRing) Signing(now time.Time) (*SigningKey, error) {
+	for i := len(r.keys) - 1; i >= 0; i-- {
+		if !r.keys[i].ActiveFrom.After(now) {
+			return r.keys[i].Key, nil
+		}
+	}
+
+	return nil, ErrNoActiveKey
+}
+
+// Verification returns the key with id if it is accepted at now and signs
+// with algorithm.
+func (r *KeyRing) Verification(id string, algorithm string, now time.Time) (*SigningKey, error) {
+	for _, key := range r.verifiable(now) {
+		if key.ID != id {
+			continue
+		}
+		if key.Method.Alg() != algorithm {
+			return nil, fmt.Errorf("%w: key %q signs with %s, not %s", ErrKeyMismatch, id, key.Method.Alg(), algorithm)
+		}
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+}
+
+// Algorithms lists the algorithms of all keys of the ring.
+func (r *KeyRing) Algorithms() []string {
+	seen := map[string]bool{}
+	algorithms := []string{}
+	for _, k := range r.keys {
+		if alg := k.Key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			algorithms = append(algorithms, alg)
+		}
+	}
+
+	return algorithms
+}
+
+// JWKS returns the public keys accepted at now.
+func (r *KeyRing) JWKS(now time.Time) JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range r.verifiable(now) {
+		if jwk, err := key.JWK(); err == nil {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+
+	return jwks
+}
+
+// verifiable returns the keys accepted at now: the upcoming ones, the active
+// one and the ones retired less than the grace period ago.
+func (r *KeyRing) verifiable(now time.Time) []*SigningKey {
+	keys := []*SigningKey{}
+	for i, k := range r.keys {
+		if i+1 < len(r.keys) {
+			retiredAt := r.keys[i+1].ActiveFrom
+			if !now.Before(retiredAt.Add(r.grace)) {
+				continue
+			}
+		}
+		keys = append(keys, k.Key)
+	}
+
+	return keys
+}
@@ -2,6 +2,7 @@ package token
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -12,10 +13,44 @@ type ClaimsExtractor interface {
 
 type Generator interface {
 	Generate(ctx context.Context, claims *UserClaims, tokenType TokenType, tokenID uuid.UUID) (string, error)
+	// TTL is the lifetime of generated tokens of the type.
+	TTL(tokenType TokenType) time.Duration
 }
 
+// WhitelistRepository keeps the refresh tokens that may still be used. Tokens
+// are grouped into families, one per login, each rotation adding to the
+// family of the token it replaces.
 type WhitelistRepository interface {
-	Add(ctx context.Context, id uuid.UUID) error
+	// Add whitelists the token in family. It returns ErrTokenRevoked if the
+	// family was revoked.
+	Add(ctx context.Context, id uuid.UUID, family uuid.UUID) error
 	Exists(ctx context.Context, id uuid.UUID) (bool, error)
+	// Consume removes a whitelisted token and remembers it as used, returning
+	// its family. ok is false if the token was not whitelisted.
+	Consume(ctx context.Context, id uuid.UUID) (family uuid.UUID, ok bool, err error)
+	// UsedFamily returns the family of a token that was already consumed.
+	UsedFamily(ctx context.Context, id uuid.UUID) (family uuid.UUID, ok bool, err error)
+	// RevokeFamily removes the tokens of the family, no token can be added
+	// to it afterwards.
+	RevokeFamily(ctx context.Context, family uuid.UUID) error
 	Remove(ctx context.Context, id uuid.UUID) error
 }
+
+// SessionRepository keeps the sessions of users until their refresh tokens
+// expire.
+type SessionRepository interface {
+	Save(ctx context.Context, session *Session) error
+	// Update stores an existing session. It returns ErrSessionNotFound if the
+	// session was deleted.
+	Update(ctx context.Context, session *Session) error
+	Get(ctx context.Context, id uuid.UUID) (*Session, error)
+	ByUser(ctx context.Context, userID uuid.UUID) ([]Session, error)
+	Delete(ctx context.Context, userID uuid.UUID, id uuid.UUID) error
+}
+
+// Denylist keeps revoked access tokens, by their own or their session id,
+// until they expire.
+type Denylist interface {
+	Deny(ctx context.Context, id uuid.UUID, expiresAt time.Time) error
+	Denied(ctx context.Context, ids ...uuid.UUID) (bool, error)
+}
@@ -0,0 +1,149 @@
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	sessionKeyPrefix      = "token-sess:"
+	userSessionsKeyPrefix = "token-user-sess:"
+)
+
+// updateScript stores a session only while it exists, a session deleted
+// meanwhile stays deleted.
+var updateScript = redis.NewScript(`
+if not redis.call('SET', KEYS[1], ARGV[1], 'XX', 'PX', ARGV[2]) then
+	return 0
+end
+redis.call('SADD', KEYS[2], ARGV[3])
+redis.call('PEXPIRE', KEYS[2], ARGV[2])
+return 1
+`)
+
+type RedisSessionRepository struct {
+	client  *redis.Client
+	expTime time.Duration
+}
+
+func NewRedisSessionRepository(client *redis.Client, sessionExpirationTime time.Duration) *RedisSessionRepository {
+	return &RedisSessionRepository{
+		client:  client,
+		expTime: sessionExpirationTime,
+	}
+}
+
+// Save stores the session and prolongs it, together with the index of the
+// sessions of its user, by the refresh token lifetime.
+func (r *RedisSessionRepository) Save(ctx context.Context, session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	userKey := userSessionsKeyPrefix + session.UserID.String()
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetEx(ctx, sessionKeyPrefix+session.ID.String(), data, r.expTime)
+		pipe.SAdd(ctx, userKey, session.ID.String())
+		pipe.Expire(ctx, userKey, r.expTime)
+		return nil
+	})
+
+	return err
+}
+
+// Update stores the session like Save, unless it was deleted.
+func (r *RedisSessionRepository) Update(ctx context.Context, session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{sessionKeyPrefix + session.ID.String(), userSessionsKeyPrefix + session.UserID.String()}
+	updated, err := updateScript.Run(ctx, r.client, keys, data, r.expTime.Milliseconds(), session.ID.String()).Int()
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (r *RedisSessionRepository) Get(ctx context.Context, id uuid.UUID) (*Session, error) {
+	data, err := r.client.Get(ctx, sessionKeyPrefix+id.String()).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session Session
+	if err = json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// ByUser returns the sessions of the user, dropping ids of expired sessions
+// from the index on the way.
+func (r *RedisSessionRepository) ByUser(ctx context.Context, userID uuid.UUID) ([]Session, error) {
+	userKey := userSessionsKeyPrefix + userID.String()
+	ids, err := r.client.SMembers(ctx, userKey).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = sessionKeyPrefix + id
+	}
+
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(values))
+	var expired []any
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			expired = append(expired, ids[i])
+			continue
+		}
+
+		var session Session
+		if err = json.Unmarshal([]byte(data), &session); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	if len(expired) > 0 {
+		if err = r.client.SRem(ctx, userKey, expired...).Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return sessions, nil
+}
+
+func (r *RedisSessionRepository) Delete(ctx context.Context, userID uuid.UUID, id uuid.UUID) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, sessionKeyPrefix+id.String())
+		pipe.SRem(ctx, userSessionsKeyPrefix+userID.String(), id.String())
+		return nil
+	})
+
+	return err
+}
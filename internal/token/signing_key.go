@@ -0,0 +1,145 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// MIN_RSA_BITS is the smallest RSA key accepted for signing.
+const MIN_RSA_BITS = 2048
+
+// supportedAlgorithms are the JWS algorithms tokens can be signed with.
+var supportedAlgorithms = map[string]bool{
+	"HS256": true, "HS384": true, "HS512": true,
+	"RS256": true, "RS384": true, "RS512": true,
+	"PS256": true, "PS384": true, "PS512": true,
+	"ES256": true, "ES384": true, "ES512": true,
+	"EdDSA": true,
+}
+
+// SigningKey is the key tokens are signed with together with the key they
+// are verified with. Both are the same secret for HMAC algorithms.
+type SigningKey struct {
+	Method jwt.SigningMethod
+	// ID is the RFC 7638 thumbprint of public keys, empty for secrets.
+	ID      string
+	Private any
+	Public  any
+}
+
+// LoadSigningKey prepares the key of algorithm: secret for HMAC algorithms,
+// the PEM encoded private key in privateKeyFile for the asymmetric ones.
+func LoadSigningKey(algorithm string, secret string, privateKeyFile string) (*SigningKey, error) {
+	if !supportedAlgorithms[algorithm] {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, algorithm)
+	}
+	method := jwt.GetSigningMethod(algorithm)
+
+	if _, ok := method.(*jwt.SigningMethodHMAC); ok {
+		if secret == "" {
+			return nil, fmt.Errorf("%w: %s needs a secret key", ErrMissingKey, algorithm)
+		}
+		return &SigningKey{Method: method, Private: []byte(secret), Public: []byte(secret)}, nil
+	}
+
+	if privateKeyFile == "" {
+		return nil, fmt.Errorf("%w: %s needs a private key file", ErrMissingKey, algorithm)
+	}
+	data, err := os.ReadFile(privateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSigningKey(method, data)
+}
+
+// NewSigningKey creates the key of method from a PEM encoded PKCS #8, PKCS #1
+// or SEC 1 private key.
+func NewSigningKey(method jwt.SigningMethod, privatePEM []byte) (*SigningKey, error) {
+	private, err := parsePrivateKey(privatePEM)
+	if err != nil {
+		return nil, err
+	}
+
+	public, err := publicKey(method, private)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{Method: method, Private: private, Public: public}
+	jwk, err := key.JWK()
+	if err != nil {
+		return nil, err
+	}
+	key.ID = jwk.Thumbprint()
+
+	return key, nil
+}
+
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM block found", ErrInvalidKey)
+	}
+
+	var private any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		private, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%w: unexpected PEM block %q", ErrInvalidKey, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err.Error())
+	}
+
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported key type %T", ErrInvalidKey, private)
+	}
+
+	return signer, nil
+}
+
+// publicKey returns the public key of private after checking it fits
+// method.
+func publicKey(method jwt.SigningMethod, private crypto.Signer) (any, error) {
+	switch m := method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		key, ok := private.(*rsa.PrivateKey)
+		if !ok {
+			break
+		}
+		if key.N.BitLen() < MIN_RSA_BITS {
+			return nil, fmt.Errorf("%w: RSA keys need at least %d bits", ErrInvalidKey, MIN_RSA_BITS)
+		}
+		return &key.PublicKey, nil
+	case *jwt.SigningMethodECDSA:
+		key, ok := private.(*ecdsa.PrivateKey)
+		if !ok || key.Curve.Params().BitSize != m.CurveBits {
+			break
+		}
+		return &key.PublicKey, nil
+	case *jwt.SigningMethodEd25519:
+		key, ok := private.(ed25519.PrivateKey)
+		if !ok {
+			break
+		}
+		return key.Public(), nil
+	}
+
+	return nil, fmt.Errorf("%w: %T can not sign %s", ErrKeyMismatch, private, method.Alg())
+}
@@ -2,34 +2,66 @@ package token
 
 import (
 	"context"
+	"errors"
+	"sort"
+	"time"
 
 	"github.com/google/uuid"
+	"roadmap.restapi/internal/ctxlogging"
 )
 
 type UseCases struct {
 	extractor ClaimsExtractor
 	generator Generator
 	whitelist WhitelistRepository
+	sessions  SessionRepository
+	denylist  Denylist
 }
 
 func NewUseCases(
 	extactor ClaimsExtractor,
 	generator Generator,
 	whitelist WhitelistRepository,
+	sessions SessionRepository,
+	denylist Denylist,
 ) *UseCases {
 	return &UseCases{
 		extractor: extactor,
 		generator: generator,
 		whitelist: whitelist,
+		sessions:  sessions,
+		denylist:  denylist,
 	}
 }
 
-func (u *UseCases) NewPair(ctx context.Context, userID uuid.UUID) (*TokenPair, error) {
+// NewPair logs the user in, starting a new session on the client.
+func (u *UseCases) NewPair(ctx context.Context, userID uuid.UUID, client SessionClient) (*TokenPair, error) {
+	now := time.Now()
+	session := &Session{
+		ID:              uuid.New(),
+		UserID:          userID,
+		CreatedAt:       now,
+		LastRefreshedAt: now,
+		IP:              client.IP,
+		UserAgent:       client.UserAgent,
+	}
+
+	if err := u.sessions.Save(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return u.newPair(ctx, userID, session.ID)
+}
+
+// newPair issues tokens of the session, the refresh token joining the token
+// family of the session.
+func (u *UseCases) newPair(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) (*TokenPair, error) {
 	accessTokenID := uuid.New()
 	refreshTokenID := uuid.New()
 
 	userClaims := &UserClaims{
 		UID: userID,
+		SID: sessionID,
 	}
 
 	access, err := u.generator.Generate(ctx, userClaims, ACCESS, accessTokenID)
@@ -42,7 +74,7 @@ func (u *UseCases) NewPair(ctx context.Context, userID uuid.UUID) (*TokenPair, e
 		return nil, err
 	}
 
-	if err = u.whitelist.Add(ctx, refreshTokenID); err != nil {
+	if err = u.whitelist.Add(ctx, refreshTokenID, sessionID); err != nil {
 		return nil, err
 	}
 
@@ -52,33 +84,180 @@ func (u *UseCases) NewPair(ctx context.Context, userID uuid.UUID) (*TokenPair, e
 	}, nil
 }
 
-func (u *UseCases) RevokeToken(ctx context.Context, refreshToken string) error {
+// RevokeToken logs out the session of the refresh token. The access token,
+// if still valid, is revoked too.
+func (u *UseCases) RevokeToken(ctx context.Context, refreshToken string, accessToken string) error {
 	claims, err := u.extractor.ParseAndValidate(ctx, refreshToken)
 	if err != nil {
 		return err
 	}
 
-	return u.whitelist.Remove(ctx, claims.ID)
+	if claims.Type != REFRESH {
+		return ErrTokenInvalid
+	}
+
+	if accessToken != "" {
+		access, err := u.extractor.ParseAndValidate(ctx, accessToken)
+		if err == nil && access.Type == ACCESS && access.UID == claims.UID && access.ExpiresAt != nil {
+			if err = u.denylist.Deny(ctx, access.ID, access.ExpiresAt.Time); err != nil {
+				return err
+			}
+		}
+	}
+
+	// tokens issued before sessions were tracked
+	if claims.SID == uuid.Nil {
+		return u.whitelist.Remove(ctx, claims.ID)
+	}
+
+	return u.revoke(ctx, claims.UID, claims.SID)
 }
 
-func (u *UseCases) RefreshTokenPair(ctx context.Context, refreshToken string) (*TokenPair, error) {
+func (u *UseCases) RefreshTokenPair(ctx context.Context, refreshToken string, client SessionClient) (*TokenPair, error) {
 	claims, err := u.extractor.ParseAndValidate(ctx, refreshToken)
 	if err != nil {
 		return nil, err
 	}
 
-	exists, err := u.whitelist.Exists(ctx, claims.ID)
+	if claims.Type != REFRESH {
+		return nil, ErrTokenInvalid
+	}
+
+	if err = u.checkDenied(ctx, claims); err != nil {
+		return nil, err
+	}
+
+	family, ok, err := u.whitelist.Consume(ctx, claims.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	if !exists {
+	if !ok {
+		return nil, u.refreshRejected(ctx, claims)
+	}
+
+	// the session is gone once it was revoked
+	session, err := u.sessions.Get(ctx, family)
+	if errors.Is(err, ErrSessionNotFound) {
+		return nil, ErrRefreshNotWhitelisted
+	} else if err != nil {
+		return nil, err
+	}
+
+	session.LastRefreshedAt = time.Now()
+	session.IP = client.IP
+	session.UserAgent = client.UserAgent
+	err = u.sessions.Update(ctx, session)
+	if errors.Is(err, ErrSessionNotFound) {
 		return nil, ErrRefreshNotWhitelisted
+	} else if err != nil {
+		return nil, err
+	}
+
+	return u.newPair(ctx, claims.UID, family)
+}
+
+// checkDenied refuses tokens revoked on their own or with their session.
+func (u *UseCases) checkDenied(ctx context.Context, claims *TokenClaims) error {
+	ids := []uuid.UUID{claims.ID}
+	if claims.SID != uuid.Nil {
+		ids = append(ids, claims.SID)
+	}
+
+	denied, err := u.denylist.Denied(ctx, ids...)
+	if err != nil {
+		return err
+	}
+
+	if denied {
+		return ErrTokenRevoked
+	}
+
+	return nil
+}
+
+// refreshRejected tells a replayed refresh token from one that was revoked or
+// never issued. A replay means the token leaked, so every session of its
+// family is revoked and the owner has to log in again.
+func (u *UseCases) refreshRejected(ctx context.Context, claims *TokenClaims) error {
+	family, reused, err := u.whitelist.UsedFamily(ctx, claims.ID)
+	if err != nil {
+		return err
+	}
+
+	if !reused {
+		return ErrRefreshNotWhitelisted
 	}
 
-	if err = u.whitelist.Remove(ctx, claims.ID); err != nil {
+	ctxlogging.Get(ctx).Warn("refresh token reuse detected, revoking token family",
+		"event", "refresh_token_reuse", "user_id", claims.UID, "token_id", claims.ID, "family", family)
+
+	if err = u.revoke(ctx, claims.UID, family); err != nil {
+		return err
+	}
+
+	return ErrRefreshReused
+}
+
+// Sessions returns the sessions of the user, most recently used first.
+func (u *UseCases) Sessions(ctx context.Context, userID uuid.UUID) ([]Session, error) {
+	sessions, err := u.sessions.ByUser(ctx, userID)
+	if err != nil {
 		return nil, err
 	}
 
-	return u.NewPair(ctx, claims.UID)
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastRefreshedAt.After(sessions[j].LastRefreshedAt)
+	})
+
+	return sessions, nil
+}
+
+func (u *UseCases) RevokeSession(ctx context.Context, userID uuid.UUID, id uuid.UUID) error {
+	session, err := u.sessions.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if session.UserID != userID {
+		return ErrSessionNotFound
+	}
+
+	return u.revoke(ctx, userID, id)
+}
+
+// RevokeSessions logs the user out everywhere but in the except session,
+// uuid.Nil revoking all of them.
+func (u *UseCases) RevokeSessions(ctx context.Context, userID uuid.UUID, except uuid.UUID) error {
+	sessions, err := u.sessions.ByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.ID == except {
+			continue
+		}
+
+		if err = u.revoke(ctx, userID, session.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// revoke ends the session. Its tokens are denied for as long as any of them
+// may live and its refresh tokens are dropped.
+func (u *UseCases) revoke(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	ttl := max(u.generator.TTL(ACCESS), u.generator.TTL(REFRESH))
+	if err := u.denylist.Deny(ctx, sessionID, time.Now().Add(ttl)); err != nil {
+		return err
+	}
+
+	if err := u.whitelist.RevokeFamily(ctx, sessionID); err != nil {
+		return err
+	}
+
+	return u.sessions.Delete(ctx, userID, sessionID)
 }
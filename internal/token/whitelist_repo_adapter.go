@@ -2,13 +2,57 @@ package token
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
-const keyPrefix = "token-wl:"
+const (
+	keyPrefix        = "token-wl:"
+	usedKeyPrefix    = "token-used:"
+	familyKeyPrefix  = "token-fam:"
+	revokedKeyPrefix = "token-fam-revoked:"
+)
+
+// addScript whitelists a token in its family unless the family was revoked.
+// A rotation racing with a revocation can not bring the family back.
+var addScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[3]) == 1 then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+redis.call('SADD', KEYS[2], ARGV[1])
+redis.call('PEXPIRE', KEYS[2], ARGV[3])
+return 1
+`)
+
+// consumeScript moves a whitelisted token to the used tokens in one step, so
+// a crash in between can not lose track of a consumed token.
+var consumeScript = redis.NewScript(`
+local family = redis.call('GETDEL', KEYS[1])
+if not family then
+	return false
+end
+redis.call('SET', KEYS[2], family, 'PX', ARGV[1])
+return family
+`)
+
+// revokeFamilyScript deletes every token of a family together with the
+// family and marks the family revoked for as long as its tokens may live, a
+// token added to the family meanwhile can not slip through. The token keys
+// are derived from the members, so the whitelist has to live on a single
+// node.
+var revokeFamilyScript = redis.NewScript(`
+local members = redis.call('SMEMBERS', KEYS[1])
+for _, member in ipairs(members) do
+	redis.call('DEL', ARGV[1] .. member)
+end
+redis.call('DEL', KEYS[1])
+redis.call('SET', KEYS[2], 1, 'PX', ARGV[2])
+return #members
+`)
 
 type RedisWhitelistRepository struct {
 	client  *redis.Client
@@ -22,10 +66,18 @@ func NewRedisWhitelistRepository(client *redis.Client, tokenExpirationTime time.
 	}
 }
 
-func (r *RedisWhitelistRepository) Add(ctx context.Context, id uuid.UUID) error {
-	status := r.client.SetEx(ctx, keyPrefix+id.String(), "", r.expTime)
+func (r *RedisWhitelistRepository) Add(ctx context.Context, id uuid.UUID, family uuid.UUID) error {
+	keys := []string{keyPrefix + id.String(), familyKeyPrefix + family.String(), revokedKeyPrefix + family.String()}
+	added, err := addScript.Run(ctx, r.client, keys, id.String(), family.String(), r.expTime.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
 
-	return status.Err()
+	if added == 0 {
+		return ErrTokenRevoked
+	}
+
+	return nil
 }
 
 func (r *RedisWhitelistRepository) Exists(ctx context.Context, id uuid.UUID) (bool, error) {
@@ -38,8 +90,47 @@ func (r *RedisWhitelistRepository) Exists(ctx context.Context, id uuid.UUID) (bo
 	return val != 0, nil
 }
 
+// Consume takes the token off the whitelist and remembers it as used, so a
+// later replay can be told apart from a token that was never issued. Only one
+// of concurrent calls for the same token gets ok.
+func (r *RedisWhitelistRepository) Consume(ctx context.Context, id uuid.UUID) (uuid.UUID, bool, error) {
+	keys := []string{keyPrefix + id.String(), usedKeyPrefix + id.String()}
+	cmd := consumeScript.Run(ctx, r.client, keys, r.expTime.Milliseconds())
+
+	return r.family(redis.NewStringResult(cmd.Text()), id)
+}
+
+func (r *RedisWhitelistRepository) UsedFamily(ctx context.Context, id uuid.UUID) (uuid.UUID, bool, error) {
+	return r.family(r.client.Get(ctx, usedKeyPrefix+id.String()), id)
+}
+
+func (r *RedisWhitelistRepository) RevokeFamily(ctx context.Context, family uuid.UUID) error {
+	keys := []string{familyKeyPrefix + family.String(), revokedKeyPrefix + family.String()}
+
+	return revokeFamilyScript.Run(ctx, r.client, keys, keyPrefix, r.expTime.Milliseconds()).Err()
+}
+
 func (r *RedisWhitelistRepository) Remove(ctx context.Context, id uuid.UUID) error {
 	status := r.client.Del(ctx, keyPrefix+id.String())
 
 	return status.Err()
 }
+
+// family reads the family stored for a token. Tokens whitelisted before
+// families existed have no value and form a family of their own.
+func (r *RedisWhitelistRepository) family(cmd *redis.StringCmd, id uuid.UUID) (uuid.UUID, bool, error) {
+	val, err := cmd.Result()
+	if errors.Is(err, redis.Nil) {
+		return uuid.Nil, false, nil
+	}
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+
+	family, err := uuid.Parse(val)
+	if err != nil {
+		return id, true, nil
+	}
+
+	return family, true, nil
+}
@@ -1,15 +1,198 @@
 package url
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type URL struct {
-	ID       string    `db:"id"`
+	// Domain is the custom domain the link is served on, empty for the
+	// default domain. A link is identified by Domain and ID together.
+	Domain    string    `db:"domain"`
+	ID        string    `db:"id"`
 	URL       string    `db:"url"`
 	Name      string    `db:"name"`
 	AuthorID  uuid.UUID `db:"author_id"`
 	CreatedAt time.Time `db:"created_at"`
+
+	// metadata fetched from the destination
+	MetaTitle       string `db:"meta_title"`
+	MetaDescription string `db:"meta_description"`
+	MetaImage       string `db:"meta_image"`
+
+	// Open Graph values set by the author, they take precedence over fetched
+	// metadata
+	OGTitle       string `db:"og_title"`
+	OGDescription string `db:"og_description"`
+	OGImage       string `db:"og_image"`
+
+	// Interstitial makes the link always show a warning page before
+	// redirecting.
+	Interstitial         bool       `db:"interstitial"`
+	DestinationChangedAt *time.Time `db:"destination_changed_at"`
+
+	// ExpiresAt is the moment the link stops redirecting, nil for links
+	// that never expire.
+	ExpiresAt *time.Time `db:"expires_at"`
+
+	// AppendClickID makes redirects carry the click id to the destination
+	// for conversion tracking.
+	AppendClickID bool `db:"append_click_id"`
+}
+
+func (u *URL) IsExpired(now time.Time) bool {
+	return u.ExpiresAt != nil && !now.Before(*u.ExpiresAt)
+}
+
+// ShortURL returns the public short link. Links of the default domain are
+// served under baseURL, links of custom domains on their domain with the
+// scheme of baseURL.
+func (u *URL) ShortURL(baseURL string) string {
+	if u.Domain != "" {
+		scheme, _, found := strings.Cut(baseURL, "://")
+		if !found {
+			scheme = "https"
+		}
+
+		return scheme + "://" + u.Domain + "/" + u.ID
+	}
+
+	return strings.TrimRight(baseURL, "/") + "/" + u.ID
+}
+
+// Metadata is the link preview information of a page.
+type Metadata struct {
+	Title       string
+	Description string
+	Image       string
+}
+
+// SetMetadata stores metadata fetched from the destination.
+func (u *URL) SetMetadata(m *Metadata) {
+	u.MetaTitle = m.Title
+	u.MetaDescription = m.Description
+	u.MetaImage = m.Image
+}
+
+// Preview returns the values shown to link preview crawlers: author
+// overrides first, then fetched metadata.
+func (u *URL) Preview() Metadata {
+	return Metadata{
+		Title:       firstNonEmpty(u.OGTitle, u.MetaTitle, u.Name),
+		Description: firstNonEmpty(u.OGDescription, u.MetaDescription),
+		Image:       firstNonEmpty(u.OGImage, u.MetaImage),
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// Revision is an immutable record of a single update of URL. It outlives
+// the URL.
+type Revision struct {
+	ID        uuid.UUID `db:"id"`
+	URLDomain string    `db:"url_domain"`
+	URLID     string    `db:"url_id"`
+	AuthorID  uuid.UUID `db:"author_id"`
+	OldURL    string    `db:"old_url"`
+	NewURL    string    `db:"new_url"`
+	OldName   string    `db:"old_name"`
+	NewName   string    `db:"new_name"`
+	CreatedAt time.Time `db:"created_at"`
+
+	// HasSettings is false for revisions made before the other author
+	// editable values were recorded, they hold the zero values.
+	HasSettings      bool       `db:"has_settings"`
+	OldOGTitle       string     `db:"old_og_title"`
+	NewOGTitle       string     `db:"new_og_title"`
+	OldOGDescription string     `db:"old_og_description"`
+	NewOGDescription string     `db:"new_og_description"`
+	OldOGImage       string     `db:"old_og_image"`
+	NewOGImage       string     `db:"new_og_image"`
+	OldInterstitial  bool       `db:"old_interstitial"`
+	NewInterstitial  bool       `db:"new_interstitial"`
+	OldExpiresAt     *time.Time `db:"old_expires_at"`
+	NewExpiresAt     *time.Time `db:"new_expires_at"`
+	OldAppendClickID bool       `db:"old_append_click_id"`
+	NewAppendClickID bool       `db:"new_append_click_id"`
+}
+
+// NewRevision records the update of old to new made by authorID.
+func NewRevision(authorID uuid.UUID, old *URL, new *URL) *Revision {
+	return &Revision{
+		URLDomain:        old.Domain,
+		URLID:            old.ID,
+		AuthorID:         authorID,
+		OldURL:           old.URL,
+		NewURL:           new.URL,
+		OldName:          old.Name,
+		NewName:          new.Name,
+		HasSettings:      true,
+		OldOGTitle:       old.OGTitle,
+		NewOGTitle:       new.OGTitle,
+		OldOGDescription: old.OGDescription,
+		NewOGDescription: new.OGDescription,
+		OldOGImage:       old.OGImage,
+		NewOGImage:       new.OGImage,
+		OldInterstitial:  old.Interstitial,
+		NewInterstitial:  new.Interstitial,
+		OldExpiresAt:     old.ExpiresAt,
+		NewExpiresAt:     new.ExpiresAt,
+		OldAppendClickID: old.AppendClickID,
+		NewAppendClickID: new.AppendClickID,
+	}
+}
+
+// Restore sets the author editable values of u to the ones u had before the
+// revision. Revisions without settings restore the destination and name
+// only.
+func (r *Revision) Restore(u *URL) {
+	u.URL = r.OldURL
+	u.Name = r.OldName
+	if !r.HasSettings {
+		return
+	}
+
+	u.OGTitle = r.OldOGTitle
+	u.OGDescription = r.OldOGDescription
+	u.OGImage = r.OldOGImage
+	u.Interstitial = r.OldInterstitial
+	u.ExpiresAt = r.OldExpiresAt
+	u.AppendClickID = r.OldAppendClickID
+}
+
+type TransferStatus string
+
+const (
+	TRANSFER_PENDING   TransferStatus = "pending"
+	TRANSFER_ACCEPTED  TransferStatus = "accepted"
+	TRANSFER_DECLINED  TransferStatus = "declined"
+	TRANSFER_CANCELLED TransferStatus = "cancelled"
+)
+
+// Transfer is a request to hand URL over to another user. It takes effect
+// only after the recipient accepts it before ExpiresAt.
+type Transfer struct {
+	ID         uuid.UUID      `db:"id"`
+	URLDomain  string         `db:"url_domain"`
+	URLID      string         `db:"url_id"`
+	FromUserID uuid.UUID      `db:"from_user_id"`
+	ToUserID   uuid.UUID      `db:"to_user_id"`
+	Status     TransferStatus `db:"status"`
+	CreatedAt  time.Time      `db:"created_at"`
+	ExpiresAt  time.Time      `db:"expires_at"`
+	ResolvedAt *time.Time     `db:"resolved_at"`
+}
+
+func (t *Transfer) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
 }
@@ -3,7 +3,14 @@ package url
 import "errors"
 
 var (
-	ErrURLNotFound      = errors.New("url not found")
-	ErrURLAlreadyExists = errors.New("url already exists")
-	ErrUserIsNotAuthor  = errors.New("this user is not author of url")
+	ErrURLNotFound        = errors.New("url not found")
+	ErrURLAlreadyExists   = errors.New("url already exists")
+	ErrUserIsNotAuthor    = errors.New("this user is not author of url")
+	ErrRevisionNotFound   = errors.New("revision not found")
+	ErrTransferNotFound   = errors.New("transfer not found")
+	ErrTransferNotPending = errors.New("transfer is already resolved")
+	ErrTransferExpired    = errors.New("transfer expired")
+	ErrTransferToSelf     = errors.New("url can not be transferred to its author")
+	ErrDomainNotAllowed   = errors.New("domain is not verified by this user")
+	ErrRecipientNotOwner  = errors.New("recipient has not verified the domain of the url")
 )
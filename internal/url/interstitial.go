@@ -0,0 +1,84 @@
+package url
+
+import (
+	neturl "net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type InterstitialReason string
+
+const (
+	INTERSTITIAL_LINK             InterstitialReason = "link"
+	INTERSTITIAL_DOMAIN           InterstitialReason = "domain"
+	INTERSTITIAL_SUSPICIOUS       InterstitialReason = "suspicious"
+	INTERSTITIAL_RECENTLY_CHANGED InterstitialReason = "recently_changed"
+)
+
+// InterstitialPolicy decides whether a visitor has to see a warning page
+// with the full destination before being redirected.
+type InterstitialPolicy struct {
+	domains      []string
+	suspicious   []*regexp.Regexp
+	recentChange time.Duration
+}
+
+// NewInterstitialPolicy builds a policy. domains are destination hosts
+// (subdomains included) that always get the warning, suspicious are regular
+// expressions matched against the full destination and recentChange is the
+// period after a destination edit during which the warning is shown.
+func NewInterstitialPolicy(domains []string, suspicious []string, recentChange time.Duration) (*InterstitialPolicy, error) {
+	policy := &InterstitialPolicy{
+		recentChange: recentChange,
+	}
+
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain != "" {
+			policy.domains = append(policy.domains, domain)
+		}
+	}
+
+	for _, pattern := range suspicious {
+		if strings.TrimSpace(pattern) == "" {
+			continue
+		}
+
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, err
+		}
+		policy.suspicious = append(policy.suspicious, re)
+	}
+
+	return policy, nil
+}
+
+// Reason returns why the interstitial must be shown for u, if at all.
+func (p *InterstitialPolicy) Reason(u *URL, now time.Time) (InterstitialReason, bool) {
+	if u.Interstitial {
+		return INTERSTITIAL_LINK, true
+	}
+
+	if parsed, err := neturl.Parse(u.URL); err == nil {
+		host := strings.ToLower(parsed.Hostname())
+		for _, domain := range p.domains {
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return INTERSTITIAL_DOMAIN, true
+			}
+		}
+	}
+
+	for _, re := range p.suspicious {
+		if re.MatchString(u.URL) {
+			return INTERSTITIAL_SUSPICIOUS, true
+		}
+	}
+
+	if u.DestinationChangedAt != nil && now.Sub(*u.DestinationChangedAt) < p.recentChange {
+		return INTERSTITIAL_RECENTLY_CHANGED, true
+	}
+
+	return "", false
+}
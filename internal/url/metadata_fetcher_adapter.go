@@ -0,0 +1,130 @@
+package url
+
+import (
+	"context"
+	"errors"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	neturl "net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"roadmap.restapi/internal/safehttp"
+)
+
+var (
+	metaTagRe   = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	attributeRe = regexp.MustCompile(`(?is)([a-z:_-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	titleTagRe  = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	spacesRe    = regexp.MustCompile(`\s+`)
+)
+
+var errNotHTML = errors.New("destination is not an html page")
+
+// HTTPMetadataFetcher downloads the destination page and extracts Open Graph,
+// Twitter card and plain html title/description tags from it.
+type HTTPMetadataFetcher struct {
+	client    *http.Client
+	userAgent string
+	maxBytes  int64
+}
+
+// NewHTTPMetadataFetcher returns a fetcher that refuses destinations and
+// redirects pointing to private, loopback or link-local addresses.
+func NewHTTPMetadataFetcher(timeout time.Duration, userAgent string, maxBytes int64) *HTTPMetadataFetcher {
+	return NewHTTPMetadataFetcherWithClient(safehttp.NewClient(timeout), userAgent, maxBytes)
+}
+
+// NewHTTPMetadataFetcherWithClient returns a fetcher that sends requests with
+// client. Unlike NewHTTPMetadataFetcher it reaches any address the client
+// allows.
+func NewHTTPMetadataFetcherWithClient(client *http.Client, userAgent string, maxBytes int64) *HTTPMetadataFetcher {
+	return &HTTPMetadataFetcher{
+		client:    client,
+		userAgent: userAgent,
+		maxBytes:  maxBytes,
+	}
+}
+
+func (f *HTTPMetadataFetcher) Fetch(ctx context.Context, target string) (*Metadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, errNotHTML
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	meta := ParseMetadata(string(body))
+	meta.Image = resolveReference(resp.Request.URL, meta.Image)
+
+	return meta, nil
+}
+
+// ParseMetadata extracts preview metadata from an html document. Open Graph
+// tags win over Twitter cards, which win over plain html tags.
+func ParseMetadata(document string) *Metadata {
+	tags := map[string]string{}
+	for _, tag := range metaTagRe.FindAllString(document, -1) {
+		attrs := map[string]string{}
+		for _, m := range attributeRe.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(m[1])] = m[2] + m[3] + m[4]
+		}
+
+		key := attrs["property"]
+		if key == "" {
+			key = attrs["name"]
+		}
+		key = strings.ToLower(key)
+
+		if _, seen := tags[key]; key != "" && !seen {
+			tags[key] = attrs["content"]
+		}
+	}
+
+	title := ""
+	if m := titleTagRe.FindStringSubmatch(document); m != nil {
+		title = m[1]
+	}
+
+	return &Metadata{
+		Title:       clean(firstNonEmpty(tags["og:title"], tags["twitter:title"], title)),
+		Description: clean(firstNonEmpty(tags["og:description"], tags["twitter:description"], tags["description"])),
+		Image:       clean(firstNonEmpty(tags["og:image"], tags["og:image:url"], tags["twitter:image"])),
+	}
+}
+
+func clean(value string) string {
+	return strings.TrimSpace(spacesRe.ReplaceAllString(html.UnescapeString(value), " "))
+}
+
+func resolveReference(base *neturl.URL, ref string) string {
+	if ref == "" || base == nil {
+		return ref
+	}
+
+	parsed, err := neturl.Parse(ref)
+	if err != nil {
+		return ""
+	}
+
+	return base.ResolveReference(parsed).String()
+}
@@ -0,0 +1,54 @@
+package url
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/outbox"
+)
+
+// metadataRequest is the payload of TOPIC_METADATA_REQUESTED messages.
+type metadataRequest struct {
+	URLDomain string `json:"url_domain"`
+	URLID     string `json:"url_id"`
+	URL       string `json:"url"`
+}
+
+// MetadataSender handles TOPIC_METADATA_REQUESTED outbox messages by fetching
+// the preview metadata of the destination. Fetching is best-effort: a failed
+// fetch only leaves the metadata empty.
+type MetadataSender struct {
+	repo    URLRepository
+	fetcher MetadataFetcher
+}
+
+func NewMetadataSender(repo URLRepository, fetcher MetadataFetcher) *MetadataSender {
+	return &MetadataSender{
+		repo:    repo,
+		fetcher: fetcher,
+	}
+}
+
+func (s *MetadataSender) Send(ctx context.Context, msg *outbox.Message) error {
+	log := ctxlogging.Get(ctx)
+	var req metadataRequest
+	if err := json.Unmarshal([]byte(msg.Payload), &req); err != nil {
+		log.Error("malformed metadata request", "id", msg.ID, "err", err)
+		return nil
+	}
+
+	meta, err := s.fetcher.Fetch(ctx, req.URL)
+	if err != nil {
+		log.Debug("failed to fetch url metadata", "url", req.URL, "err", err)
+		return nil
+	}
+
+	err = s.repo.SetMetadata(ctx, req.URLDomain, req.URLID, req.URL, meta)
+	if err != nil && !errors.Is(err, ErrURLNotFound) {
+		return err
+	}
+
+	return nil
+}
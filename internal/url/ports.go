@@ -7,9 +7,42 @@ import (
 )
 
 type URLRepository interface {
-	ByID(ctx context.Context, id string) (*URL, error)
+	ByID(ctx context.Context, domain string, id string) (*URL, error)
+	// LockByID is ByID locking the row until the transaction of ctx ends.
+	LockByID(ctx context.Context, domain string, id string) (*URL, error)
 	Update(ctx context.Context, url *URL) error
+	// SetAuthor hands the URL over to authorID, leaving everything else as
+	// it is.
+	SetAuthor(ctx context.Context, domain string, id string, authorID uuid.UUID) error
 	Create(ctx context.Context, url *URL) error
-	Delete(ctx context.Context, id string) error
+	Delete(ctx context.Context, domain string, id string) error
 	ByUser(ctx context.Context, userID uuid.UUID) ([]URL, error)
+	// SetMetadata stores fetched metadata unless the destination has changed
+	// since the fetch was requested.
+	SetMetadata(ctx context.Context, domain string, id string, destination string, meta *Metadata) error
+}
+
+type MetadataFetcher interface {
+	Fetch(ctx context.Context, target string) (*Metadata, error)
+}
+
+type RevisionRepository interface {
+	Create(ctx context.Context, revision *Revision) error
+	ByID(ctx context.Context, id uuid.UUID) (*Revision, error)
+	ByURL(ctx context.Context, domain string, urlID string) ([]Revision, error)
+}
+
+type TransferRepository interface {
+	Create(ctx context.Context, transfer *Transfer) error
+	ByID(ctx context.Context, id uuid.UUID) (*Transfer, error)
+	IncomingPending(ctx context.Context, userID uuid.UUID) ([]Transfer, error)
+	// Resolve moves a pending transfer to status. It returns
+	// ErrTransferNotPending if the transfer was resolved concurrently.
+	Resolve(ctx context.Context, id uuid.UUID, status TransferStatus) error
+	CancelPending(ctx context.Context, domain string, urlID string) error
+}
+
+// DomainVerifier tells whether a user may place links on a custom domain.
+type DomainVerifier interface {
+	IsVerifiedOwner(ctx context.Context, userID uuid.UUID, domain string) (bool, error)
 }
@@ -0,0 +1,94 @@
+package url
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/database"
+	"roadmap.restapi/internal/errormapper"
+	"roadmap.restapi/internal/postgres"
+)
+
+type PostgresRevisionRepository struct {
+	db     *sqlx.DB
+	errMap *errormapper.ErrorMapper
+}
+
+func NewPostgresRevisionRepository(db *sqlx.DB) *PostgresRevisionRepository {
+	return &PostgresRevisionRepository{
+		db: db,
+		errMap: errormapper.NewErrorMapper(
+			errormapper.NewMapping(database.ErrNotFound, ErrRevisionNotFound),
+		),
+	}
+}
+
+func (r *PostgresRevisionRepository) Create(ctx context.Context, revision *Revision) error {
+	log := ctxlogging.Get(ctx)
+	rows, err := sqlx.NamedQueryContext(ctx, postgres.Conn(ctx, r.db), `INSERT INTO url_revisions (
+		url_domain, url_id, author_id, old_url, new_url, old_name, new_name,
+		has_settings, old_og_title, new_og_title, old_og_description, new_og_description,
+		old_og_image, new_og_image, old_interstitial, new_interstitial,
+		old_expires_at, new_expires_at, old_append_click_id, new_append_click_id
+	)
+	VALUES (
+		:url_domain, :url_id, :author_id, :old_url, :new_url, :old_name, :new_name,
+		:has_settings, :old_og_title, :new_og_title, :old_og_description, :new_og_description,
+		:old_og_image, :new_og_image, :old_interstitial, :new_interstitial,
+		:old_expires_at, :new_expires_at, :old_append_click_id, :new_append_click_id
+	)
+	RETURNING *
+	`, revision)
+
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+	defer rows.Close()
+
+	rows.Next()
+	if err = rows.Err(); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	if err = rows.StructScan(revision); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
+
+func (r *PostgresRevisionRepository) ByID(ctx context.Context, id uuid.UUID) (*Revision, error) {
+	log := ctxlogging.Get(ctx)
+	var revision Revision
+	err := r.db.GetContext(ctx, &revision, r.db.Rebind("SELECT * FROM url_revisions WHERE id = ?"), id)
+	if err != nil {
+		return nil, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return &revision, nil
+}
+
+// ByURL returns the revisions of the URL as it exists now, revisions of a
+// deleted URL that had the same id are left out.
+func (r *PostgresRevisionRepository) ByURL(ctx context.Context, domain string, urlID string) ([]Revision, error) {
+	log := ctxlogging.Get(ctx)
+	revisions := []Revision{}
+	err := r.db.SelectContext(
+		ctx,
+		&revisions,
+		r.db.Rebind(`SELECT url_revisions.* FROM url_revisions
+		JOIN urls ON urls.domain = url_revisions.url_domain AND urls.id = url_revisions.url_id
+		WHERE url_revisions.url_domain = ? AND url_revisions.url_id = ? AND url_revisions.created_at >= urls.created_at
+		ORDER BY url_revisions.created_at DESC`),
+		domain,
+		urlID,
+	)
+
+	if err != nil {
+		return revisions, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return revisions, nil
+}
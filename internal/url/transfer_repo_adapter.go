@@ -0,0 +1,115 @@
+package url
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"roadmap.restapi/internal/ctxlogging"
+	"roadmap.restapi/internal/database"
+	"roadmap.restapi/internal/errormapper"
+	"roadmap.restapi/internal/postgres"
+)
+
+type PostgresTransferRepository struct {
+	db     *sqlx.DB
+	errMap *errormapper.ErrorMapper
+}
+
+func NewPostgresTransferRepository(db *sqlx.DB) *PostgresTransferRepository {
+	return &PostgresTransferRepository{
+		db: db,
+		errMap: errormapper.NewErrorMapper(
+			errormapper.NewMapping(database.ErrNotFound, ErrTransferNotFound),
+			errormapper.NewMapping(database.ErrForeignKeyViolation, ErrURLNotFound),
+		),
+	}
+}
+
+func (r *PostgresTransferRepository) Create(ctx context.Context, transfer *Transfer) error {
+	log := ctxlogging.Get(ctx)
+	rows, err := sqlx.NamedQueryContext(ctx, postgres.Conn(ctx, r.db), `INSERT INTO url_transfers (url_domain, url_id, from_user_id, to_user_id, expires_at)
+	VALUES (:url_domain, :url_id, :from_user_id, :to_user_id, :expires_at)
+	RETURNING *
+	`, transfer)
+
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+	defer rows.Close()
+
+	rows.Next()
+	if err = rows.Err(); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	if err = rows.StructScan(transfer); err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
+
+func (r *PostgresTransferRepository) ByID(ctx context.Context, id uuid.UUID) (*Transfer, error) {
+	log := ctxlogging.Get(ctx)
+	var transfer Transfer
+	err := r.db.GetContext(ctx, &transfer, r.db.Rebind("SELECT * FROM url_transfers WHERE id = ?"), id)
+	if err != nil {
+		return nil, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return &transfer, nil
+}
+
+func (r *PostgresTransferRepository) IncomingPending(ctx context.Context, userID uuid.UUID) ([]Transfer, error) {
+	log := ctxlogging.Get(ctx)
+	transfers := []Transfer{}
+	err := r.db.SelectContext(ctx, &transfers, r.db.Rebind(`SELECT * FROM url_transfers
+	WHERE to_user_id = ?
+		AND status = ?
+		AND expires_at > CURRENT_TIMESTAMP
+	ORDER BY created_at DESC`), userID, TRANSFER_PENDING)
+
+	if err != nil {
+		return transfers, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return transfers, nil
+}
+
+func (r *PostgresTransferRepository) Resolve(ctx context.Context, id uuid.UUID, status TransferStatus) error {
+	log := ctxlogging.Get(ctx)
+	db := postgres.Conn(ctx, r.db)
+	res, err := db.ExecContext(ctx, db.Rebind(`UPDATE url_transfers
+	SET status = ?,
+		resolved_at = CURRENT_TIMESTAMP
+	WHERE id = ? AND status = ?`), status, id, TRANSFER_PENDING)
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	if rows == 0 {
+		return ErrTransferNotPending
+	}
+
+	return nil
+}
+
+func (r *PostgresTransferRepository) CancelPending(ctx context.Context, domain string, urlID string) error {
+	log := ctxlogging.Get(ctx)
+	db := postgres.Conn(ctx, r.db)
+	_, err := db.ExecContext(ctx, db.Rebind(`UPDATE url_transfers
+	SET status = ?,
+		resolved_at = CURRENT_TIMESTAMP
+	WHERE url_domain = ? AND url_id = ? AND status = ?`), TRANSFER_CANCELLED, domain, urlID, TRANSFER_PENDING)
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
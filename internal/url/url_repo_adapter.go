@@ -26,10 +26,23 @@ func NewPostgresURLRepository(db *sqlx.DB) *PostgresURLRepository {
 	}
 }
 
-func (r *PostgresURLRepository) ByID(ctx context.Context, id string) (*URL, error) {
+func (r *PostgresURLRepository) ByID(ctx context.Context, domain string, id string) (*URL, error) {
 	log := ctxlogging.Get(ctx)
 	var url URL
-	err := r.db.GetContext(ctx, &url, r.db.Rebind("SELECT * FROM urls WHERE id = ?"), id)
+	db := postgres.Conn(ctx, r.db)
+	err := db.GetContext(ctx, &url, db.Rebind("SELECT * FROM urls WHERE domain = ? AND id = ?"), domain, id)
+	if err != nil {
+		return nil, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return &url, nil
+}
+
+func (r *PostgresURLRepository) LockByID(ctx context.Context, domain string, id string) (*URL, error) {
+	log := ctxlogging.Get(ctx)
+	var url URL
+	db := postgres.Conn(ctx, r.db)
+	err := db.GetContext(ctx, &url, db.Rebind("SELECT * FROM urls WHERE domain = ? AND id = ? FOR UPDATE"), domain, id)
 	if err != nil {
 		return nil, r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
 	}
@@ -39,17 +52,28 @@ func (r *PostgresURLRepository) ByID(ctx context.Context, id string) (*URL, erro
 
 func (r *PostgresURLRepository) Update(ctx context.Context, url *URL) error {
 	log := ctxlogging.Get(ctx)
-	rows, err := r.db.NamedQueryContext(ctx, `UPDATE urls
+	rows, err := sqlx.NamedQueryContext(ctx, postgres.Conn(ctx, r.db), `UPDATE urls
 	SET author_id = :author_id,
 		url = :url,
-		name = :name
-	WHERE id = :id
+		name = :name,
+		meta_title = :meta_title,
+		meta_description = :meta_description,
+		meta_image = :meta_image,
+		og_title = :og_title,
+		og_description = :og_description,
+		og_image = :og_image,
+		interstitial = :interstitial,
+		destination_changed_at = :destination_changed_at,
+		expires_at = :expires_at,
+		append_click_id = :append_click_id
+	WHERE domain = :domain AND id = :id
 	RETURNING *
 	`, url)
 
 	if err != nil {
 		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
 	}
+	defer rows.Close()
 
 	if !rows.Next() {
 		return ErrURLNotFound
@@ -66,16 +90,60 @@ func (r *PostgresURLRepository) Update(ctx context.Context, url *URL) error {
 	return nil
 }
 
+func (r *PostgresURLRepository) SetAuthor(ctx context.Context, domain string, id string, authorID uuid.UUID) error {
+	log := ctxlogging.Get(ctx)
+	db := postgres.Conn(ctx, r.db)
+	res, err := db.ExecContext(ctx, db.Rebind(`UPDATE urls SET author_id = ? WHERE domain = ? AND id = ?`), authorID, domain, id)
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	if rows == 0 {
+		return ErrURLNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresURLRepository) SetMetadata(ctx context.Context, domain string, id string, destination string, meta *Metadata) error {
+	log := ctxlogging.Get(ctx)
+	db := postgres.Conn(ctx, r.db)
+	_, err := db.ExecContext(ctx, db.Rebind(`UPDATE urls
+	SET meta_title = ?, meta_description = ?, meta_image = ?
+	WHERE domain = ? AND id = ? AND url = ?`), meta.Title, meta.Description, meta.Image, domain, id, destination)
+	if err != nil {
+		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
+	}
+
+	return nil
+}
+
 func (r *PostgresURLRepository) Create(ctx context.Context, url *URL) error {
 	log := ctxlogging.Get(ctx)
-	rows, err := r.db.NamedQueryContext(ctx, `INSERT INTO urls (id, author_id, url, name)
-	VALUES (:id, :author_id, :url, :name)
+	rows, err := sqlx.NamedQueryContext(ctx, postgres.Conn(ctx, r.db), `INSERT INTO urls (
+		domain, id, author_id, url, name,
+		meta_title, meta_description, meta_image,
+		og_title, og_description, og_image,
+		interstitial, expires_at, append_click_id
+	)
+	VALUES (
+		:domain, :id, :author_id, :url, :name,
+		:meta_title, :meta_description, :meta_image,
+		:og_title, :og_description, :og_image,
+		:interstitial, :expires_at, :append_click_id
+	)
 	RETURNING *
 	`, url)
 
 	if err != nil {
 		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
 	}
+	defer rows.Close()
 
 	if err = rows.Err(); err != nil {
 		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
@@ -89,9 +157,10 @@ func (r *PostgresURLRepository) Create(ctx context.Context, url *URL) error {
 	return nil
 }
 
-func (r *PostgresURLRepository) Delete(ctx context.Context, id string) error {
+func (r *PostgresURLRepository) Delete(ctx context.Context, domain string, id string) error {
 	log := ctxlogging.Get(ctx)
-	res, err := r.db.ExecContext(ctx, r.db.Rebind(`DELETE FROM urls WHERE id = ?`), id)
+	db := postgres.Conn(ctx, r.db)
+	res, err := db.ExecContext(ctx, db.Rebind(`DELETE FROM urls WHERE domain = ? AND id = ?`), domain, id)
 	if err != nil {
 		return r.errMap.MapAndLogUnmatched(postgres.TranslateError(err, log), log)
 	}
@@ -2,22 +2,104 @@ package url
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"roadmap.restapi/internal/database"
+	"roadmap.restapi/internal/outbox"
+)
+
+const (
+	TOPIC_TRANSFER_REQUESTED = "url.transfer.requested"
+	TOPIC_TRANSFER_ACCEPTED  = "url.transfer.accepted"
+	TOPIC_TRANSFER_DECLINED  = "url.transfer.declined"
+
+	TOPIC_METADATA_REQUESTED = "url.metadata.requested"
 )
 
 type UseCases struct {
-	repo URLRepository
+	tx          database.Transactor
+	repo        URLRepository
+	revisions   RevisionRepository
+	transfers   TransferRepository
+	outbox      outbox.Repository
+	domains     DomainVerifier
+	transferTTL time.Duration
 }
 
-func NewUseCases(repo URLRepository) *UseCases {
+func NewUseCases(
+	tx database.Transactor,
+	repo URLRepository,
+	revisions RevisionRepository,
+	transfers TransferRepository,
+	outbox outbox.Repository,
+	domains DomainVerifier,
+	transferTTL time.Duration,
+) *UseCases {
 	return &UseCases{
-		repo: repo,
+		tx:          tx,
+		repo:        repo,
+		revisions:   revisions,
+		transfers:   transfers,
+		outbox:      outbox,
+		domains:     domains,
+		transferTTL: transferTTL,
+	}
+}
+
+// Create stores a new URL. A URL on a custom domain can be created only by
+// the user who verified the domain. Preview metadata of the destination is
+// fetched later by the outbox relay, see MetadataSender.
+func (u *UseCases) Create(ctx context.Context, url *URL) error {
+	if url.Domain != "" {
+		allowed, err := u.domains.IsVerifiedOwner(ctx, url.AuthorID, url.Domain)
+		if err != nil {
+			return err
+		}
+
+		if !allowed {
+			return ErrDomainNotAllowed
+		}
+	}
+
+	return u.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := u.repo.Create(ctx, url); err != nil {
+			return err
+		}
+
+		return u.requestMetadata(ctx, url)
+	})
+}
+
+func (u *UseCases) requestMetadata(ctx context.Context, url *URL) error {
+	msg, err := outbox.NewMessage(TOPIC_METADATA_REQUESTED, metadataRequest{
+		URLDomain: url.Domain,
+		URLID:     url.ID,
+		URL:       url.URL,
+	})
+	if err != nil {
+		return err
+	}
+
+	return u.outbox.Add(ctx, msg)
+}
+
+// Get returns the URL if it belongs to authorID.
+func (u *UseCases) Get(ctx context.Context, authorID uuid.UUID, domain string, urlID string) (*URL, error) {
+	url, err := u.repo.ByID(ctx, domain, urlID)
+	if err != nil {
+		return nil, err
 	}
+
+	if url.AuthorID != authorID {
+		return nil, ErrUserIsNotAuthor
+	}
+
+	return url, nil
 }
 
-func (u *UseCases) Delete(ctx context.Context, authorID uuid.UUID, urlID string) error {
-	url, err := u.repo.ByID(ctx, urlID)
+func (u *UseCases) Delete(ctx context.Context, authorID uuid.UUID, domain string, urlID string) error {
+	url, err := u.repo.ByID(ctx, domain, urlID)
 	if err != nil {
 		return err
 	}
@@ -26,26 +108,315 @@ func (u *UseCases) Delete(ctx context.Context, authorID uuid.UUID, urlID string)
 		return ErrUserIsNotAuthor
 	}
 
-	if err = u.repo.Delete(ctx, urlID); err != nil {
+	if err = u.repo.Delete(ctx, domain, urlID); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// Update replaces the author editable fields of the stored URL with the
+// values of url and appends a revision holding both the previous and the new
+// values, both in one transaction. A new destination drops the fetched
+// metadata and requests it again. On success url holds the full updated URL.
 func (u *UseCases) Update(ctx context.Context, authorID uuid.UUID, url *URL) error {
-	url, err := u.repo.ByID(ctx, url.ID)
+	updated, err := u.update(ctx, authorID, url.Domain, url.ID, func(current *URL, updated *URL) error {
+		updated.URL = url.URL
+		updated.Name = url.Name
+		updated.OGTitle = url.OGTitle
+		updated.OGDescription = url.OGDescription
+		updated.OGImage = url.OGImage
+		updated.Interstitial = url.Interstitial
+		updated.ExpiresAt = url.ExpiresAt
+		updated.AppendClickID = url.AppendClickID
+		return nil
+	})
 	if err != nil {
 		return err
 	}
+	*url = *updated
+
+	return nil
+}
+
+// update applies edit to the URL locked for the transaction, so the values
+// recorded as old in the revision are the ones replaced.
+func (u *UseCases) update(
+	ctx context.Context,
+	authorID uuid.UUID,
+	domain string,
+	urlID string,
+	edit func(current *URL, updated *URL) error,
+) (*URL, error) {
+	var updated URL
+	err := u.tx.InTx(ctx, func(ctx context.Context) error {
+		current, err := u.repo.LockByID(ctx, domain, urlID)
+		if err != nil {
+			return err
+		}
+
+		if current.AuthorID != authorID {
+			return ErrUserIsNotAuthor
+		}
+
+		updated = *current
+		if err = edit(current, &updated); err != nil {
+			return err
+		}
+
+		if updated.URL != current.URL {
+			changedAt := time.Now().UTC()
+			updated.DestinationChangedAt = &changedAt
+			// metadata of the old destination must not be shown for the new one
+			updated.SetMetadata(&Metadata{})
+		}
+
+		if err = u.repo.Update(ctx, &updated); err != nil {
+			return err
+		}
+
+		if err = u.revisions.Create(ctx, NewRevision(authorID, current, &updated)); err != nil {
+			return err
+		}
+
+		if updated.URL != current.URL {
+			return u.requestMetadata(ctx, &updated)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+func (u *UseCases) Revisions(ctx context.Context, authorID uuid.UUID, domain string, urlID string) ([]Revision, error) {
+	if _, err := u.Get(ctx, authorID, domain, urlID); err != nil {
+		return nil, err
+	}
+
+	return u.revisions.ByURL(ctx, domain, urlID)
+}
+
+// Rollback restores the values URL had right before the given revision was
+// made. The rollback itself is recorded as a new revision as well.
+func (u *UseCases) Rollback(ctx context.Context, authorID uuid.UUID, domain string, urlID string, revisionID uuid.UUID) (*URL, error) {
+	revision, err := u.revisions.ByID(ctx, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if revision.URLDomain != domain || revision.URLID != urlID {
+		return nil, ErrRevisionNotFound
+	}
+
+	return u.update(ctx, authorID, domain, urlID, func(current *URL, updated *URL) error {
+		// revisions of a deleted URL that had the same id
+		if revision.CreatedAt.Before(current.CreatedAt) {
+			return ErrRevisionNotFound
+		}
+
+		revision.Restore(updated)
+		return nil
+	})
+}
+
+// Duplicate copies the URL with all of its settings under a new id on the
+// same domain. The copy belongs to the same author.
+func (u *UseCases) Duplicate(ctx context.Context, authorID uuid.UUID, domain string, urlID string, newID string) (*URL, error) {
+	var clone URL
+	err := u.tx.InTx(ctx, func(ctx context.Context) error {
+		source, err := u.repo.ByID(ctx, domain, urlID)
+		if err != nil {
+			return err
+		}
+
+		if source.AuthorID != authorID {
+			return ErrUserIsNotAuthor
+		}
+
+		clone = *source
+		clone.ID = newID
+		clone.CreatedAt = time.Time{}
+
+		return u.repo.Create(ctx, &clone)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &clone, nil
+}
+
+// Transfer offers the URL to recipientID. Any previous pending transfer of
+// the same URL is cancelled. A URL keeps its domain when transferred, so
+// URLs on a custom domain can only go to the user who verified it.
+func (u *UseCases) Transfer(ctx context.Context, authorID uuid.UUID, domain string, urlID string, recipientID uuid.UUID) (*Transfer, error) {
+	url, err := u.repo.ByID(ctx, domain, urlID)
+	if err != nil {
+		return nil, err
+	}
 
 	if url.AuthorID != authorID {
-		return ErrUserIsNotAuthor
+		return nil, ErrUserIsNotAuthor
+	}
+
+	if recipientID == authorID {
+		return nil, ErrTransferToSelf
+	}
+
+	if err = u.checkRecipientDomain(ctx, recipientID, domain); err != nil {
+		return nil, err
+	}
+
+	transfer := &Transfer{
+		URLDomain:  domain,
+		URLID:      urlID,
+		FromUserID: authorID,
+		ToUserID:   recipientID,
+		ExpiresAt:  time.Now().UTC().Add(u.transferTTL),
+	}
+
+	err = u.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := u.transfers.CancelPending(ctx, domain, urlID); err != nil {
+			return err
+		}
+
+		if err := u.transfers.Create(ctx, transfer); err != nil {
+			return err
+		}
+
+		return u.notifyTransfer(ctx, TOPIC_TRANSFER_REQUESTED, transfer)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// checkRecipientDomain returns ErrRecipientNotOwner unless recipientID may
+// own links on domain.
+func (u *UseCases) checkRecipientDomain(ctx context.Context, recipientID uuid.UUID, domain string) error {
+	if domain == "" {
+		return nil
 	}
 
-	if err = u.repo.Update(ctx, url); err != nil {
+	allowed, err := u.domains.IsVerifiedOwner(ctx, recipientID, domain)
+	if err != nil {
 		return err
 	}
 
+	if !allowed {
+		return ErrRecipientNotOwner
+	}
+
 	return nil
 }
+
+func (u *UseCases) IncomingTransfers(ctx context.Context, userID uuid.UUID) ([]Transfer, error) {
+	return u.transfers.IncomingPending(ctx, userID)
+}
+
+// AcceptTransfer makes the recipient the author of the transferred URL. The
+// URL is locked while the transfer is resolved, the author changed and the
+// notification queued in one transaction, edits of the previous author
+// meanwhile are kept.
+func (u *UseCases) AcceptTransfer(ctx context.Context, userID uuid.UUID, transferID uuid.UUID) (*URL, error) {
+	transfer, err := u.pendingTransfer(ctx, userID, transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the domain may have changed hands since the transfer was offered
+	if err = u.checkRecipientDomain(ctx, userID, transfer.URLDomain); err != nil {
+		return nil, err
+	}
+
+	var url *URL
+	err = u.tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		if url, err = u.repo.LockByID(ctx, transfer.URLDomain, transfer.URLID); err != nil {
+			return err
+		}
+
+		if url.AuthorID != transfer.FromUserID {
+			return ErrTransferNotPending
+		}
+
+		if err = u.transfers.Resolve(ctx, transfer.ID, TRANSFER_ACCEPTED); err != nil {
+			return err
+		}
+
+		if err = u.repo.SetAuthor(ctx, url.Domain, url.ID, userID); err != nil {
+			return err
+		}
+		url.AuthorID = userID
+
+		transfer.Status = TRANSFER_ACCEPTED
+		return u.notifyTransfer(ctx, TOPIC_TRANSFER_ACCEPTED, transfer)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return url, nil
+}
+
+func (u *UseCases) DeclineTransfer(ctx context.Context, userID uuid.UUID, transferID uuid.UUID) error {
+	transfer, err := u.pendingTransfer(ctx, userID, transferID)
+	if err != nil {
+		return err
+	}
+
+	return u.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := u.transfers.Resolve(ctx, transfer.ID, TRANSFER_DECLINED); err != nil {
+			return err
+		}
+
+		transfer.Status = TRANSFER_DECLINED
+		return u.notifyTransfer(ctx, TOPIC_TRANSFER_DECLINED, transfer)
+	})
+}
+
+// pendingTransfer loads a transfer addressed to userID that can still be
+// resolved. Transfers addressed to other users are reported as not found.
+func (u *UseCases) pendingTransfer(ctx context.Context, userID uuid.UUID, transferID uuid.UUID) (*Transfer, error) {
+	transfer, err := u.transfers.ByID(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	if transfer.ToUserID != userID {
+		return nil, ErrTransferNotFound
+	}
+
+	if transfer.Status != TRANSFER_PENDING {
+		return nil, ErrTransferNotPending
+	}
+
+	if transfer.IsExpired(time.Now().UTC()) {
+		return nil, ErrTransferExpired
+	}
+
+	return transfer, nil
+}
+
+func (u *UseCases) notifyTransfer(ctx context.Context, topic string, transfer *Transfer) error {
+	msg, err := outbox.NewMessage(topic, map[string]any{
+		"transfer_id":  transfer.ID,
+		"url_domain":   transfer.URLDomain,
+		"url_id":       transfer.URLID,
+		"from_user_id": transfer.FromUserID,
+		"to_user_id":   transfer.ToUserID,
+		"status":       transfer.Status,
+		"expires_at":   transfer.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return u.outbox.Add(ctx, msg)
+}
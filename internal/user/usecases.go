@@ -45,3 +45,41 @@ func (u *UseCases) ByEmailAndPassword(ctx context.Context, email string, passwor
 
 	return user, nil
 }
+
+func (u *UseCases) ChangePassword(ctx context.Context, id uuid.UUID, current string, password string) error {
+	user, err := u.repo.ByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !u.hasher.Check(current, user.PasswordHash) {
+		return ErrPasswordIncorrect
+	}
+
+	user.PasswordHash = u.hasher.Hash(password)
+
+	return u.repo.Update(ctx, user)
+}
+
+// CheckPassword returns ErrPasswordIncorrect unless password is the one of
+// the user id.
+func (u *UseCases) CheckPassword(ctx context.Context, id uuid.UUID, password string) error {
+	user, err := u.repo.ByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !u.hasher.Check(password, user.PasswordHash) {
+		return ErrPasswordIncorrect
+	}
+
+	return nil
+}
+
+func (u *UseCases) DeleteAccount(ctx context.Context, id uuid.UUID, password string) error {
+	if err := u.CheckPassword(ctx, id, password); err != nil {
+		return err
+	}
+
+	return u.repo.Delete(ctx, id)
+}
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+CREATE TABLE url_revisions (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    url_id VARCHAR NOT NULL REFERENCES urls(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    author_id UUID REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+    old_url VARCHAR NOT NULL,
+    new_url VARCHAR NOT NULL,
+    old_name VARCHAR NOT NULL DEFAULT '',
+    new_name VARCHAR NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY(id)
+);
+
+CREATE INDEX url_revisions_url_id_idx ON url_revisions (url_id, created_at);
+
+CREATE FUNCTION url_revisions_immutable() RETURNS trigger AS $$
+BEGIN
+    -- only cascades on url_id / author_id are allowed to touch a revision
+    IF NEW.id <> OLD.id
+        OR NEW.old_url <> OLD.old_url
+        OR NEW.new_url <> OLD.new_url
+        OR NEW.old_name <> OLD.old_name
+        OR NEW.new_name <> OLD.new_name
+        OR NEW.created_at IS DISTINCT FROM OLD.created_at
+    THEN
+        RAISE EXCEPTION 'url revisions are immutable';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER url_revisions_no_update
+    BEFORE UPDATE ON url_revisions
+    FOR EACH ROW EXECUTE FUNCTION url_revisions_immutable();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE url_revisions;
+DROP FUNCTION url_revisions_immutable();
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+CREATE TABLE outbox_messages (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    topic VARCHAR NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    processed_at TIMESTAMP,
+
+    PRIMARY KEY(id)
+);
+
+CREATE INDEX outbox_messages_pending_idx ON outbox_messages (created_at) WHERE processed_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE outbox_messages;
+-- +goose StatementEnd
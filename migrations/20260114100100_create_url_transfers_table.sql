@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+CREATE TABLE url_transfers (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    url_id VARCHAR NOT NULL REFERENCES urls(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    from_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    to_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    status VARCHAR NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'accepted', 'declined', 'cancelled')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    resolved_at TIMESTAMP,
+
+    PRIMARY KEY(id)
+);
+
+CREATE INDEX url_transfers_to_user_idx ON url_transfers (to_user_id) WHERE status = 'pending';
+CREATE INDEX url_transfers_url_idx ON url_transfers (url_id) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE url_transfers;
+-- +goose StatementEnd
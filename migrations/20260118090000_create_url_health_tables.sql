@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+CREATE TABLE url_health (
+    url_id VARCHAR NOT NULL REFERENCES urls(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    status_code INT NOT NULL DEFAULT 0,
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+    error VARCHAR NOT NULL DEFAULT '',
+    broken BOOLEAN NOT NULL DEFAULT FALSE,
+    consecutive_failures INT NOT NULL DEFAULT 0,
+    checked_at TIMESTAMP NOT NULL,
+    next_check_at TIMESTAMP NOT NULL,
+
+    PRIMARY KEY(url_id)
+);
+
+CREATE INDEX url_health_next_check_idx ON url_health (next_check_at);
+
+CREATE TABLE url_health_checks (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    url_id VARCHAR NOT NULL REFERENCES urls(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    status_code INT NOT NULL DEFAULT 0,
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+    error VARCHAR NOT NULL DEFAULT '',
+    broken BOOLEAN NOT NULL DEFAULT FALSE,
+    checked_at TIMESTAMP NOT NULL,
+
+    PRIMARY KEY(id)
+);
+
+CREATE INDEX url_health_checks_url_idx ON url_health_checks (url_id, checked_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE url_health_checks;
+DROP TABLE url_health;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+ALTER TABLE urls
+    ADD COLUMN meta_title VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN meta_description VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN meta_image VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN og_title VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN og_description VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN og_image VARCHAR NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE urls
+    DROP COLUMN meta_title,
+    DROP COLUMN meta_description,
+    DROP COLUMN meta_image,
+    DROP COLUMN og_title,
+    DROP COLUMN og_description,
+    DROP COLUMN og_image;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+ALTER TABLE urls
+    ADD COLUMN interstitial BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN destination_changed_at TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE urls
+    DROP COLUMN interstitial,
+    DROP COLUMN destination_changed_at;
+-- +goose StatementEnd
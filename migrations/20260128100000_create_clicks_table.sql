@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+CREATE TABLE clicks (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    url_id VARCHAR NOT NULL REFERENCES urls(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    clicked_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    country_code VARCHAR,
+    referer VARCHAR,
+
+    PRIMARY KEY(id)
+);
+
+CREATE INDEX clicks_url_clicked_at_idx ON clicks (url_id, clicked_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE clicks;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+CREATE TABLE pages (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    handle VARCHAR NOT NULL UNIQUE,
+    author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    title VARCHAR NOT NULL DEFAULT '',
+    description VARCHAR NOT NULL DEFAULT '',
+    avatar_url VARCHAR NOT NULL DEFAULT '',
+    theme VARCHAR NOT NULL DEFAULT 'light' CHECK (theme IN ('light', 'dark')),
+    accent_color VARCHAR NOT NULL DEFAULT '#2456d6',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY(id)
+);
+
+CREATE TABLE page_links (
+    page_id UUID NOT NULL REFERENCES pages(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    url_id VARCHAR NOT NULL REFERENCES urls(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    position INT NOT NULL,
+    title VARCHAR NOT NULL DEFAULT '',
+    icon VARCHAR NOT NULL DEFAULT '',
+
+    PRIMARY KEY(page_id, url_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE page_links;
+DROP TABLE pages;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+CREATE TABLE domains (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    name VARCHAR NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    token VARCHAR NOT NULL,
+    verified_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY(id),
+    UNIQUE(user_id, name)
+);
+
+-- several users may claim a domain, only one of them can prove ownership
+CREATE UNIQUE INDEX domains_verified_name_idx ON domains (name) WHERE verified_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE domains;
+-- +goose StatementEnd
@@ -0,0 +1,100 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- links created before custom domains live on the default domain ''
+ALTER TABLE urls ADD COLUMN domain VARCHAR NOT NULL DEFAULT '';
+
+ALTER TABLE url_revisions DROP CONSTRAINT url_revisions_url_id_fkey;
+ALTER TABLE url_transfers DROP CONSTRAINT url_transfers_url_id_fkey;
+ALTER TABLE url_health DROP CONSTRAINT url_health_url_id_fkey;
+ALTER TABLE url_health_checks DROP CONSTRAINT url_health_checks_url_id_fkey;
+ALTER TABLE page_links DROP CONSTRAINT page_links_url_id_fkey;
+ALTER TABLE clicks DROP CONSTRAINT clicks_url_id_fkey;
+
+ALTER TABLE urls DROP CONSTRAINT urls_pkey;
+ALTER TABLE urls ADD PRIMARY KEY (domain, id);
+
+ALTER TABLE url_revisions ADD COLUMN url_domain VARCHAR NOT NULL DEFAULT '';
+ALTER TABLE url_revisions ADD FOREIGN KEY (url_domain, url_id)
+    REFERENCES urls(domain, id) ON DELETE CASCADE ON UPDATE CASCADE;
+DROP INDEX url_revisions_url_id_idx;
+CREATE INDEX url_revisions_url_id_idx ON url_revisions (url_domain, url_id, created_at);
+
+ALTER TABLE url_transfers ADD COLUMN url_domain VARCHAR NOT NULL DEFAULT '';
+ALTER TABLE url_transfers ADD FOREIGN KEY (url_domain, url_id)
+    REFERENCES urls(domain, id) ON DELETE CASCADE ON UPDATE CASCADE;
+DROP INDEX url_transfers_url_idx;
+CREATE INDEX url_transfers_url_idx ON url_transfers (url_domain, url_id) WHERE status = 'pending';
+
+ALTER TABLE url_health ADD COLUMN url_domain VARCHAR NOT NULL DEFAULT '';
+ALTER TABLE url_health ADD FOREIGN KEY (url_domain, url_id)
+    REFERENCES urls(domain, id) ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE url_health DROP CONSTRAINT url_health_pkey;
+ALTER TABLE url_health ADD PRIMARY KEY (url_domain, url_id);
+
+ALTER TABLE url_health_checks ADD COLUMN url_domain VARCHAR NOT NULL DEFAULT '';
+ALTER TABLE url_health_checks ADD FOREIGN KEY (url_domain, url_id)
+    REFERENCES urls(domain, id) ON DELETE CASCADE ON UPDATE CASCADE;
+DROP INDEX url_health_checks_url_idx;
+CREATE INDEX url_health_checks_url_idx ON url_health_checks (url_domain, url_id, checked_at);
+
+ALTER TABLE page_links ADD COLUMN url_domain VARCHAR NOT NULL DEFAULT '';
+ALTER TABLE page_links ADD FOREIGN KEY (url_domain, url_id)
+    REFERENCES urls(domain, id) ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE page_links DROP CONSTRAINT page_links_pkey;
+ALTER TABLE page_links ADD PRIMARY KEY (page_id, url_domain, url_id);
+
+ALTER TABLE clicks ADD COLUMN url_domain VARCHAR NOT NULL DEFAULT '';
+ALTER TABLE clicks ADD FOREIGN KEY (url_domain, url_id)
+    REFERENCES urls(domain, id) ON DELETE CASCADE ON UPDATE CASCADE;
+DROP INDEX clicks_url_clicked_at_idx;
+CREATE INDEX clicks_url_clicked_at_idx ON clicks (url_domain, url_id, clicked_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- links of custom domains can not be kept once ids are globally unique again
+DELETE FROM urls WHERE domain <> '';
+
+ALTER TABLE clicks DROP CONSTRAINT clicks_url_domain_url_id_fkey;
+ALTER TABLE page_links DROP CONSTRAINT page_links_url_domain_url_id_fkey;
+ALTER TABLE url_health_checks DROP CONSTRAINT url_health_checks_url_domain_url_id_fkey;
+ALTER TABLE url_health DROP CONSTRAINT url_health_url_domain_url_id_fkey;
+ALTER TABLE url_transfers DROP CONSTRAINT url_transfers_url_domain_url_id_fkey;
+ALTER TABLE url_revisions DROP CONSTRAINT url_revisions_url_domain_url_id_fkey;
+
+ALTER TABLE urls DROP CONSTRAINT urls_pkey;
+ALTER TABLE urls ADD PRIMARY KEY (id);
+ALTER TABLE urls DROP COLUMN domain;
+
+DROP INDEX clicks_url_clicked_at_idx;
+ALTER TABLE clicks DROP COLUMN url_domain;
+ALTER TABLE clicks ADD FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE ON UPDATE CASCADE;
+CREATE INDEX clicks_url_clicked_at_idx ON clicks (url_id, clicked_at);
+
+ALTER TABLE page_links DROP CONSTRAINT page_links_pkey;
+ALTER TABLE page_links DROP COLUMN url_domain;
+ALTER TABLE page_links ADD PRIMARY KEY (page_id, url_id);
+ALTER TABLE page_links ADD FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+DROP INDEX url_health_checks_url_idx;
+ALTER TABLE url_health_checks DROP COLUMN url_domain;
+ALTER TABLE url_health_checks ADD FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE ON UPDATE CASCADE;
+CREATE INDEX url_health_checks_url_idx ON url_health_checks (url_id, checked_at);
+
+ALTER TABLE url_health DROP CONSTRAINT url_health_pkey;
+ALTER TABLE url_health DROP COLUMN url_domain;
+ALTER TABLE url_health ADD PRIMARY KEY (url_id);
+ALTER TABLE url_health ADD FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+DROP INDEX url_transfers_url_idx;
+ALTER TABLE url_transfers DROP COLUMN url_domain;
+ALTER TABLE url_transfers ADD FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE ON UPDATE CASCADE;
+CREATE INDEX url_transfers_url_idx ON url_transfers (url_id) WHERE status = 'pending';
+
+DROP INDEX url_revisions_url_id_idx;
+ALTER TABLE url_revisions DROP COLUMN url_domain;
+ALTER TABLE url_revisions ADD FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE ON UPDATE CASCADE;
+CREATE INDEX url_revisions_url_id_idx ON url_revisions (url_id, created_at);
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+CREATE TABLE fallback_pages (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    -- '' is the default of the user, otherwise a verified custom domain
+    domain VARCHAR NOT NULL DEFAULT '',
+    mode VARCHAR NOT NULL CHECK (mode IN ('not_found', 'redirect', 'template')),
+    redirect_url VARCHAR NOT NULL DEFAULT '',
+    template TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY(id),
+    UNIQUE(user_id, domain)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE fallback_pages;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+ALTER TABLE clicks
+    ADD COLUMN device_type VARCHAR NOT NULL DEFAULT 'other',
+    ADD COLUMN os_family VARCHAR NOT NULL DEFAULT 'Other',
+    ADD COLUMN browser_family VARCHAR NOT NULL DEFAULT 'Other';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE clicks
+    DROP COLUMN device_type,
+    DROP COLUMN os_family,
+    DROP COLUMN browser_family;
+-- +goose StatementEnd
@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+CREATE TABLE click_rollups_hourly (
+    url_domain VARCHAR NOT NULL,
+    url_id VARCHAR NOT NULL,
+    bucket TIMESTAMP NOT NULL,
+    dimension VARCHAR NOT NULL,
+    key VARCHAR NOT NULL,
+    is_bot BOOLEAN NOT NULL,
+    clicks INTEGER NOT NULL,
+
+    PRIMARY KEY(url_domain, url_id, bucket, dimension, key, is_bot),
+    FOREIGN KEY(url_domain, url_id) REFERENCES urls(domain, id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE click_rollups_daily (
+    url_domain VARCHAR NOT NULL,
+    url_id VARCHAR NOT NULL,
+    bucket TIMESTAMP NOT NULL,
+    dimension VARCHAR NOT NULL,
+    key VARCHAR NOT NULL,
+    is_bot BOOLEAN NOT NULL,
+    clicks INTEGER NOT NULL,
+
+    PRIMARY KEY(url_domain, url_id, bucket, dimension, key, is_bot),
+    FOREIGN KEY(url_domain, url_id) REFERENCES urls(domain, id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- rollups cover everything before rolled_up_to
+CREATE TABLE click_rollup_watermarks (
+    granularity VARCHAR NOT NULL,
+    rolled_up_to TIMESTAMP NOT NULL,
+
+    PRIMARY KEY(granularity)
+);
+
+CREATE INDEX clicks_clicked_at_idx ON clicks (clicked_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP INDEX clicks_clicked_at_idx;
+DROP TABLE click_rollup_watermarks;
+DROP TABLE click_rollups_daily;
+DROP TABLE click_rollups_hourly;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+CREATE TABLE privacy_settings (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    capture_referer BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY(user_id)
+);
+
+-- clicks of visitors refusing tracking are counted without any details
+ALTER TABLE clicks
+    ALTER COLUMN device_type DROP NOT NULL,
+    ALTER COLUMN os_family DROP NOT NULL,
+    ALTER COLUMN browser_family DROP NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+UPDATE clicks SET device_type = 'other' WHERE device_type IS NULL;
+UPDATE clicks SET os_family = 'Other' WHERE os_family IS NULL;
+UPDATE clicks SET browser_family = 'Other' WHERE browser_family IS NULL;
+ALTER TABLE clicks
+    ALTER COLUMN device_type SET NOT NULL,
+    ALTER COLUMN os_family SET NOT NULL,
+    ALTER COLUMN browser_family SET NOT NULL;
+
+DROP TABLE privacy_settings;
+-- +goose StatementEnd
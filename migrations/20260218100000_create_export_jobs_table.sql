@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+CREATE TABLE export_jobs (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- empty url_id exports all urls of the user
+    url_domain VARCHAR NOT NULL DEFAULT '',
+    url_id VARCHAR NOT NULL DEFAULT '',
+    granularity VARCHAR NOT NULL CHECK (granularity IN ('raw', 'hourly', 'daily')),
+    format VARCHAR NOT NULL CHECK (format IN ('csv', 'ndjson')),
+    columns VARCHAR NOT NULL,
+    from_time TIMESTAMP NOT NULL,
+    to_time TIMESTAMP NOT NULL,
+    status VARCHAR NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'done', 'failed')),
+    row_count BIGINT NOT NULL DEFAULT 0,
+    error VARCHAR,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP,
+
+    PRIMARY KEY(id)
+);
+
+CREATE INDEX export_jobs_user_idx ON export_jobs (user_id, created_at);
+CREATE INDEX export_jobs_pending_idx ON export_jobs (created_at) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE export_jobs;
+-- +goose StatementEnd
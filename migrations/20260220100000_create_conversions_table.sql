@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+ALTER TABLE urls ADD COLUMN append_click_id BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- conversions outlive the raw clicks they are attributed to, so the click is
+-- copied instead of referenced
+CREATE TABLE conversions (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    click_id UUID NOT NULL UNIQUE,
+    url_domain VARCHAR NOT NULL DEFAULT '',
+    url_id VARCHAR NOT NULL,
+    clicked_at TIMESTAMP NOT NULL,
+    converted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    value NUMERIC(14, 2),
+
+    PRIMARY KEY(id),
+    FOREIGN KEY (url_domain, url_id) REFERENCES urls(domain, id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX conversions_url_clicked_at_idx ON conversions (url_domain, url_id, clicked_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE conversions;
+ALTER TABLE urls DROP COLUMN append_click_id;
+-- +goose StatementEnd
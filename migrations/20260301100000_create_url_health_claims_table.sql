@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- a claim keeps other instances from probing a link while one is checking it;
+-- it is dropped with the check result or lapses if the instance dies
+CREATE TABLE url_health_claims (
+    url_domain VARCHAR NOT NULL DEFAULT '',
+    url_id VARCHAR NOT NULL,
+    claimed_until TIMESTAMP NOT NULL,
+
+    PRIMARY KEY(url_domain, url_id),
+    FOREIGN KEY (url_domain, url_id) REFERENCES urls(domain, id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE url_health_claims;
+-- +goose StatementEnd
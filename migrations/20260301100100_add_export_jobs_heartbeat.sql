@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- a running job whose worker stopped beating is claimed again
+ALTER TABLE export_jobs
+    ADD COLUMN started_at TIMESTAMP,
+    ADD COLUMN heartbeat_at TIMESTAMP;
+
+CREATE INDEX export_jobs_running_idx ON export_jobs (heartbeat_at) WHERE status = 'running';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP INDEX export_jobs_running_idx;
+ALTER TABLE export_jobs
+    DROP COLUMN started_at,
+    DROP COLUMN heartbeat_at;
+-- +goose StatementEnd
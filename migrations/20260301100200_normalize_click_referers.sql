@@ -0,0 +1,102 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- referer_host mirrors analytics.RefererNormalizer.Normalize: the lower case
+-- host without www. or m., page referers and '' as they are, NULL for
+-- referers without a usable host
+CREATE FUNCTION pg_temp.referer_host(raw VARCHAR) RETURNS VARCHAR AS $$
+    SELECT CASE
+        WHEN r = '' OR r LIKE 'page:%' THEN r
+        WHEN h !~ '[.:]' OR length(h) > 253 OR h !~ '^[a-z0-9.:_-]+$' THEN NULL
+        ELSE regexp_replace(h, '^(www|m)\.(?=.*\.)', '')
+    END
+    FROM (SELECT lower(trim(raw)) AS r) AS lowered,
+    LATERAL (SELECT regexp_replace(regexp_replace(regexp_replace(r,
+        '^([a-z][a-z0-9+.-]*:)?//', ''),
+        '[/?#].*$', ''),
+        '^.*@', '') AS authority) AS authorities,
+    LATERAL (SELECT rtrim(CASE
+        WHEN authority LIKE '[%' THEN substring(authority FROM '^\[([^]]*)\]')
+        ELSE regexp_replace(authority, ':[0-9]*$', '')
+    END, '.') AS h) AS hosts
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- full referers stored before normalization may identify the visitor
+UPDATE clicks SET referer = pg_temp.referer_host(referer)
+WHERE referer IS NOT NULL AND referer IS DISTINCT FROM pg_temp.referer_host(referer);
+
+-- direct visits used to be stored as NULL and are '' now. NULL is left for
+-- clicks without details and authors not capturing referers.
+CREATE TEMPORARY TABLE direct_clicks (
+    url_domain VARCHAR NOT NULL,
+    url_id VARCHAR NOT NULL,
+    clicked_at TIMESTAMP NOT NULL,
+    is_bot BOOLEAN NOT NULL
+);
+
+WITH updated AS (
+    UPDATE clicks SET referer = ''
+    FROM urls
+    LEFT JOIN privacy_settings ON privacy_settings.user_id = urls.author_id
+    WHERE clicks.url_domain = urls.domain AND clicks.url_id = urls.id
+        AND clicks.referer IS NULL
+        AND clicks.device_type IS NOT NULL
+        AND COALESCE(privacy_settings.capture_referer, TRUE)
+    RETURNING clicks.url_domain, clicks.url_id, clicks.clicked_at, clicks.is_bot
+)
+INSERT INTO direct_clicks SELECT * FROM updated;
+
+-- rollup keys are normalized the same way, keys that end up equal are merged
+CREATE TEMPORARY TABLE referer_rollups_hourly AS
+SELECT url_domain, url_id, bucket, dimension, pg_temp.referer_host(key) AS key, is_bot, SUM(clicks) AS clicks
+FROM click_rollups_hourly
+WHERE dimension = 'referer'
+GROUP BY 1, 2, 3, 4, 5, 6;
+
+DELETE FROM click_rollups_hourly WHERE dimension = 'referer';
+
+INSERT INTO click_rollups_hourly (url_domain, url_id, bucket, dimension, key, is_bot, clicks)
+SELECT url_domain, url_id, bucket, dimension, key, is_bot, clicks
+FROM referer_rollups_hourly
+WHERE key IS NOT NULL;
+
+CREATE TEMPORARY TABLE referer_rollups_daily AS
+SELECT url_domain, url_id, bucket, dimension, pg_temp.referer_host(key) AS key, is_bot, SUM(clicks) AS clicks
+FROM click_rollups_daily
+WHERE dimension = 'referer'
+GROUP BY 1, 2, 3, 4, 5, 6;
+
+DELETE FROM click_rollups_daily WHERE dimension = 'referer';
+
+INSERT INTO click_rollups_daily (url_domain, url_id, bucket, dimension, key, is_bot, clicks)
+SELECT url_domain, url_id, bucket, dimension, key, is_bot, clicks
+FROM referer_rollups_daily
+WHERE key IS NOT NULL;
+
+-- direct visits already rolled up were skipped for their NULL key. Clicks
+-- purged since can not be counted again.
+INSERT INTO click_rollups_hourly (url_domain, url_id, bucket, dimension, key, is_bot, clicks)
+SELECT url_domain, url_id, date_trunc('hour', clicked_at), 'referer', '', is_bot, COUNT(*)
+FROM direct_clicks
+WHERE clicked_at < (SELECT rolled_up_to FROM click_rollup_watermarks WHERE granularity = 'hourly')
+GROUP BY 1, 2, 3, 4, 5, 6
+ON CONFLICT (url_domain, url_id, bucket, dimension, key, is_bot)
+DO UPDATE SET clicks = click_rollups_hourly.clicks + EXCLUDED.clicks;
+
+INSERT INTO click_rollups_daily (url_domain, url_id, bucket, dimension, key, is_bot, clicks)
+SELECT url_domain, url_id, date_trunc('day', clicked_at), 'referer', '', is_bot, COUNT(*)
+FROM direct_clicks
+WHERE clicked_at < (SELECT rolled_up_to FROM click_rollup_watermarks WHERE granularity = 'daily')
+GROUP BY 1, 2, 3, 4, 5, 6
+ON CONFLICT (url_domain, url_id, bucket, dimension, key, is_bot)
+DO UPDATE SET clicks = click_rollups_daily.clicks + EXCLUDED.clicks;
+
+DROP TABLE direct_clicks, referer_rollups_hourly, referer_rollups_daily;
+DROP FUNCTION pg_temp.referer_host(VARCHAR);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- stripped paths and queries are gone for good, there is nothing to restore
+-- +goose StatementEnd
@@ -0,0 +1,77 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- the history outlives the link, revisions of a link created again under
+-- the same id are told apart by the creation time of the link
+ALTER TABLE url_revisions DROP CONSTRAINT url_revisions_url_domain_url_id_fkey;
+
+-- revisions keep every author editable value, older revisions only hold the
+-- destination and the name and have has_settings unset
+ALTER TABLE url_revisions
+    ADD COLUMN has_settings BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN old_og_title VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN new_og_title VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN old_og_description VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN new_og_description VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN old_og_image VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN new_og_image VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN old_interstitial BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN new_interstitial BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN old_expires_at TIMESTAMP,
+    ADD COLUMN new_expires_at TIMESTAMP,
+    ADD COLUMN old_append_click_id BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN new_append_click_id BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE OR REPLACE FUNCTION url_revisions_immutable() RETURNS trigger AS $$
+BEGIN
+    -- only cascades on author_id are allowed to touch a revision
+    IF (to_jsonb(NEW) - 'author_id') <> (to_jsonb(OLD) - 'author_id') THEN
+        RAISE EXCEPTION 'url revisions are immutable';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+CREATE OR REPLACE FUNCTION url_revisions_immutable() RETURNS trigger AS $$
+BEGIN
+    -- only cascades on url_id / author_id are allowed to touch a revision
+    IF NEW.id <> OLD.id
+        OR NEW.old_url <> OLD.old_url
+        OR NEW.new_url <> OLD.new_url
+        OR NEW.old_name <> OLD.old_name
+        OR NEW.new_name <> OLD.new_name
+        OR NEW.created_at IS DISTINCT FROM OLD.created_at
+    THEN
+        RAISE EXCEPTION 'url revisions are immutable';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE url_revisions
+    DROP COLUMN has_settings,
+    DROP COLUMN old_og_title,
+    DROP COLUMN new_og_title,
+    DROP COLUMN old_og_description,
+    DROP COLUMN new_og_description,
+    DROP COLUMN old_og_image,
+    DROP COLUMN new_og_image,
+    DROP COLUMN old_interstitial,
+    DROP COLUMN new_interstitial,
+    DROP COLUMN old_expires_at,
+    DROP COLUMN new_expires_at,
+    DROP COLUMN old_append_click_id,
+    DROP COLUMN new_append_click_id;
+
+-- the foreign key can not hold the history of deleted links
+DELETE FROM url_revisions r
+WHERE NOT EXISTS (SELECT 1 FROM urls WHERE urls.domain = r.url_domain AND urls.id = r.url_id);
+ALTER TABLE url_revisions ADD FOREIGN KEY (url_domain, url_id)
+    REFERENCES urls(domain, id) ON DELETE CASCADE ON UPDATE CASCADE;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- a relay claims messages for the time it takes to send them instead of
+-- keeping them locked in an open transaction
+ALTER TABLE outbox_messages ADD COLUMN claimed_until TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE outbox_messages DROP COLUMN claimed_until;
+-- +goose StatementEnd
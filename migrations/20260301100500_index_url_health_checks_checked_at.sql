@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- the history is pruned by age across all links
+CREATE INDEX url_health_checks_checked_at_idx ON url_health_checks (checked_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP INDEX url_health_checks_checked_at_idx;
+-- +goose StatementEnd
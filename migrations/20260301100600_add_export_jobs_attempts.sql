@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- every claim of a job is a new attempt, its number fences off workers that
+-- lost the job to another one
+ALTER TABLE export_jobs ADD COLUMN attempts INT NOT NULL DEFAULT 0;
+UPDATE export_jobs SET attempts = 1 WHERE started_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE export_jobs DROP COLUMN attempts;
+-- +goose StatementEnd
@@ -0,0 +1,86 @@
+package integration
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/analytics"
+)
+
+func TestClickStream_PublishSubscribe(t *testing.T) {
+	db := RedisConnection(t)
+	defer RedisClose(t, db)
+
+	ctx := context.Background()
+	stream := analytics.NewRedisClickStream(db)
+	urlID := uuid.NewString()
+
+	events, unsubscribe, err := stream.Subscribe(ctx, "", urlID)
+	if err != nil {
+		t.Fatalf("error on subscribe: err: %s", err.Error())
+	}
+	defer unsubscribe()
+
+	country, mobile := "DE", analytics.DEVICE_MOBILE
+	other := analytics.ClickEvent{URLID: uuid.NewString(), ClickedAt: time.Now().UTC()}
+	if err := stream.Publish(ctx, other); err != nil {
+		t.Fatalf("error on publish: err: %s", err.Error())
+	}
+	sent := analytics.ClickEvent{URLID: urlID, ClickedAt: time.Now().UTC(), CountryCode: &country, Device: &mobile}
+	if err := stream.Publish(ctx, sent); err != nil {
+		t.Fatalf("error on publish: err: %s", err.Error())
+	}
+
+	select {
+	case got := <-events:
+		if got.URLID != urlID || got.CountryCode == nil || *got.CountryCode != country || got.Device == nil || *got.Device != mobile {
+			t.Errorf("unexpected event: %+v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+	}
+}
+
+func TestClickStream_DropsForSlowSubscriber(t *testing.T) {
+	db := RedisConnection(t)
+	defer RedisClose(t, db)
+
+	ctx := context.Background()
+	stream := analytics.NewRedisClickStream(db)
+	urlID := uuid.NewString()
+
+	events, unsubscribe, err := stream.Subscribe(ctx, "", urlID)
+	if err != nil {
+		t.Fatalf("error on subscribe: err: %s", err.Error())
+	}
+
+	// nobody reads events, publishing must not block on the subscriber
+	for range 500 {
+		if err := stream.Publish(ctx, analytics.ClickEvent{URLID: urlID}); err != nil {
+			t.Fatalf("error on publish: err: %s", err.Error())
+		}
+	}
+
+	// the buffer fills up in delivery order, everything after it is dropped
+	deadline := time.After(5 * time.Second)
+	for len(events) < cap(events) {
+		select {
+		case <-deadline:
+			t.Fatalf("expected the subscriber buffer to fill up, got %d events", len(events))
+		default:
+			runtime.Gosched()
+		}
+	}
+	unsubscribe()
+
+	received := 0
+	for range events {
+		received++
+	}
+	if received != cap(events) {
+		t.Errorf("expected a slow subscriber to get only the buffered events, got %d", received)
+	}
+}
@@ -0,0 +1,47 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/analytics"
+)
+
+func TestDashboardCache_GetSet(t *testing.T) {
+	db := RedisConnection(t)
+	defer RedisClose(t, db)
+
+	ctx := context.Background()
+	cache := analytics.NewRedisDashboardCache(db, time.Minute)
+	userID := uuid.New()
+	to := analytics.Day(time.Now()).AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -7)
+
+	if _, ok, err := cache.Get(ctx, userID, from, to); err != nil || ok {
+		t.Fatalf("expected cache miss, got ok: %v err: %v", ok, err)
+	}
+
+	dashboard := &analytics.Dashboard{
+		From:        from,
+		To:          to,
+		TotalClicks: 3,
+		TopLinks:    []analytics.ClicksByLink{{URLID: "abc", Clicks: 3}},
+	}
+	if err := cache.Set(ctx, userID, dashboard); err != nil {
+		t.Fatalf("error on set: err: %s", err.Error())
+	}
+
+	cached, ok, err := cache.Get(ctx, userID, from, to)
+	if err != nil || !ok {
+		t.Fatalf("expected cache hit, got ok: %v err: %v", ok, err)
+	}
+	if cached.TotalClicks != 3 || len(cached.TopLinks) != 1 || cached.TopLinks[0].URLID != "abc" {
+		t.Errorf("unexpected cached dashboard: %+v", cached)
+	}
+
+	if _, ok, _ := cache.Get(ctx, userID, from.AddDate(0, 0, 1), to); ok {
+		t.Errorf("expected other periods to miss")
+	}
+}
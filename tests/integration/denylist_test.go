@@ -0,0 +1,96 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/token"
+)
+
+func TestRedisDenylist_DenyAndRebuild(t *testing.T) {
+	db := RedisConnection(t)
+	defer RedisClose(t, db)
+
+	ctx := context.Background()
+	denylist := token.NewRedisDenylist(db, 1000)
+	denied, expired := uuid.New(), uuid.New()
+
+	if err := denylist.Deny(ctx, denied, time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("error on deny: err: %s", err.Error())
+	}
+	if err := denylist.Deny(ctx, expired, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("error on deny: err: %s", err.Error())
+	}
+
+	// a fresh instance loads the ids denied so far from Redis
+	loaded := token.NewRedisDenylist(db, 1000)
+	if err := loaded.Rebuild(ctx); err != nil {
+		t.Fatalf("error on rebuild: err: %s", err.Error())
+	}
+
+	for _, d := range []*token.RedisDenylist{denylist, loaded} {
+		if ok, err := d.Denied(ctx, uuid.New(), denied); err != nil || !ok {
+			t.Errorf("expected the id to be denied, got %v, %v", ok, err)
+		}
+		if ok, err := d.Denied(ctx, expired, uuid.New()); err != nil || ok {
+			t.Errorf("expected ids of expired or unknown tokens to pass, got %v, %v", ok, err)
+		}
+	}
+}
+
+func TestRedisDenylist_RebuildTrimsExpiredIDs(t *testing.T) {
+	db := RedisConnection(t)
+	defer RedisClose(t, db)
+
+	ctx := context.Background()
+	denylist := token.NewRedisDenylist(db, 1000)
+	id := uuid.New()
+
+	if err := denylist.Deny(ctx, id, time.Now().Add(100*time.Millisecond)); err != nil {
+		t.Fatalf("error on deny: err: %s", err.Error())
+	}
+	time.Sleep(150 * time.Millisecond)
+
+	if ok, err := denylist.Denied(ctx, id); err != nil || ok {
+		t.Errorf("expected the expired id to pass, got %v, %v", ok, err)
+	}
+
+	if err := denylist.Rebuild(ctx); err != nil {
+		t.Fatalf("error on rebuild: err: %s", err.Error())
+	}
+	if count, err := db.ZCard(ctx, "token-denied").Result(); err != nil || count != 0 {
+		t.Errorf("expected expired ids to be trimmed, got %d, %v", count, err)
+	}
+}
+
+func TestRedisDenylist_SyncsInstances(t *testing.T) {
+	db := RedisConnection(t)
+	defer RedisClose(t, db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	follower := token.NewRedisDenylist(db, 1000)
+	go follower.Run(ctx, time.Hour)
+
+	// give the follower time to subscribe and load its filter
+	time.Sleep(200 * time.Millisecond)
+
+	id := uuid.New()
+	if err := token.NewRedisDenylist(db, 1000).Deny(ctx, id, time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("error on deny: err: %s", err.Error())
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if ok, err := follower.Denied(ctx, id); err == nil && ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the id denied by another instance to be denied")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,159 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"roadmap.restapi/internal/customdomain"
+	"roadmap.restapi/internal/url"
+)
+
+func TestDomainRepository_CreateAndVerify(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"domains", "users"})
+	uid := createUser(t, db)
+
+	repo := customdomain.NewPostgresRepository(db)
+	ctx := context.Background()
+
+	d := &customdomain.Domain{Name: "go.brand.com", UserID: uid, Token: "token"}
+	if err := repo.Create(ctx, d); err != nil {
+		t.Fatalf("failed to create domain: %v", err)
+	}
+	if d.IsVerified() {
+		t.Error("new domain should not be verified")
+	}
+
+	if _, err := repo.Verified(ctx, "go.brand.com"); !errors.Is(err, customdomain.ErrDomainNotFound) {
+		t.Errorf("expected ErrDomainNotFound for unverified domain, got %v", err)
+	}
+
+	err := repo.Create(ctx, &customdomain.Domain{Name: "go.brand.com", UserID: uid, Token: "other"})
+	if !errors.Is(err, customdomain.ErrDomainAlreadyExists) {
+		t.Errorf("expected ErrDomainAlreadyExists, got %v", err)
+	}
+
+	if err = repo.MarkVerified(ctx, d.ID, time.Now().UTC()); err != nil {
+		t.Fatalf("failed to mark verified: %v", err)
+	}
+
+	found, err := repo.Verified(ctx, "go.brand.com")
+	if err != nil {
+		t.Fatalf("failed to get verified domain: %v", err)
+	}
+	if found.ID != d.ID {
+		t.Errorf("ID mismatch: got %s, want %s", found.ID, d.ID)
+	}
+}
+
+func TestDomainRepository_MarkVerified_Taken(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"domains", "users"})
+	first := createUserWithEmail(t, db, "first@test")
+	second := createUserWithEmail(t, db, "second@test")
+
+	repo := customdomain.NewPostgresRepository(db)
+	ctx := context.Background()
+
+	a := &customdomain.Domain{Name: "brand.com", UserID: first, Token: "a"}
+	b := &customdomain.Domain{Name: "brand.com", UserID: second, Token: "b"}
+	for _, d := range []*customdomain.Domain{a, b} {
+		if err := repo.Create(ctx, d); err != nil {
+			t.Fatalf("failed to create domain: %v", err)
+		}
+	}
+
+	if err := repo.MarkVerified(ctx, a.ID, time.Now().UTC()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.MarkVerified(ctx, b.ID, time.Now().UTC()); !errors.Is(err, customdomain.ErrDomainTaken) {
+		t.Errorf("expected ErrDomainTaken, got %v", err)
+	}
+}
+
+func TestDomainRepository_Delete_RemovesLinksOfVerifiedDomain(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"urls", "domains", "users"})
+	owner := createUserWithEmail(t, db, "owner@test")
+	squatter := createUserWithEmail(t, db, "squatter@test")
+
+	repo := customdomain.NewPostgresRepository(db)
+	urls := url.NewPostgresURLRepository(db)
+	ctx := context.Background()
+
+	verified := &customdomain.Domain{Name: "brand.com", UserID: owner, Token: "a"}
+	unverified := &customdomain.Domain{Name: "brand.com", UserID: squatter, Token: "b"}
+	for _, d := range []*customdomain.Domain{verified, unverified} {
+		if err := repo.Create(ctx, d); err != nil {
+			t.Fatalf("failed to create domain: %v", err)
+		}
+	}
+	if err := repo.MarkVerified(ctx, verified.ID, time.Now().UTC()); err != nil {
+		t.Fatal(err)
+	}
+
+	link := &url.URL{Domain: "brand.com", ID: "abc", AuthorID: owner, URL: "https://example.com"}
+	if err := urls.Create(ctx, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.Delete(ctx, unverified.ID); err != nil {
+		t.Fatalf("failed to delete domain: %v", err)
+	}
+	if _, err := urls.ByID(ctx, "brand.com", "abc"); err != nil {
+		t.Fatalf("expected links to survive deletion of an unverified registration, got %v", err)
+	}
+
+	if err := repo.Delete(ctx, verified.ID); err != nil {
+		t.Fatalf("failed to delete domain: %v", err)
+	}
+	if _, err := urls.ByID(ctx, "brand.com", "abc"); !errors.Is(err, url.ErrURLNotFound) {
+		t.Errorf("expected links of the deleted domain to be gone, got %v", err)
+	}
+
+	if err := repo.Delete(ctx, verified.ID); !errors.Is(err, customdomain.ErrDomainNotFound) {
+		t.Errorf("expected ErrDomainNotFound, got %v", err)
+	}
+}
+
+func TestDomainRepository_Delete_KeepsDomainWithForeignLinks(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"urls", "domains", "users"})
+	owner := createUserWithEmail(t, db, "owner@test")
+	recipient := createUserWithEmail(t, db, "recipient@test")
+
+	repo := customdomain.NewPostgresRepository(db)
+	urls := url.NewPostgresURLRepository(db)
+	ctx := context.Background()
+
+	domain := &customdomain.Domain{Name: "brand.com", UserID: owner, Token: "a"}
+	if err := repo.Create(ctx, domain); err != nil {
+		t.Fatalf("failed to create domain: %v", err)
+	}
+	if err := repo.MarkVerified(ctx, domain.ID, time.Now().UTC()); err != nil {
+		t.Fatal(err)
+	}
+
+	own := &url.URL{Domain: "brand.com", ID: "own", AuthorID: owner, URL: "https://example.com"}
+	foreign := &url.URL{Domain: "brand.com", ID: "foreign", AuthorID: recipient, URL: "https://example.com"}
+	for _, link := range []*url.URL{own, foreign} {
+		if err := urls.Create(ctx, link); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repo.Delete(ctx, domain.ID); !errors.Is(err, customdomain.ErrDomainHasForeignLinks) {
+		t.Fatalf("expected ErrDomainHasForeignLinks, got %v", err)
+	}
+	if _, err := repo.ByID(ctx, domain.ID); err != nil {
+		t.Errorf("expected the domain to be kept, got %v", err)
+	}
+	for _, link := range []*url.URL{own, foreign} {
+		if _, err := urls.ByID(ctx, "brand.com", link.ID); err != nil {
+			t.Errorf("expected link %s to be kept, got %v", link.ID, err)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"roadmap.restapi/internal/analytics"
+	"roadmap.restapi/internal/export"
+)
+
+func TestRowSource_Rows(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"clicks", "urls", "users"})
+	uid := createUser(t, db)
+	first := createURL(t, db, uid)
+	second := createURL(t, db, uid)
+
+	clicks := analytics.NewPostgresURLStatisticsRepository(db)
+	ctx := context.Background()
+
+	country := "DE"
+	now := time.Now().UTC()
+	for _, click := range []*analytics.Click{
+		{URLID: first.ID, ClickedAt: now.Add(-2 * time.Hour), CountryCode: &country},
+		{URLID: first.ID, ClickedAt: now.Add(-time.Hour)},
+		{URLID: second.ID, ClickedAt: now.Add(-time.Hour)},
+		{URLID: first.ID, ClickedAt: now.AddDate(0, 0, -10)},
+	} {
+		if err := clicks.AddClick(ctx, click); err != nil {
+			t.Fatalf("failed to add click: %v", err)
+		}
+	}
+
+	source := export.NewPostgresRowSource(db)
+	q := export.Query{
+		UserID:      uid,
+		URLID:       first.ID,
+		Granularity: export.GRANULARITY_RAW,
+		Columns:     []string{"url_id", "clicked_at", "country_code"},
+		From:        now.AddDate(0, 0, -1),
+		To:          now,
+	}
+
+	rows := [][]any{}
+	err := source.Rows(ctx, q, func(values []any) error {
+		rows = append(rows, values)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read rows: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows of the url in range, got %d", len(rows))
+	}
+	if len(rows[0]) != 3 || rows[0][2] == nil || rows[1][2] != nil {
+		t.Errorf("unexpected rows, oldest first expected: %v", rows)
+	}
+
+	q.URLID = ""
+	count, err := source.Count(ctx, q)
+	if err != nil {
+		t.Fatalf("failed to count rows: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 rows of all urls of the user, got %d", count)
+	}
+}
+
+func TestJobRepository_ClaimPending(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"export_jobs", "users"})
+	uid := createUser(t, db)
+
+	repo := export.NewPostgresJobRepository(db)
+	ctx := context.Background()
+
+	job := export.NewJob(export.Query{
+		UserID:      uid,
+		Granularity: export.GRANULARITY_DAILY,
+		Format:      export.FORMAT_NDJSON,
+		Columns:     []string{"bucket", "clicks"},
+		To:          time.Now().UTC(),
+	})
+	if err := repo.Create(ctx, job); err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+
+	now := time.Now().UTC()
+	claimed, err := repo.ClaimPending(ctx, now, now.Add(-export.JOB_LEASE), export.JOB_MAX_ATTEMPTS)
+	if err != nil {
+		t.Fatalf("failed to claim job: %v", err)
+	}
+	if claimed.ID != job.ID || claimed.Status != export.STATUS_RUNNING || claimed.HeartbeatAt == nil || claimed.Attempts != 1 {
+		t.Errorf("unexpected claimed job: %+v", claimed)
+	}
+
+	if _, err := repo.ClaimPending(ctx, now, now.Add(-export.JOB_LEASE), export.JOB_MAX_ATTEMPTS); !errors.Is(err, export.ErrJobNotFound) {
+		t.Errorf("a job must be claimed once, got %v", err)
+	}
+
+	// the worker stops beating, once the lease lapsed the job is claimed again
+	later := now.Add(2 * export.JOB_LEASE)
+	if err := repo.Heartbeat(ctx, job.ID, 1, now.Add(export.JOB_HEARTBEAT)); err != nil {
+		t.Fatalf("failed to record heartbeat: %v", err)
+	}
+	if _, err := repo.ClaimPending(ctx, now, now.Add(-export.JOB_LEASE), export.JOB_MAX_ATTEMPTS); !errors.Is(err, export.ErrJobNotFound) {
+		t.Errorf("a job with a recent heartbeat must not be claimed, got %v", err)
+	}
+	first := *claimed
+	claimed, err = repo.ClaimPending(ctx, later, later.Add(-export.JOB_LEASE), export.JOB_MAX_ATTEMPTS)
+	if err != nil {
+		t.Fatalf("failed to reclaim stale job: %v", err)
+	}
+	if claimed.ID != job.ID || claimed.Attempts != 2 {
+		t.Errorf("expected stale job %s to be reclaimed, got %+v", job.ID, claimed)
+	}
+
+	// the first worker comes back, it must not touch the job anymore
+	if err := repo.Heartbeat(ctx, job.ID, first.Attempts, later); !errors.Is(err, export.ErrJobLeaseLost) {
+		t.Errorf("expected ErrJobLeaseLost for a heartbeat of the old attempt, got %v", err)
+	}
+	first.Status = export.STATUS_FAILED
+	first.FinishedAt = &later
+	if err := repo.Finish(ctx, &first); !errors.Is(err, export.ErrJobLeaseLost) {
+		t.Errorf("expected ErrJobLeaseLost for the old attempt, got %v", err)
+	}
+
+	finished := time.Now().UTC().Add(-time.Hour)
+	claimed.Status = export.STATUS_DONE
+	claimed.RowCount = 42
+	claimed.FinishedAt = &finished
+	if err := repo.Finish(ctx, claimed); err != nil {
+		t.Fatalf("failed to finish job: %v", err)
+	}
+
+	stored, err := repo.ByID(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	if stored.Status != export.STATUS_DONE || stored.RowCount != 42 || stored.Query().Columns[1] != "clicks" {
+		t.Errorf("unexpected stored job: %+v", stored)
+	}
+
+	old, err := repo.FinishedBefore(ctx, time.Now().UTC())
+	if err != nil {
+		t.Fatalf("failed to list finished jobs: %v", err)
+	}
+	if len(old) != 1 {
+		t.Errorf("expected 1 finished job, got %d", len(old))
+	}
+}
+
+func TestJobRepository_FailAbandoned(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"export_jobs", "users"})
+	uid := createUser(t, db)
+
+	repo := export.NewPostgresJobRepository(db)
+	ctx := context.Background()
+
+	job := export.NewJob(export.Query{
+		UserID:      uid,
+		Granularity: export.GRANULARITY_DAILY,
+		Format:      export.FORMAT_CSV,
+		Columns:     []string{"bucket", "clicks"},
+		To:          time.Now().UTC(),
+	})
+	if err := repo.Create(ctx, job); err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+
+	// every worker dies while running the job
+	now := time.Now().UTC()
+	for range export.JOB_MAX_ATTEMPTS {
+		if _, err := repo.ClaimPending(ctx, now, now.Add(-export.JOB_LEASE), export.JOB_MAX_ATTEMPTS); err != nil {
+			t.Fatalf("failed to claim job: %v", err)
+		}
+		now = now.Add(2 * export.JOB_LEASE)
+	}
+
+	if _, err := repo.ClaimPending(ctx, now, now.Add(-export.JOB_LEASE), export.JOB_MAX_ATTEMPTS); !errors.Is(err, export.ErrJobNotFound) {
+		t.Errorf("a job out of attempts must not be claimed, got %v", err)
+	}
+
+	if err := repo.FailAbandoned(ctx, now, now.Add(-export.JOB_LEASE), export.JOB_MAX_ATTEMPTS, export.ErrJobAbandoned.Error()); err != nil {
+		t.Fatalf("failed to fail abandoned jobs: %v", err)
+	}
+
+	stored, err := repo.ByID(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	if stored.Status != export.STATUS_FAILED || stored.FinishedAt == nil || stored.Error == nil || *stored.Error != export.ErrJobAbandoned.Error() {
+		t.Errorf("expected the abandoned job to fail, got %+v", stored)
+	}
+}
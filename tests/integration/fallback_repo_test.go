@@ -0,0 +1,50 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"roadmap.restapi/internal/fallback"
+)
+
+func TestFallbackRepository_SaveReplaces(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"fallback_pages", "users"})
+	uid := createUser(t, db)
+
+	repo := fallback.NewPostgresRepository(db)
+	ctx := context.Background()
+
+	page := &fallback.Page{UserID: uid, Mode: fallback.MODE_REDIRECT, RedirectURL: "https://brand.com"}
+	if err := repo.Save(ctx, page); err != nil {
+		t.Fatalf("failed to save page: %v", err)
+	}
+
+	replaced := &fallback.Page{UserID: uid, Mode: fallback.MODE_TEMPLATE, Template: "<p>gone</p>"}
+	if err := repo.Save(ctx, replaced); err != nil {
+		t.Fatalf("failed to replace page: %v", err)
+	}
+	if replaced.ID != page.ID {
+		t.Errorf("page should be replaced in place: got %s, want %s", replaced.ID, page.ID)
+	}
+
+	found, err := repo.Get(ctx, uid, "")
+	if err != nil {
+		t.Fatalf("failed to get page: %v", err)
+	}
+	if found.Mode != fallback.MODE_TEMPLATE || found.Template != "<p>gone</p>" {
+		t.Errorf("page mismatch: got %+v", found)
+	}
+
+	if _, err = repo.Get(ctx, uid, "go.brand.com"); !errors.Is(err, fallback.ErrPageNotFound) {
+		t.Errorf("expected ErrPageNotFound, got %v", err)
+	}
+
+	if err = repo.Delete(ctx, uid, ""); err != nil {
+		t.Fatalf("failed to delete page: %v", err)
+	}
+	if err = repo.Delete(ctx, uid, ""); !errors.Is(err, fallback.ErrPageNotFound) {
+		t.Errorf("expected ErrPageNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"roadmap.restapi/internal/health"
+)
+
+func TestHealthRepository_DueRecordByUser(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"url_health_claims", "url_health_checks", "url_health", "urls", "users"})
+	uid := createUser(t, db)
+	u := createURL(t, db, uid)
+
+	repo := health.NewPostgresRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	due, err := repo.Due(ctx, now, time.Minute, 10)
+	if err != nil {
+		t.Fatalf("failed to get due: %v", err)
+	}
+	if len(due) != 1 || due[0].URLID != u.ID || due[0].URL != u.URL {
+		t.Fatalf("expected never checked url to be due, got %v", due)
+	}
+
+	due, err = repo.Due(ctx, now, time.Minute, 10)
+	if err != nil {
+		t.Fatalf("failed to get due: %v", err)
+	}
+	if len(due) != 0 {
+		t.Fatalf("expected claimed url to be skipped, got %v", due)
+	}
+
+	check := &health.Check{URLID: u.ID, StatusCode: 500, LatencyMs: 12, Broken: true, CheckedAt: now}
+	status := &health.Status{
+		URLID:               u.ID,
+		StatusCode:          500,
+		LatencyMs:           12,
+		Broken:              true,
+		ConsecutiveFailures: 2,
+		CheckedAt:           now,
+		NextCheckAt:         now.Add(time.Hour),
+	}
+	if err = repo.Record(ctx, check, status); err != nil {
+		t.Fatalf("failed to record: %v", err)
+	}
+
+	due, err = repo.Due(ctx, now, time.Minute, 10)
+	if err != nil {
+		t.Fatalf("failed to get due: %v", err)
+	}
+	if len(due) != 0 {
+		t.Errorf("expected no due urls, got %v", due)
+	}
+
+	due, err = repo.Due(ctx, now.Add(2*time.Hour), time.Minute, 10)
+	if err != nil {
+		t.Fatalf("failed to get due: %v", err)
+	}
+	if len(due) != 1 || !due[0].Broken || due[0].ConsecutiveFailures != 2 {
+		t.Errorf("expected broken url to be due later, got %v", due)
+	}
+
+	statuses, err := repo.ByUser(ctx, uid)
+	if err != nil {
+		t.Fatalf("failed to get by user: %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].StatusCode != 500 || !statuses[0].Broken {
+		t.Errorf("unexpected statuses: %v", statuses)
+	}
+}
+
+func TestHealthRepository_DueReclaimsLapsedClaims(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"url_health_claims", "urls", "users"})
+	uid := createUser(t, db)
+	u := createURL(t, db, uid)
+
+	repo := health.NewPostgresRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	if _, err := repo.Due(ctx, now, time.Minute, 10); err != nil {
+		t.Fatalf("failed to get due: %v", err)
+	}
+
+	due, err := repo.Due(ctx, now.Add(2*time.Minute), time.Minute, 10)
+	if err != nil {
+		t.Fatalf("failed to get due: %v", err)
+	}
+	if len(due) != 1 || due[0].URLID != u.ID {
+		t.Errorf("expected url with a lapsed claim to be due again, got %v", due)
+	}
+}
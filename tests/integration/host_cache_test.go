@@ -0,0 +1,45 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/customdomain"
+)
+
+func TestHostCache_GetSetForget(t *testing.T) {
+	db := RedisConnection(t)
+	defer RedisClose(t, db)
+
+	ctx := context.Background()
+	cache := customdomain.NewRedisHostCache(db, time.Minute)
+
+	if _, ok, err := cache.Get(ctx, "go.brand.com"); err != nil || ok {
+		t.Fatalf("expected cache miss, got ok: %v err: %v", ok, err)
+	}
+
+	if err := cache.Set(ctx, "other.com", nil); err != nil {
+		t.Fatal(err)
+	}
+	if domain, ok, err := cache.Get(ctx, "other.com"); err != nil || !ok || domain != nil {
+		t.Errorf("expected cached miss, got domain: %v ok: %v err: %v", domain, ok, err)
+	}
+
+	domain := &customdomain.Domain{ID: uuid.New(), Name: "go.brand.com", UserID: uuid.New()}
+	if err := cache.Set(ctx, "go.brand.com", domain); err != nil {
+		t.Fatal(err)
+	}
+	cached, ok, err := cache.Get(ctx, "go.brand.com")
+	if err != nil || !ok || cached.ID != domain.ID || cached.UserID != domain.UserID {
+		t.Fatalf("expected cached domain, got %v ok: %v err: %v", cached, ok, err)
+	}
+
+	if err = cache.Forget(ctx, "go.brand.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := cache.Get(ctx, "go.brand.com"); ok {
+		t.Error("expected forgotten host to miss")
+	}
+}
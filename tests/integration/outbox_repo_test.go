@@ -0,0 +1,112 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/outbox"
+)
+
+func TestOutboxRepository_AddClaimProcessed(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"outbox_messages"})
+
+	repo := outbox.NewPostgresRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	msg, err := outbox.NewMessage("test.topic", map[string]string{"key": "value"})
+	if err != nil {
+		t.Fatalf("failed to build message: %v", err)
+	}
+
+	if err = repo.Add(ctx, msg); err != nil {
+		t.Fatalf("failed to add message: %v", err)
+	}
+
+	claimed, err := repo.Claim(ctx, now, outbox.CLAIM_LEASE, 10)
+	if err != nil {
+		t.Fatalf("failed to claim: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].ID != msg.ID {
+		t.Fatalf("expected added message to be claimed, got %v", claimed)
+	}
+	if claimed[0].Topic != "test.topic" {
+		t.Errorf("topic mismatch: got %s", claimed[0].Topic)
+	}
+
+	if err = repo.MarkProcessed(ctx, msg.ID); err != nil {
+		t.Fatalf("failed to mark processed: %v", err)
+	}
+
+	later := now.Add(2 * outbox.CLAIM_LEASE)
+	claimed, err = repo.Claim(ctx, later, outbox.CLAIM_LEASE, 10)
+	if err != nil {
+		t.Fatalf("failed to claim: %v", err)
+	}
+	if len(claimed) != 0 {
+		t.Errorf("expected no pending messages, got %d", len(claimed))
+	}
+}
+
+func TestOutboxRepository_ClaimSkipsClaimedMessages(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"outbox_messages"})
+
+	repo := outbox.NewPostgresRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	msg, err := outbox.NewMessage("test.topic", map[string]string{"key": "value"})
+	if err != nil {
+		t.Fatalf("failed to build message: %v", err)
+	}
+	if err = repo.Add(ctx, msg); err != nil {
+		t.Fatalf("failed to add message: %v", err)
+	}
+
+	if claimed, err := repo.Claim(ctx, now, outbox.CLAIM_LEASE, 10); err != nil || len(claimed) != 1 {
+		t.Fatalf("expected the message to be claimed, got %d (%v)", len(claimed), err)
+	}
+
+	if other, err := repo.Claim(ctx, now, outbox.CLAIM_LEASE, 10); err != nil || len(other) != 0 {
+		t.Errorf("expected the claimed message to be skipped, got %d (%v)", len(other), err)
+	}
+
+	// a relay that died holds its claim only until the lease lapses
+	later := now.Add(outbox.CLAIM_LEASE)
+	if claimed, err := repo.Claim(ctx, later, outbox.CLAIM_LEASE, 10); err != nil || len(claimed) != 1 {
+		t.Errorf("expected the lapsed claim to be taken over, got %d (%v)", len(claimed), err)
+	}
+}
+
+func TestOutboxRepository_Release(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"outbox_messages"})
+
+	repo := outbox.NewPostgresRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	msg, err := outbox.NewMessage("test.topic", map[string]string{"key": "value"})
+	if err != nil {
+		t.Fatalf("failed to build message: %v", err)
+	}
+	if err = repo.Add(ctx, msg); err != nil {
+		t.Fatalf("failed to add message: %v", err)
+	}
+
+	if _, err = repo.Claim(ctx, now, outbox.CLAIM_LEASE, 10); err != nil {
+		t.Fatalf("failed to claim: %v", err)
+	}
+
+	if err = repo.Release(ctx, []uuid.UUID{msg.ID}); err != nil {
+		t.Fatalf("failed to release: %v", err)
+	}
+
+	if claimed, err := repo.Claim(ctx, now, outbox.CLAIM_LEASE, 10); err != nil || len(claimed) != 1 {
+		t.Errorf("expected the released message to be claimed again, got %d (%v)", len(claimed), err)
+	}
+}
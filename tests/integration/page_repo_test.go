@@ -0,0 +1,128 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"roadmap.restapi/internal/page"
+	"roadmap.restapi/internal/url"
+)
+
+func TestPageRepository_CreateAndByHandle(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"page_links", "pages", "urls", "users"})
+	uid := createUser(t, db)
+
+	repo := page.NewPostgresRepository(db)
+	ctx := context.Background()
+
+	p := &page.Page{
+		Handle:      "alice",
+		AuthorID:    uid,
+		Title:       "Alice",
+		Theme:       page.THEME_DARK,
+		AccentColor: "#ff0000",
+	}
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("failed to create page: %v", err)
+	}
+
+	found, err := repo.ByHandle(ctx, "alice")
+	if err != nil {
+		t.Fatalf("failed to get by handle: %v", err)
+	}
+	if found.ID != p.ID || found.Theme != page.THEME_DARK {
+		t.Errorf("page mismatch: got %+v", found)
+	}
+
+	err = repo.Create(ctx, &page.Page{Handle: "alice", AuthorID: uid, Theme: page.THEME_LIGHT})
+	if !errors.Is(err, page.ErrHandleTaken) {
+		t.Errorf("expected ErrHandleTaken, got %v", err)
+	}
+
+	if _, err = repo.ByHandle(ctx, "bob"); !errors.Is(err, page.ErrPageNotFound) {
+		t.Errorf("expected ErrPageNotFound, got %v", err)
+	}
+}
+
+func TestPageRepository_SetLinks(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"page_links", "pages", "urls", "users"})
+	uid := createUser(t, db)
+	first := createURL(t, db, uid)
+	second := createURL(t, db, uid)
+
+	repo := page.NewPostgresRepository(db)
+	ctx := context.Background()
+
+	p := &page.Page{Handle: "links", AuthorID: uid, Theme: page.THEME_LIGHT}
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+
+	err := repo.SetLinks(ctx, p.ID, []page.Link{
+		{URLID: second.ID, Position: 0, Title: "Second"},
+		{URLID: first.ID, Position: 1},
+	})
+	if err != nil {
+		t.Fatalf("failed to set links: %v", err)
+	}
+
+	links, err := repo.Links(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("failed to get links: %v", err)
+	}
+	if len(links) != 2 {
+		t.Fatalf("expected 2 links, got %d", len(links))
+	}
+	if links[0].URLID != second.ID || links[1].URLID != first.ID {
+		t.Errorf("links are not ordered by position: %+v", links)
+	}
+	if links[1].DisplayTitle() != first.Name {
+		t.Errorf("expected url name as title, got %s", links[1].DisplayTitle())
+	}
+
+	if err = repo.SetLinks(ctx, p.ID, []page.Link{{URLID: first.ID}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = repo.Link(ctx, p.ID, "", second.ID); !errors.Is(err, page.ErrLinkNotFound) {
+		t.Errorf("expected ErrLinkNotFound, got %v", err)
+	}
+}
+
+func TestPageRepository_LinksSkipTransferredURLs(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"page_links", "pages", "urls", "users"})
+	uid := createUserWithEmail(t, db, "owner@test")
+	other := createUserWithEmail(t, db, "other@test")
+	kept := createURL(t, db, uid)
+	transferred := createURL(t, db, uid)
+
+	repo := page.NewPostgresRepository(db)
+	ctx := context.Background()
+
+	p := &page.Page{Handle: "transfers", AuthorID: uid, Theme: page.THEME_LIGHT}
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+
+	err := repo.SetLinks(ctx, p.ID, []page.Link{{URLID: kept.ID}, {URLID: transferred.ID, Position: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transferred.AuthorID = other
+	if err = url.NewPostgresURLRepository(db).Update(ctx, transferred); err != nil {
+		t.Fatal(err)
+	}
+
+	links, err := repo.Links(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("failed to get links: %v", err)
+	}
+	if len(links) != 1 || links[0].URLID != kept.ID {
+		t.Errorf("expected only the url still owned by the page author, got %+v", links)
+	}
+}
@@ -0,0 +1,37 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"roadmap.restapi/internal/privacy"
+)
+
+func TestPrivacySettingsRepository_SaveGet(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"privacy_settings", "users"})
+	uid := createUser(t, db)
+
+	repo := privacy.NewPostgresSettingsRepository(db)
+	ctx := context.Background()
+
+	if _, err := repo.Get(ctx, uid); !errors.Is(err, privacy.ErrSettingsNotFound) {
+		t.Fatalf("expected ErrSettingsNotFound, got %v", err)
+	}
+
+	if err := repo.Save(ctx, &privacy.Settings{UserID: uid, CaptureReferer: false}); err != nil {
+		t.Fatalf("failed to save settings: %v", err)
+	}
+	if err := repo.Save(ctx, &privacy.Settings{UserID: uid, CaptureReferer: true}); err != nil {
+		t.Fatalf("failed to replace settings: %v", err)
+	}
+
+	settings, err := repo.Get(ctx, uid)
+	if err != nil {
+		t.Fatalf("failed to get settings: %v", err)
+	}
+	if !settings.CaptureReferer {
+		t.Error("expected settings to be replaced")
+	}
+}
@@ -0,0 +1,37 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/analytics"
+)
+
+func TestRateCounter_Hit(t *testing.T) {
+	db := RedisConnection(t)
+	defer RedisClose(t, db)
+
+	ctx := context.Background()
+	counter := analytics.NewRedisRateCounter(db)
+	key := uuid.NewString()
+
+	for i := int64(1); i <= 3; i++ {
+		hits, err := counter.Hit(ctx, key, time.Hour)
+		if err != nil {
+			t.Fatalf("error on hit: err: %s", err.Error())
+		}
+		if hits != i {
+			t.Errorf("expected %d hits, got %d", i, hits)
+		}
+	}
+
+	hits, err := counter.Hit(ctx, uuid.NewString(), time.Hour)
+	if err != nil {
+		t.Fatalf("error on hit: err: %s", err.Error())
+	}
+	if hits != 1 {
+		t.Errorf("expected other keys to be counted apart, got %d hits", hits)
+	}
+}
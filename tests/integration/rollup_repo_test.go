@@ -0,0 +1,105 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"roadmap.restapi/internal/analytics"
+)
+
+func TestRollupRepository_RollUp(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"click_rollup_watermarks", "click_rollups_daily", "click_rollups_hourly", "clicks", "urls", "users"})
+	uid := createUser(t, db)
+	u := createURL(t, db, uid)
+
+	stats := analytics.NewPostgresURLStatisticsRepository(db)
+	rollups := analytics.NewPostgresRollupRepository(db)
+	ctx := context.Background()
+
+	country := "DE"
+	desktop, mobile := analytics.DEVICE_DESKTOP, analytics.DEVICE_MOBILE
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	clicks := []*analytics.Click{
+		{URLID: u.ID, ClickedAt: today.AddDate(0, 0, -3).Add(10 * time.Hour), CountryCode: &country, DeviceType: &desktop},
+		{URLID: u.ID, ClickedAt: today.AddDate(0, 0, -3).Add(11 * time.Hour), DeviceType: &mobile},
+		{URLID: u.ID, ClickedAt: today.AddDate(0, 0, -1).Add(23 * time.Hour), CountryCode: &country, DeviceType: &desktop, IsBot: true},
+		{URLID: u.ID, ClickedAt: today.Add(30 * time.Minute), DeviceType: &desktop},
+	}
+	for _, click := range clicks {
+		if err := stats.AddClick(ctx, click); err != nil {
+			t.Fatalf("failed to add click: %v", err)
+		}
+	}
+
+	filter := analytics.StatsFilter{To: today.AddDate(0, 0, 1)}
+	before, err := stats.Stats(ctx, u.Domain, u.ID, filter)
+	if err != nil {
+		t.Fatalf("failed to get stats: %v", err)
+	}
+
+	// rolls everything before today into daily rollups and purges the
+	// clicks of three days ago
+	done, err := rollups.RollUp(ctx, today, today.AddDate(0, 0, -2))
+	if err != nil {
+		t.Fatalf("failed to roll up: %v", err)
+	}
+	if !done {
+		t.Fatal("expected rollup to take the lock")
+	}
+
+	var raw int
+	if err := db.Get(&raw, `SELECT COUNT(*) FROM clicks`); err != nil {
+		t.Fatal(err)
+	}
+	if raw != 2 {
+		t.Errorf("expected 2 raw clicks left, got %d", raw)
+	}
+
+	after, err := stats.Stats(ctx, u.Domain, u.ID, filter)
+	if err != nil {
+		t.Fatalf("failed to get stats: %v", err)
+	}
+	if len(after) != len(before) || len(after) != 3 {
+		t.Fatalf("expected stats of 3 days before and after rollup, got %d and %d", len(before), len(after))
+	}
+	for i := range before {
+		if after[i].TotalClicks != before[i].TotalClicks || after[i].BotClicks != before[i].BotClicks {
+			t.Errorf("day %s changed by rollup: %+v, was %+v", after[i].Date, after[i], before[i])
+		}
+		if len(after[i].ByGeo) != len(before[i].ByGeo) || len(after[i].ByDevice) != len(before[i].ByDevice) {
+			t.Errorf("breakdowns of day %s changed by rollup: %+v, was %+v", after[i].Date, after[i], before[i])
+		}
+	}
+
+	// a second run finds nothing new to roll up
+	if _, err := rollups.RollUp(ctx, today, today.AddDate(0, 0, -2)); err != nil {
+		t.Fatalf("failed to roll up again: %v", err)
+	}
+	again, err := stats.Stats(ctx, u.Domain, u.ID, filter)
+	if err != nil {
+		t.Fatalf("failed to get stats: %v", err)
+	}
+	if again[2].TotalClicks != 2 {
+		t.Errorf("expected clicks to be rolled up once, got %d", again[2].TotalClicks)
+	}
+}
+
+func TestRollupRepository_RollUpLocked(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"click_rollup_watermarks"})
+
+	ctx := context.Background()
+	tx := db.MustBegin()
+	defer tx.Rollback()
+	tx.MustExec(`SELECT pg_advisory_xact_lock(hashtext('click_rollups'))`)
+
+	done, err := analytics.NewPostgresRollupRepository(db).RollUp(ctx, time.Now().UTC().Truncate(time.Hour), time.Time{})
+	if err != nil {
+		t.Fatalf("failed to roll up: %v", err)
+	}
+	if done {
+		t.Error("expected rollup to skip while another instance holds the lock")
+	}
+}
@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/token"
+)
+
+func TestSessionRepo_SaveListDelete(t *testing.T) {
+	db := RedisConnection(t)
+	defer RedisClose(t, db)
+
+	ctx := context.Background()
+	repo := token.NewRedisSessionRepository(db, 10*time.Second)
+	userID := uuid.New()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	first := &token.Session{ID: uuid.New(), UserID: userID, CreatedAt: now, LastRefreshedAt: now, IP: "203.0.113.7", UserAgent: "Mozilla/5.0"}
+	second := &token.Session{ID: uuid.New(), UserID: userID, CreatedAt: now, LastRefreshedAt: now}
+	for _, s := range []*token.Session{first, second} {
+		if err := repo.Save(ctx, s); err != nil {
+			t.Fatalf("error on save: err: %s", err.Error())
+		}
+	}
+
+	got, err := repo.Get(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("error on get: err: %s", err.Error())
+	}
+	if got.IP != first.IP || got.UserAgent != first.UserAgent || !got.CreatedAt.Equal(now) {
+		t.Errorf("expected %+v, got %+v", first, got)
+	}
+
+	sessions, err := repo.ByUser(ctx, userID)
+	if err != nil {
+		t.Fatalf("error on list: err: %s", err.Error())
+	}
+	if len(sessions) != 2 {
+		t.Errorf("expected 2 sessions, got %d", len(sessions))
+	}
+
+	if err = repo.Delete(ctx, userID, first.ID); err != nil {
+		t.Fatalf("error on delete: err: %s", err.Error())
+	}
+
+	if _, err = repo.Get(ctx, first.ID); !errors.Is(err, token.ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound, got %v", err)
+	}
+
+	sessions, err = repo.ByUser(ctx, userID)
+	if err != nil {
+		t.Fatalf("error on list: err: %s", err.Error())
+	}
+	if len(sessions) != 1 || sessions[0].ID != second.ID {
+		t.Errorf("expected only the remaining session, got %+v", sessions)
+	}
+}
+
+func TestSessionRepo_UpdateDeleted(t *testing.T) {
+	db := RedisConnection(t)
+	defer RedisClose(t, db)
+
+	ctx := context.Background()
+	repo := token.NewRedisSessionRepository(db, 10*time.Second)
+	now := time.Now().UTC().Truncate(time.Second)
+	session := &token.Session{ID: uuid.New(), UserID: uuid.New(), CreatedAt: now, LastRefreshedAt: now}
+
+	if err := repo.Save(ctx, session); err != nil {
+		t.Fatalf("error on save: err: %s", err.Error())
+	}
+
+	session.IP = "203.0.113.7"
+	if err := repo.Update(ctx, session); err != nil {
+		t.Fatalf("error on update: err: %s", err.Error())
+	}
+	if got, err := repo.Get(ctx, session.ID); err != nil || got.IP != session.IP {
+		t.Errorf("expected the session to be updated, got %+v, %v", got, err)
+	}
+
+	// the session is revoked while its token is refreshed
+	if err := repo.Delete(ctx, session.UserID, session.ID); err != nil {
+		t.Fatalf("error on delete: err: %s", err.Error())
+	}
+	if err := repo.Update(ctx, session); !errors.Is(err, token.ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound, got %v", err)
+	}
+	if _, err := repo.Get(ctx, session.ID); !errors.Is(err, token.ErrSessionNotFound) {
+		t.Errorf("expected the deleted session to stay deleted, got %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/analytics"
+)
+
+func TestURLStatisticsRepository_Stats(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"clicks", "urls", "users"})
+	uid := createUser(t, db)
+	u := createURL(t, db, uid)
+
+	repo := analytics.NewPostgresURLStatisticsRepository(db)
+	ctx := context.Background()
+
+	country := "DE"
+	referer := "page:alice"
+	mobile, other := analytics.DEVICE_MOBILE, analytics.DEVICE_OTHER
+	ios, android, safari, chrome := "iOS", "Android", "Safari", "Chrome"
+	unknown := analytics.FAMILY_OTHER
+	now := time.Now().UTC().Truncate(24 * time.Hour).Add(12 * time.Hour)
+	clicks := []*analytics.Click{
+		{URLID: u.ID, ClickedAt: now, CountryCode: &country, Referer: &referer, DeviceType: &mobile, OSFamily: &ios, BrowserFamily: &safari},
+		{URLID: u.ID, ClickedAt: now, CountryCode: &country, DeviceType: &mobile, OSFamily: &android, BrowserFamily: &chrome},
+		{URLID: u.ID, ClickedAt: now.AddDate(0, 0, -1), DeviceType: &other, OSFamily: &unknown, BrowserFamily: &unknown},
+		{URLID: u.ID, ClickedAt: now, CountryCode: &country, DeviceType: &other, OSFamily: &unknown, BrowserFamily: &unknown, IsBot: true},
+		// anonymous clicks only count in the totals
+		{URLID: u.ID, ClickedAt: now.AddDate(0, 0, -1)},
+	}
+	for _, click := range clicks {
+		if err := repo.AddClick(ctx, click); err != nil {
+			t.Fatalf("failed to add click: %v", err)
+		}
+	}
+
+	stats, err := repo.Stats(ctx, u.Domain, u.ID, analytics.StatsFilter{To: now.AddDate(0, 0, 1)})
+	if err != nil {
+		t.Fatalf("failed to get stats: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("expected stats for 2 days, got %d", len(stats))
+	}
+
+	today := stats[0]
+	if today.TotalClicks != 2 {
+		t.Errorf("expected 2 clicks today, got %d", today.TotalClicks)
+	}
+	if today.BotClicks != 1 {
+		t.Errorf("expected 1 bot click today, got %d", today.BotClicks)
+	}
+	if len(today.ByGeo) != 1 || today.ByGeo[0].CountryCode != "DE" || today.ByGeo[0].Clicks != 2 {
+		t.Errorf("unexpected geo stats: %+v", today.ByGeo)
+	}
+	if len(today.ByReferer) == 0 {
+		t.Errorf("expected referer stats, got none")
+	}
+	if len(today.ByDevice) != 1 || today.ByDevice[0].Device != analytics.DEVICE_MOBILE || today.ByDevice[0].Clicks != 2 {
+		t.Errorf("unexpected device stats: %+v", today.ByDevice)
+	}
+	if len(today.ByOS) != 2 {
+		t.Errorf("expected 2 os families, got %+v", today.ByOS)
+	}
+	if len(today.ByBrowser) != 2 {
+		t.Errorf("expected 2 browser families, got %+v", today.ByBrowser)
+	}
+	if stats[1].TotalClicks != 2 {
+		t.Errorf("expected 2 clicks yesterday, got %d", stats[1].TotalClicks)
+	}
+	if len(stats[1].ByDevice) != 1 || stats[1].ByDevice[0].Clicks != 1 {
+		t.Errorf("expected anonymous click to be left out of device stats: %+v", stats[1].ByDevice)
+	}
+}
+
+func TestURLStatisticsRepository_StatsIncludeBots(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"clicks", "urls", "users"})
+	uid := createUser(t, db)
+	u := createURL(t, db, uid)
+
+	repo := analytics.NewPostgresURLStatisticsRepository(db)
+	ctx := context.Background()
+
+	country := "US"
+	now := time.Now().UTC()
+	clicks := []*analytics.Click{
+		{URLID: u.ID, ClickedAt: now, CountryCode: &country},
+		{URLID: u.ID, ClickedAt: now, CountryCode: &country, IsBot: true},
+		{URLID: u.ID, ClickedAt: now, CountryCode: &country, IsBot: true},
+	}
+	for _, click := range clicks {
+		if err := repo.AddClick(ctx, click); err != nil {
+			t.Fatalf("failed to add click: %v", err)
+		}
+	}
+
+	stats, err := repo.Stats(ctx, u.Domain, u.ID, analytics.StatsFilter{To: now.Add(time.Hour), IncludeBots: true})
+	if err != nil {
+		t.Fatalf("failed to get stats: %v", err)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("expected stats for 1 day, got %d", len(stats))
+	}
+
+	if stats[0].TotalClicks != 3 {
+		t.Errorf("expected 3 clicks with bots, got %d", stats[0].TotalClicks)
+	}
+	if stats[0].BotClicks != 2 {
+		t.Errorf("expected 2 bot clicks, got %d", stats[0].BotClicks)
+	}
+	if len(stats[0].ByGeo) != 1 || stats[0].ByGeo[0].Clicks != 3 {
+		t.Errorf("unexpected geo stats: %+v", stats[0].ByGeo)
+	}
+}
+
+func TestURLStatisticsRepository_AccountStats(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"clicks", "urls", "users"})
+	uid := createUser(t, db)
+	popular := createURL(t, db, uid)
+	quiet := createURL(t, db, uid)
+	foreign := createURL(t, db, createUser(t, db))
+
+	repo := analytics.NewPostgresURLStatisticsRepository(db)
+	ctx := context.Background()
+
+	country := "DE"
+	referer := "page:alice"
+	now := time.Now().UTC().Truncate(24 * time.Hour).Add(12 * time.Hour)
+	clicks := []*analytics.Click{
+		{URLID: popular.ID, ClickedAt: now, CountryCode: &country, Referer: &referer},
+		{URLID: popular.ID, ClickedAt: now, CountryCode: &country},
+		{URLID: quiet.ID, ClickedAt: now},
+		{URLID: quiet.ID, ClickedAt: now.AddDate(0, 0, -1)},
+		{URLID: popular.ID, ClickedAt: now, IsBot: true},
+		{URLID: foreign.ID, ClickedAt: now},
+	}
+	for _, click := range clicks {
+		if err := repo.AddClick(ctx, click); err != nil {
+			t.Fatalf("failed to add click: %v", err)
+		}
+	}
+
+	stats, err := repo.AccountStats(ctx, uid, now.AddDate(0, 0, -7), now.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("failed to get account stats: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("expected stats for 2 days, got %d", len(stats))
+	}
+
+	today := stats[0]
+	if today.TotalClicks != 3 {
+		t.Errorf("expected 3 clicks today, got %d", today.TotalClicks)
+	}
+	if len(today.ByLink) != 2 || today.ByLink[0].URLID != popular.ID || today.ByLink[0].Clicks != 2 {
+		t.Errorf("unexpected link stats: %+v", today.ByLink)
+	}
+	if len(today.ByGeo) != 1 || today.ByGeo[0].Clicks != 2 {
+		t.Errorf("unexpected geo stats: %+v", today.ByGeo)
+	}
+	if len(today.ByReferer) != 1 || today.ByReferer[0].Referer != referer {
+		t.Errorf("unexpected referer stats: %+v", today.ByReferer)
+	}
+	if stats[1].TotalClicks != 1 {
+		t.Errorf("expected 1 click yesterday, got %d", stats[1].TotalClicks)
+	}
+}
+
+func TestURLStatisticsRepository_Conversions(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"conversions", "clicks", "urls", "users"})
+	uid := createUser(t, db)
+	u := createURL(t, db, uid)
+
+	repo := analytics.NewPostgresURLStatisticsRepository(db)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(24 * time.Hour).Add(12 * time.Hour)
+	clicks := []*analytics.Click{
+		{ID: uuid.New(), URLID: u.ID, ClickedAt: now},
+		{URLID: u.ID, ClickedAt: now},
+		{URLID: u.ID, ClickedAt: now},
+		{URLID: u.ID, ClickedAt: now},
+	}
+	for _, click := range clicks {
+		if err := repo.AddClick(ctx, click); err != nil {
+			t.Fatalf("failed to add click: %v", err)
+		}
+	}
+
+	click, err := repo.Click(ctx, clicks[0].ID)
+	if err != nil {
+		t.Fatalf("failed to get click: %v", err)
+	}
+	if click.URLID != u.ID {
+		t.Errorf("expected click of %s, got %+v", u.ID, click)
+	}
+	if _, err := repo.Click(ctx, uuid.New()); !errors.Is(err, analytics.ErrClickNotFound) {
+		t.Errorf("expected ErrClickNotFound, got %v", err)
+	}
+
+	value := 12.5
+	conversion := &analytics.Conversion{ClickID: click.ID, URLID: u.ID, ClickedAt: click.ClickedAt, Value: &value}
+	if err := repo.AddConversion(ctx, conversion); err != nil {
+		t.Fatalf("failed to add conversion: %v", err)
+	}
+	if conversion.ID == uuid.Nil || conversion.ConvertedAt.IsZero() {
+		t.Errorf("expected stored conversion, got %+v", conversion)
+	}
+
+	again := &analytics.Conversion{ClickID: click.ID, URLID: u.ID, ClickedAt: click.ClickedAt}
+	if err := repo.AddConversion(ctx, again); !errors.Is(err, analytics.ErrAlreadyConverted) {
+		t.Errorf("expected ErrAlreadyConverted, got %v", err)
+	}
+
+	stats, err := repo.Stats(ctx, u.Domain, u.ID, analytics.StatsFilter{To: now.AddDate(0, 0, 1)})
+	if err != nil {
+		t.Fatalf("failed to get stats: %v", err)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("expected stats for 1 day, got %d", len(stats))
+	}
+	if stats[0].Conversions != 1 || stats[0].ConversionValue != value || stats[0].ConversionRate != 0.25 {
+		t.Errorf("unexpected conversion stats: %+v", stats[0])
+	}
+}
@@ -2,6 +2,7 @@ package integration
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -16,7 +17,7 @@ func TestTokenRepo_AddExists(t *testing.T) {
 	ctx := context.Background()
 	id := uuid.New()
 	repo := token.NewRedisWhitelistRepository(db, time.Duration(10) * time.Second)
-	err := repo.Add(ctx, id)
+	err := repo.Add(ctx, id, uuid.New())
 
 	if err != nil {
 		t.Errorf("error on add: err: %s", err.Error())
@@ -57,7 +58,7 @@ func TestTokenRepo_DeleteAdded(t *testing.T) {
 	ctx := context.Background()
 	id := uuid.New()
 	repo := token.NewRedisWhitelistRepository(db, time.Duration(10) * time.Second)
-	err := repo.Add(ctx, id)
+	err := repo.Add(ctx, id, uuid.New())
 
 	if err != nil {
 		t.Errorf("error on add: err: %s", err.Error())
@@ -77,3 +78,73 @@ func TestTokenRepo_DeleteAdded(t *testing.T) {
 		t.Error("removed key exists")
 	}
 }
+
+func TestTokenRepo_ConsumeAndRevokeFamily(t *testing.T) {
+	db := RedisConnection(t)
+	defer RedisClose(t, db)
+
+	ctx := context.Background()
+	repo := token.NewRedisWhitelistRepository(db, 10*time.Second)
+	family, first, second := uuid.New(), uuid.New(), uuid.New()
+
+	if err := repo.Add(ctx, first, family); err != nil {
+		t.Fatalf("error on add: err: %s", err.Error())
+	}
+
+	consumed, ok, err := repo.Consume(ctx, first)
+	if err != nil || !ok || consumed != family {
+		t.Fatalf("expected to consume the token of family %s, got %s, %v, %v", family, consumed, ok, err)
+	}
+
+	if _, ok, _ := repo.Consume(ctx, first); ok {
+		t.Error("expected a token to be consumed only once")
+	}
+
+	used, ok, err := repo.UsedFamily(ctx, first)
+	if err != nil || !ok || used != family {
+		t.Errorf("expected the consumed token to be remembered, got %s, %v, %v", used, ok, err)
+	}
+
+	if _, ok, _ := repo.UsedFamily(ctx, uuid.New()); ok {
+		t.Error("expected an unknown token not to be used")
+	}
+
+	if err = repo.Add(ctx, second, family); err != nil {
+		t.Fatalf("error on add: err: %s", err.Error())
+	}
+
+	if err = repo.RevokeFamily(ctx, family); err != nil {
+		t.Fatalf("error on revoke: err: %s", err.Error())
+	}
+
+	exists, err := repo.Exists(ctx, second)
+	if err != nil {
+		t.Errorf("error on exists: err: %s", err.Error())
+	}
+
+	if exists {
+		t.Error("token of a revoked family exists")
+	}
+}
+
+func TestTokenRepo_AddToRevokedFamily(t *testing.T) {
+	db := RedisConnection(t)
+	defer RedisClose(t, db)
+
+	ctx := context.Background()
+	repo := token.NewRedisWhitelistRepository(db, 10*time.Second)
+	family, rotated := uuid.New(), uuid.New()
+
+	if err := repo.RevokeFamily(ctx, family); err != nil {
+		t.Fatalf("error on revoke: err: %s", err.Error())
+	}
+
+	// a rotation that passed Consume before the family was revoked
+	if err := repo.Add(ctx, rotated, family); !errors.Is(err, token.ErrTokenRevoked) {
+		t.Errorf("expected ErrTokenRevoked, got %v", err)
+	}
+
+	if exists, _ := repo.Exists(ctx, rotated); exists {
+		t.Error("token added to a revoked family exists")
+	}
+}
@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"roadmap.restapi/internal/postgres"
+	"roadmap.restapi/internal/url"
+)
+
+func TestTransactor_RollsBackAllRepositories(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"url_revisions", "urls", "users"})
+	uid := createUser(t, db)
+	u := createURL(t, db, uid)
+
+	urls := url.NewPostgresURLRepository(db)
+	revisions := url.NewPostgresRevisionRepository(db)
+	tx := postgres.NewTransactor(db)
+	ctx := context.Background()
+	failure := errors.New("revision failed")
+
+	err := tx.InTx(ctx, func(ctx context.Context) error {
+		changed := *u
+		changed.URL = "https://changed.test"
+		if err := urls.Update(ctx, &changed); err != nil {
+			return err
+		}
+
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected the error of fn, got %v", err)
+	}
+
+	stored, err := urls.ByID(ctx, u.Domain, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.URL != u.URL {
+		t.Errorf("expected the update to be rolled back, got %s", stored.URL)
+	}
+
+	err = tx.InTx(ctx, func(ctx context.Context) error {
+		changed := *u
+		changed.URL = "https://changed.test"
+		if err := urls.Update(ctx, &changed); err != nil {
+			return err
+		}
+
+		return revisions.Create(ctx, &url.Revision{URLDomain: u.Domain, URLID: u.ID, AuthorID: uid, OldURL: u.URL, NewURL: changed.URL})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := revisions.ByURL(ctx, u.Domain, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 {
+		t.Errorf("expected the revision to be committed, got %d", len(history))
+	}
+}
@@ -0,0 +1,178 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"roadmap.restapi/internal/url"
+)
+
+func createURL(t *testing.T, db *sqlx.DB, authorID uuid.UUID) *url.URL {
+	u := &url.URL{
+		ID:       uuid.NewString(),
+		AuthorID: authorID,
+		URL:      "https://example.com",
+		Name:     "Example",
+	}
+
+	if err := url.NewPostgresURLRepository(db).Create(context.Background(), u); err != nil {
+		t.Fatal(err)
+	}
+
+	return u
+}
+
+func TestRevisionRepository_Create_Success(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"url_revisions", "urls", "users"})
+	uid := createUser(t, db)
+	u := createURL(t, db, uid)
+
+	repo := url.NewPostgresRevisionRepository(db)
+	ctx := context.Background()
+
+	rev := &url.Revision{
+		URLID:    u.ID,
+		AuthorID: uid,
+		OldURL:   u.URL,
+		NewURL:   "https://new.test",
+		OldName:  u.Name,
+		NewName:  "New",
+	}
+
+	if err := repo.Create(ctx, rev); err != nil {
+		t.Fatalf("failed to create revision: %v", err)
+	}
+
+	if rev.ID == uuid.Nil {
+		t.Error("ID should not be empty")
+	}
+	if rev.CreatedAt.IsZero() {
+		t.Error("CreatedAt should be set")
+	}
+
+	found, err := repo.ByID(ctx, rev.ID)
+	if err != nil {
+		t.Fatalf("failed to get by id: %v", err)
+	}
+	if found.OldURL != u.URL || found.NewURL != "https://new.test" {
+		t.Errorf("url mismatch: got %s -> %s", found.OldURL, found.NewURL)
+	}
+	if found.AuthorID != uid {
+		t.Errorf("AuthorID mismatch: got %s, want %s", found.AuthorID, uid)
+	}
+}
+
+func TestRevisionRepository_OutlivesURL(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"url_revisions", "urls", "users"})
+	uid := createUser(t, db)
+	u := createURL(t, db, uid)
+
+	repo := url.NewPostgresRevisionRepository(db)
+	urls := url.NewPostgresURLRepository(db)
+	ctx := context.Background()
+
+	changed := *u
+	changed.URL = "https://new.test"
+	changed.Interstitial = true
+	rev := url.NewRevision(uid, u, &changed)
+	if err := repo.Create(ctx, rev); err != nil {
+		t.Fatalf("failed to create revision: %v", err)
+	}
+
+	if err := urls.Delete(ctx, u.Domain, u.ID); err != nil {
+		t.Fatalf("failed to delete url: %v", err)
+	}
+
+	found, err := repo.ByID(ctx, rev.ID)
+	if err != nil {
+		t.Fatalf("expected the revision to outlive the url, got %v", err)
+	}
+	if !found.HasSettings || found.OldInterstitial || !found.NewInterstitial {
+		t.Errorf("settings not recorded: %+v", found)
+	}
+
+	// a link created again under the same id starts with an empty history
+	if err := urls.Create(ctx, u); err != nil {
+		t.Fatalf("failed to create url again: %v", err)
+	}
+	list, err := repo.ByURL(ctx, u.Domain, u.ID)
+	if err != nil {
+		t.Fatalf("failed to get by url: %v", err)
+	}
+	if len(list) != 0 {
+		t.Errorf("expected no revisions of the deleted url, got %d", len(list))
+	}
+}
+
+func TestRevisionRepository_ByID_NotFound(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"url_revisions", "urls", "users"})
+
+	repo := url.NewPostgresRevisionRepository(db)
+
+	_, err := repo.ByID(context.Background(), uuid.New())
+	if err != url.ErrRevisionNotFound {
+		t.Errorf("wrong error: got %v, want %v", err, url.ErrRevisionNotFound)
+	}
+}
+
+func TestRevisionRepository_ByURL_NewestFirst(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"url_revisions", "urls", "users"})
+	uid := createUser(t, db)
+	u := createURL(t, db, uid)
+
+	repo := url.NewPostgresRevisionRepository(db)
+	ctx := context.Background()
+
+	for _, next := range []string{"https://one.test", "https://two.test"} {
+		if err := repo.Create(ctx, &url.Revision{
+			URLID:    u.ID,
+			AuthorID: uid,
+			OldURL:   u.URL,
+			NewURL:   next,
+		}); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+		u.URL = next
+	}
+
+	list, err := repo.ByURL(ctx, u.Domain, u.ID)
+	if err != nil {
+		t.Fatalf("failed to get by url: %v", err)
+	}
+
+	if len(list) != 2 {
+		t.Fatalf("expected 2 revisions, got %d", len(list))
+	}
+	if list[0].NewURL != "https://two.test" {
+		t.Errorf("expected newest revision first, got %s", list[0].NewURL)
+	}
+}
+
+func TestRevisionRepository_Immutable(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"url_revisions", "urls", "users"})
+	uid := createUser(t, db)
+	u := createURL(t, db, uid)
+
+	repo := url.NewPostgresRevisionRepository(db)
+	rev := &url.Revision{
+		URLID:    u.ID,
+		AuthorID: uid,
+		OldURL:   u.URL,
+		NewURL:   "https://new.test",
+	}
+	if err := repo.Create(context.Background(), rev); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	_, err := db.Exec("UPDATE url_revisions SET new_url = 'https://evil.test' WHERE id = $1", rev.ID)
+	if err == nil {
+		t.Error("expected revision update to be rejected")
+	}
+}
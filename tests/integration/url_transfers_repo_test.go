@@ -0,0 +1,177 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"roadmap.restapi/internal/url"
+	"roadmap.restapi/internal/user"
+)
+
+func createUserWithEmail(t *testing.T, db *sqlx.DB, email string) uuid.UUID {
+	userRepo := user.NewPostgresUserRepository(db)
+	users := user.NewUseCases(userRepo, user.NewArgon2IDPasswordHasher())
+	u, err := users.NewUser(context.Background(), email, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return u.ID
+}
+
+func TestTransferRepository_CreateAndIncoming(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"url_transfers", "urls", "users"})
+	from := createUserWithEmail(t, db, "from@test")
+	to := createUserWithEmail(t, db, "to@test")
+	u := createURL(t, db, from)
+
+	repo := url.NewPostgresTransferRepository(db)
+	ctx := context.Background()
+
+	transfer := &url.Transfer{
+		URLID:      u.ID,
+		FromUserID: from,
+		ToUserID:   to,
+		ExpiresAt:  time.Now().UTC().Add(time.Hour),
+	}
+	if err := repo.Create(ctx, transfer); err != nil {
+		t.Fatalf("failed to create transfer: %v", err)
+	}
+
+	if transfer.Status != url.TRANSFER_PENDING {
+		t.Errorf("status mismatch: got %s, want %s", transfer.Status, url.TRANSFER_PENDING)
+	}
+
+	incoming, err := repo.IncomingPending(ctx, to)
+	if err != nil {
+		t.Fatalf("failed to list incoming: %v", err)
+	}
+	if len(incoming) != 1 || incoming[0].ID != transfer.ID {
+		t.Fatalf("expected created transfer in incoming, got %v", incoming)
+	}
+
+	outgoing, err := repo.IncomingPending(ctx, from)
+	if err != nil {
+		t.Fatalf("failed to list incoming: %v", err)
+	}
+	if len(outgoing) != 0 {
+		t.Errorf("sender should not see transfer as incoming, got %d", len(outgoing))
+	}
+}
+
+func TestTransferRepository_IncomingSkipsExpired(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"url_transfers", "urls", "users"})
+	from := createUserWithEmail(t, db, "from@test")
+	to := createUserWithEmail(t, db, "to@test")
+	u := createURL(t, db, from)
+
+	repo := url.NewPostgresTransferRepository(db)
+	ctx := context.Background()
+
+	if err := repo.Create(ctx, &url.Transfer{
+		URLID:      u.ID,
+		FromUserID: from,
+		ToUserID:   to,
+		ExpiresAt:  time.Now().UTC().Add(-time.Hour),
+	}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	incoming, err := repo.IncomingPending(ctx, to)
+	if err != nil {
+		t.Fatalf("failed to list incoming: %v", err)
+	}
+	if len(incoming) != 0 {
+		t.Errorf("expired transfer should be skipped, got %d", len(incoming))
+	}
+}
+
+func TestTransferRepository_ResolveOnce(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"url_transfers", "urls", "users"})
+	from := createUserWithEmail(t, db, "from@test")
+	to := createUserWithEmail(t, db, "to@test")
+	u := createURL(t, db, from)
+
+	repo := url.NewPostgresTransferRepository(db)
+	ctx := context.Background()
+
+	transfer := &url.Transfer{
+		URLID:      u.ID,
+		FromUserID: from,
+		ToUserID:   to,
+		ExpiresAt:  time.Now().UTC().Add(time.Hour),
+	}
+	if err := repo.Create(ctx, transfer); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	if err := repo.Resolve(ctx, transfer.ID, url.TRANSFER_ACCEPTED); err != nil {
+		t.Fatalf("failed to resolve: %v", err)
+	}
+
+	if err := repo.Resolve(ctx, transfer.ID, url.TRANSFER_DECLINED); err != url.ErrTransferNotPending {
+		t.Errorf("wrong error: got %v, want %v", err, url.ErrTransferNotPending)
+	}
+
+	found, err := repo.ByID(ctx, transfer.ID)
+	if err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	if found.Status != url.TRANSFER_ACCEPTED {
+		t.Errorf("status mismatch: got %s, want %s", found.Status, url.TRANSFER_ACCEPTED)
+	}
+	if found.ResolvedAt == nil {
+		t.Error("ResolvedAt should be set")
+	}
+}
+
+func TestTransferRepository_CancelPending(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"url_transfers", "urls", "users"})
+	from := createUserWithEmail(t, db, "from@test")
+	to := createUserWithEmail(t, db, "to@test")
+	u := createURL(t, db, from)
+
+	repo := url.NewPostgresTransferRepository(db)
+	ctx := context.Background()
+
+	transfer := &url.Transfer{
+		URLID:      u.ID,
+		FromUserID: from,
+		ToUserID:   to,
+		ExpiresAt:  time.Now().UTC().Add(time.Hour),
+	}
+	if err := repo.Create(ctx, transfer); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	if err := repo.CancelPending(ctx, u.Domain, u.ID); err != nil {
+		t.Fatalf("failed to cancel: %v", err)
+	}
+
+	found, err := repo.ByID(ctx, transfer.ID)
+	if err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	if found.Status != url.TRANSFER_CANCELLED {
+		t.Errorf("status mismatch: got %s, want %s", found.Status, url.TRANSFER_CANCELLED)
+	}
+}
+
+func TestTransferRepository_ByID_NotFound(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"url_transfers", "urls", "users"})
+
+	repo := url.NewPostgresTransferRepository(db)
+
+	_, err := repo.ByID(context.Background(), uuid.New())
+	if err != url.ErrTransferNotFound {
+		t.Errorf("wrong error: got %v, want %v", err, url.ErrTransferNotFound)
+	}
+}
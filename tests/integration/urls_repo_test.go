@@ -112,7 +112,7 @@ func TestURLRepository_ByID_Success(t *testing.T) {
 		t.Fatalf("setup failed: %v", err)
 	}
 
-	found, err := repo.ByID(ctx, u.ID)
+	found, err := repo.ByID(ctx, u.Domain, u.ID)
 	if err != nil {
 		t.Fatalf("failed to get by id: %v", err)
 	}
@@ -135,7 +135,7 @@ func TestURLRepository_ByID_NotFound(t *testing.T) {
 	repo := url.NewPostgresURLRepository(db)
 	ctx := context.Background()
 
-	_, err := repo.ByID(ctx, uuid.NewString())
+	_, err := repo.ByID(ctx, "", uuid.NewString())
 	if err == nil {
 		t.Fatal("expected ErrURLNotFound, got nil")
 	}
@@ -170,7 +170,7 @@ func TestURLRepository_Update_Success(t *testing.T) {
 		t.Fatalf("failed to update: %v", err)
 	}
 
-	updated, err := repo.ByID(ctx, u.ID)
+	updated, err := repo.ByID(ctx, u.Domain, u.ID)
 	if err != nil {
 		t.Fatalf("failed to reload: %v", err)
 	}
@@ -207,6 +207,40 @@ func TestURLRepository_Update_NotFound(t *testing.T) {
 	}
 }
 
+func TestURLRepository_SetAuthor(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"urls", "users"})
+	from := createUser(t, db)
+	to := createUserWithEmail(t, db, "recipient@example.com")
+	u := createURL(t, db, from)
+
+	repo := url.NewPostgresURLRepository(db)
+	ctx := context.Background()
+
+	// an edit made after the URL was read must survive the hand over
+	edited := *u
+	edited.Name = "Edited"
+	if err := repo.Update(ctx, &edited); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	if err := repo.SetAuthor(ctx, u.Domain, u.ID, to); err != nil {
+		t.Fatalf("failed to set author: %v", err)
+	}
+
+	stored, err := repo.ByID(ctx, u.Domain, u.ID)
+	if err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	if stored.AuthorID != to || stored.Name != "Edited" {
+		t.Errorf("unexpected url after hand over: %+v", stored)
+	}
+
+	if err := repo.SetAuthor(ctx, u.Domain, uuid.NewString(), to); err != url.ErrURLNotFound {
+		t.Errorf("wrong error: got %v, want %v", err, url.ErrURLNotFound)
+	}
+}
+
 func TestURLRepository_Delete_Success(t *testing.T) {
 	db := PostgresConnection(t)
 	defer CleanTables(t, db, []string{"urls", "users"})
@@ -226,11 +260,11 @@ func TestURLRepository_Delete_Success(t *testing.T) {
 		t.Fatalf("setup failed: %v", err)
 	}
 
-	if err := repo.Delete(ctx, u.ID); err != nil {
+	if err := repo.Delete(ctx, u.Domain, u.ID); err != nil {
 		t.Fatalf("failed to delete: %v", err)
 	}
 
-	_, err := repo.ByID(ctx, u.ID)
+	_, err := repo.ByID(ctx, u.Domain, u.ID)
 	if err != url.ErrURLNotFound {
 		t.Errorf("expected ErrURLNotFound after delete, got %v", err)
 	}
@@ -243,7 +277,7 @@ func TestURLRepository_Delete_NotFound(t *testing.T) {
 	repo := url.NewPostgresURLRepository(db)
 	ctx := context.Background()
 
-	err := repo.Delete(ctx, uuid.NewString())
+	err := repo.Delete(ctx, "", uuid.NewString())
 	if err != url.ErrURLNotFound {
 		t.Errorf("expected ErrURLNotFound on delete non-existent, got %v", err)
 	}
@@ -287,3 +321,62 @@ func TestURLRepository_ByUser_Success(t *testing.T) {
 	}
 }
 
+func TestURLRepository_SameIDOnDifferentDomains(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"urls", "users"})
+	uid := createUser(t, db)
+
+	repo := url.NewPostgresURLRepository(db)
+	ctx := context.Background()
+
+	def := &url.URL{ID: "sale", AuthorID: uid, URL: "https://default.test"}
+	brand := &url.URL{Domain: "go.brand.com", ID: "sale", AuthorID: uid, URL: "https://brand.test"}
+
+	if err := repo.Create(ctx, def); err != nil {
+		t.Fatalf("failed to create URL: %v", err)
+	}
+	if err := repo.Create(ctx, brand); err != nil {
+		t.Fatalf("failed to create URL with the same id on another domain: %v", err)
+	}
+
+	found, err := repo.ByID(ctx, "go.brand.com", "sale")
+	if err != nil {
+		t.Fatalf("failed to get by id: %v", err)
+	}
+	if found.URL != "https://brand.test" {
+		t.Errorf("url mismatch: got %s", found.URL)
+	}
+
+	if err = repo.Delete(ctx, "", "sale"); err != nil {
+		t.Fatalf("failed to delete URL: %v", err)
+	}
+
+	if _, err = repo.ByID(ctx, "go.brand.com", "sale"); err != nil {
+		t.Errorf("URL of another domain should be kept: %v", err)
+	}
+}
+
+func TestURLRepository_SetMetadata_SkipsChangedDestination(t *testing.T) {
+	db := PostgresConnection(t)
+	defer CleanTables(t, db, []string{"urls", "users"})
+	uid := createUser(t, db)
+	u := createURL(t, db, uid)
+
+	repo := url.NewPostgresURLRepository(db)
+	ctx := context.Background()
+
+	if err := repo.SetMetadata(ctx, u.Domain, u.ID, "https://stale.test", &url.Metadata{Title: "Stale"}); err != nil {
+		t.Fatalf("failed to set metadata: %v", err)
+	}
+	if err := repo.SetMetadata(ctx, u.Domain, u.ID, u.URL, &url.Metadata{Title: "Fresh"}); err != nil {
+		t.Fatalf("failed to set metadata: %v", err)
+	}
+
+	stored, err := repo.ByID(ctx, u.Domain, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.MetaTitle != "Fresh" {
+		t.Errorf("expected metadata of the current destination, got %q", stored.MetaTitle)
+	}
+}
@@ -0,0 +1,73 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/analytics"
+)
+
+func TestVisitorsRepo_AddCount(t *testing.T) {
+	db := RedisConnection(t)
+	defer RedisClose(t, db)
+
+	ctx := context.Background()
+	repo := analytics.NewRedisVisitorsRepository(db, time.Hour)
+	urlID := uuid.NewString()
+	today := analytics.Day(time.Now())
+	yesterday := today.AddDate(0, 0, -1)
+
+	for i := range 10 {
+		// every visitor clicks twice
+		for range 2 {
+			if err := repo.Add(ctx, "", urlID, today, fmt.Sprintf("visitor-%d", i)); err != nil {
+				t.Fatalf("error on add: err: %s", err.Error())
+			}
+		}
+	}
+
+	counts, err := repo.Count(ctx, "", urlID, []time.Time{today, yesterday})
+	if err != nil {
+		t.Fatalf("error on count: err: %s", err.Error())
+	}
+
+	if counts[today] != 10 {
+		t.Errorf("expected 10 visitors today, got %d", counts[today])
+	}
+	if counts[yesterday] != 0 {
+		t.Errorf("expected no visitors yesterday, got %d", counts[yesterday])
+	}
+}
+
+func TestVisitorsRepo_Salt(t *testing.T) {
+	db := RedisConnection(t)
+	defer RedisClose(t, db)
+
+	ctx := context.Background()
+	repo := analytics.NewRedisVisitorsRepository(db, time.Hour)
+	today := analytics.Day(time.Now())
+
+	salt, err := repo.Salt(ctx, today)
+	if err != nil {
+		t.Fatalf("error on salt: err: %s", err.Error())
+	}
+
+	again, err := repo.Salt(ctx, today)
+	if err != nil {
+		t.Fatalf("error on salt: err: %s", err.Error())
+	}
+	if salt != again {
+		t.Error("salt changed within the day")
+	}
+
+	other, err := repo.Salt(ctx, today.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("error on salt: err: %s", err.Error())
+	}
+	if salt == other {
+		t.Error("salt is not rotated daily")
+	}
+}
@@ -0,0 +1,143 @@
+package unit
+
+import (
+	"context"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"roadmap.restapi/internal/analytics"
+)
+
+const browserUA = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+type memoryRates map[string]int64
+
+func (m memoryRates) Hit(ctx context.Context, key string, window time.Duration) (int64, error) {
+	m[key]++
+	return m[key], nil
+}
+
+func TestBotClassifier_IsBot(t *testing.T) {
+	ranges := []netip.Prefix{netip.MustParsePrefix("66.249.64.0/19")}
+
+	cases := []struct {
+		name    string
+		visitor analytics.Visitor
+		want    bool
+	}{
+		{"browser", analytics.Visitor{IP: "203.0.113.7", UserAgent: browserUA, AcceptLanguage: "en-US"}, false},
+		{"empty user agent", analytics.Visitor{IP: "203.0.113.7"}, true},
+		{"preview bot", analytics.Visitor{IP: "203.0.113.7", UserAgent: "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)"}, true},
+		{"http library", analytics.Visitor{IP: "203.0.113.7", UserAgent: "python-requests/2.31.0"}, true},
+		{"uptime monitor", analytics.Visitor{IP: "203.0.113.7", UserAgent: "Mozilla/5.0+(compatible; UptimeRobot/2.0; http://www.uptimerobot.com/)", AcceptLanguage: "en"}, true},
+		{"scanner", analytics.Visitor{IP: "203.0.113.7", UserAgent: "Mozilla/5.0 zgrab/0.x", AcceptLanguage: "en"}, true},
+		{"headless chrome", analytics.Visitor{IP: "203.0.113.7", UserAgent: "Mozilla/5.0 (X11; Linux x86_64) HeadlessChrome/120.0.0.0 Safari/537.36", AcceptLanguage: "en-US"}, true},
+		{"browser without language", analytics.Visitor{IP: "203.0.113.7", UserAgent: browserUA}, true},
+		{"crawler network", analytics.Visitor{IP: "66.249.66.1", UserAgent: browserUA, AcceptLanguage: "en-US"}, true},
+		{"mapped crawler network", analytics.Visitor{IP: "::ffff:66.249.66.1", UserAgent: browserUA, AcceptLanguage: "en-US"}, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			classifier := analytics.NewBotClassifier(ranges, memoryRates{}, memoryRates{}, 5, time.Minute)
+			if got := classifier.IsBot(context.Background(), c.visitor, "fp", false); got != c.want {
+				t.Errorf("IsBot(%+v) = %v, want %v", c.visitor, got, c.want)
+			}
+		})
+	}
+}
+
+func TestBotClassifier_IsBotRate(t *testing.T) {
+	ctx := context.Background()
+	classifier := analytics.NewBotClassifier(nil, memoryRates{}, memoryRates{}, 3, time.Minute)
+	visitor := analytics.Visitor{IP: "203.0.113.7", UserAgent: browserUA, AcceptLanguage: "en-US"}
+
+	for i := range 3 {
+		if classifier.IsBot(ctx, visitor, "fp", false) {
+			t.Fatalf("click %d within the limit classified as bot", i+1)
+		}
+	}
+
+	if !classifier.IsBot(ctx, visitor, "fp", false) {
+		t.Error("click over the limit not classified as bot")
+	}
+	if classifier.IsBot(ctx, visitor, "other-fp", false) {
+		t.Error("rate of another visitor affected")
+	}
+	if classifier.IsBot(ctx, visitor, "", false) {
+		t.Error("visitor without fingerprint classified by rate")
+	}
+}
+
+func TestBotClassifier_IsBotRateAnonymous(t *testing.T) {
+	ctx := context.Background()
+	rates, ephemeral := memoryRates{}, memoryRates{}
+	classifier := analytics.NewBotClassifier(nil, rates, ephemeral, 1, time.Minute)
+	visitor := analytics.Visitor{IP: "203.0.113.7", UserAgent: browserUA, AcceptLanguage: "en-US", DoNotTrack: "1"}
+
+	if classifier.IsBot(ctx, visitor, "fp", true) {
+		t.Fatal("click within the limit classified as bot")
+	}
+	if !classifier.IsBot(ctx, visitor, "fp", true) {
+		t.Error("anonymous click over the limit not classified as bot")
+	}
+	if len(rates) != 0 {
+		t.Errorf("expected anonymous clicks to stay off the shared counter, got %v", rates)
+	}
+}
+
+func TestMemoryRateCounter_Hit(t *testing.T) {
+	ctx := context.Background()
+	counter := analytics.NewMemoryRateCounter()
+
+	for i := range 3 {
+		hits, err := counter.Hit(ctx, "fp", time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hits != int64(i+1) {
+			t.Errorf("hit %d counted as %d", i+1, hits)
+		}
+	}
+
+	if hits, _ := counter.Hit(ctx, "other-fp", time.Hour); hits != 1 {
+		t.Errorf("expected keys to be counted apart, got %d", hits)
+	}
+}
+
+func TestLoadIPRanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ranges.txt")
+	content := "# crawlers\n66.249.64.0/19\n\n203.0.113.7 # single address\n2001:db8::/32\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ranges, err := analytics.LoadIPRanges(path)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := []string{"66.249.64.0/19", "203.0.113.7/32", "2001:db8::/32"}
+	if len(ranges) != len(want) {
+		t.Fatalf("got %v, want %v", ranges, want)
+	}
+	for i := range want {
+		if ranges[i].String() != want[i] {
+			t.Errorf("range %d = %s, want %s", i, ranges[i], want[i])
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("not-a-range\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := analytics.LoadIPRanges(path); err == nil {
+		t.Error("expected error for invalid range")
+	}
+
+	if ranges, err := analytics.LoadIPRanges(""); err != nil || ranges != nil {
+		t.Errorf("expected no ranges for empty path, got %v, %v", ranges, err)
+	}
+}
@@ -0,0 +1,22 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/analytics"
+)
+
+func TestClickRecorder_DropsClicksWhenQueueIsFull(t *testing.T) {
+	recorder := analytics.NewClickRecorder(nil, 2, 1)
+
+	for i := range 2 {
+		if !recorder.Enqueue(analytics.PendingClick{ID: uuid.New()}) {
+			t.Fatalf("expected click %d to be queued", i)
+		}
+	}
+
+	if recorder.Enqueue(analytics.PendingClick{ID: uuid.New()}) {
+		t.Error("expected a click over the queue size to be dropped")
+	}
+}
@@ -0,0 +1,90 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/analytics"
+	"roadmap.restapi/internal/privacy"
+)
+
+func TestAppendClickID(t *testing.T) {
+	clickID := uuid.MustParse("0b0f3c9e-8c1e-4d2a-9a57-6f1f4f7d2c10")
+
+	cases := []struct {
+		destination string
+		want        string
+	}{
+		{"https://shop.example/landing", "https://shop.example/landing?cid=0b0f3c9e-8c1e-4d2a-9a57-6f1f4f7d2c10"},
+		{"https://shop.example/?utm_source=x&b=2", "https://shop.example/?utm_source=x&b=2&cid=0b0f3c9e-8c1e-4d2a-9a57-6f1f4f7d2c10"},
+		{"https://shop.example/p#reviews", "https://shop.example/p?cid=0b0f3c9e-8c1e-4d2a-9a57-6f1f4f7d2c10#reviews"},
+		{"://broken", "://broken"},
+	}
+
+	for _, c := range cases {
+		if got := analytics.AppendClickID(c.destination, clickID); got != c.want {
+			t.Errorf("AppendClickID(%q) = %q, want %q", c.destination, got, c.want)
+		}
+	}
+}
+
+func newConversionUseCases(repo *memoryStatsRepository) *analytics.UseCases {
+	privacyUC := privacy.NewUseCases(&memoryPrivacySettings{}, euPolicy)
+	return analytics.NewUseCases(repo, nil, nil, analytics.NewRefererNormalizer(nil), nil, nil, privacyUC, &memoryDashboardCache{})
+}
+
+func TestConversion_Convert(t *testing.T) {
+	repo := &memoryStatsRepository{clicks: map[uuid.UUID]analytics.Click{}, conversions: map[uuid.UUID]analytics.Conversion{}}
+	uc := newConversionUseCases(repo)
+	ctx := context.Background()
+
+	click := &analytics.Click{ID: uuid.New(), URLDomain: "go.example", URLID: "abc", ClickedAt: time.Now().UTC()}
+	repo.AddClick(ctx, click)
+
+	value := 19.99
+	conversion, err := uc.Convert(ctx, click, &value)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if conversion.ClickID != click.ID || conversion.URLDomain != "go.example" || conversion.URLID != "abc" {
+		t.Errorf("conversion not attributed to the click: %+v", conversion)
+	}
+	if !conversion.ClickedAt.Equal(click.ClickedAt) || conversion.Value == nil || *conversion.Value != value {
+		t.Errorf("unexpected conversion: %+v", conversion)
+	}
+
+	if _, err := uc.Convert(ctx, click, nil); !errors.Is(err, analytics.ErrAlreadyConverted) {
+		t.Errorf("expected a click to convert once, got %v", err)
+	}
+}
+
+func TestConversion_InvalidValue(t *testing.T) {
+	repo := &memoryStatsRepository{clicks: map[uuid.UUID]analytics.Click{}, conversions: map[uuid.UUID]analytics.Conversion{}}
+	uc := newConversionUseCases(repo)
+
+	value := -1.0
+	click := &analytics.Click{ID: uuid.New()}
+	if _, err := uc.Convert(context.Background(), click, &value); !errors.Is(err, analytics.ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue, got %v", err)
+	}
+	if len(repo.conversions) != 0 {
+		t.Errorf("expected no conversion to be stored")
+	}
+}
+
+func TestConversion_Trackable(t *testing.T) {
+	uc := newConversionUseCases(&memoryStatsRepository{})
+
+	if !uc.Trackable(analytics.Visitor{}) {
+		t.Errorf("expected visitors without opt-out signals to be trackable")
+	}
+	if uc.Trackable(analytics.Visitor{DoNotTrack: "1"}) {
+		t.Errorf("expected DNT visitors not to be trackable")
+	}
+	if uc.Trackable(analytics.Visitor{GlobalPrivacyControl: "1"}) {
+		t.Errorf("expected GPC visitors not to be trackable")
+	}
+}
@@ -0,0 +1,225 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/analytics"
+)
+
+type memoryStatsRepository struct {
+	stats       []analytics.AccountStatistics
+	calls       int
+	clicks      map[uuid.UUID]analytics.Click
+	conversions map[uuid.UUID]analytics.Conversion
+}
+
+func (m *memoryStatsRepository) AddClick(ctx context.Context, click *analytics.Click) error {
+	m.clicks[click.ID] = *click
+	return nil
+}
+
+func (m *memoryStatsRepository) Click(ctx context.Context, id uuid.UUID) (*analytics.Click, error) {
+	click, ok := m.clicks[id]
+	if !ok {
+		return nil, analytics.ErrClickNotFound
+	}
+	return &click, nil
+}
+
+func (m *memoryStatsRepository) AddConversion(ctx context.Context, conversion *analytics.Conversion) error {
+	if _, ok := m.conversions[conversion.ClickID]; ok {
+		return analytics.ErrAlreadyConverted
+	}
+	conversion.ID = uuid.New()
+	m.conversions[conversion.ClickID] = *conversion
+	return nil
+}
+
+func (m *memoryStatsRepository) Stats(ctx context.Context, domain string, urlID string, filter analytics.StatsFilter) ([]analytics.UrlStatistics, error) {
+	return nil, nil
+}
+
+func (m *memoryStatsRepository) AccountStats(ctx context.Context, userID uuid.UUID, from time.Time, to time.Time) ([]analytics.AccountStatistics, error) {
+	m.calls++
+	stats := []analytics.AccountStatistics{}
+	for _, s := range m.stats {
+		if !s.Date.Before(from) && s.Date.Before(to) {
+			stats = append(stats, s)
+		}
+	}
+	return stats, nil
+}
+
+type memoryDashboardCache struct {
+	dashboards map[string]analytics.Dashboard
+	err        error
+}
+
+func (m *memoryDashboardCache) key(userID uuid.UUID, from time.Time, to time.Time) string {
+	return userID.String() + from.String() + to.String()
+}
+
+func (m *memoryDashboardCache) Get(ctx context.Context, userID uuid.UUID, from time.Time, to time.Time) (*analytics.Dashboard, bool, error) {
+	if m.err != nil {
+		return nil, false, m.err
+	}
+	dashboard, ok := m.dashboards[m.key(userID, from, to)]
+	return &dashboard, ok, nil
+}
+
+func (m *memoryDashboardCache) Set(ctx context.Context, userID uuid.UUID, dashboard *analytics.Dashboard) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.dashboards[m.key(userID, dashboard.From, dashboard.To)] = *dashboard
+	return nil
+}
+
+func dashboardFixture() ([]analytics.AccountStatistics, time.Time, time.Time) {
+	to := time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -3)
+	stats := []analytics.AccountStatistics{
+		{
+			Date:        from.AddDate(0, 0, 2),
+			TotalClicks: 5,
+			ByLink:      []analytics.ClicksByLink{{URLID: "b", Clicks: 4}, {URLID: "a", Clicks: 1}},
+			ByGeo:       []analytics.ClicksByGeo{{CountryCode: "DE", Clicks: 5}},
+			ByReferer:   []analytics.ClicksByReferer{{Referer: "news.example", Clicks: 2}},
+		},
+		{
+			Date:        from,
+			TotalClicks: 2,
+			ByLink:      []analytics.ClicksByLink{{URLID: "a", Clicks: 2}},
+			ByGeo:       []analytics.ClicksByGeo{{CountryCode: "FR", Clicks: 1}, {CountryCode: "DE", Clicks: 1}},
+			ByReferer:   []analytics.ClicksByReferer{},
+		},
+		// previous period
+		{
+			Date:        from.AddDate(0, 0, -1),
+			TotalClicks: 4,
+			ByLink:      []analytics.ClicksByLink{{URLID: "c", Clicks: 4}},
+			ByGeo:       []analytics.ClicksByGeo{},
+			ByReferer:   []analytics.ClicksByReferer{},
+		},
+	}
+
+	return stats, from, to
+}
+
+func TestDashboard_Summary(t *testing.T) {
+	stats, from, to := dashboardFixture()
+	repo := &memoryStatsRepository{stats: stats}
+	uc := analytics.NewUseCases(repo, nil, nil, analytics.NewRefererNormalizer(nil), nil, nil, nil, &memoryDashboardCache{dashboards: map[string]analytics.Dashboard{}})
+
+	dashboard, err := uc.Dashboard(context.Background(), uuid.New(), analytics.DashboardFilter{From: from, To: to})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if dashboard.TotalClicks != 7 {
+		t.Errorf("expected 7 clicks, got %d", dashboard.TotalClicks)
+	}
+	if len(dashboard.TopLinks) != 2 || dashboard.TopLinks[0].URLID != "b" || dashboard.TopLinks[1].Clicks != 3 {
+		t.Errorf("unexpected top links: %+v", dashboard.TopLinks)
+	}
+	if len(dashboard.TopCountries) != 2 || dashboard.TopCountries[0].CountryCode != "DE" || dashboard.TopCountries[0].Clicks != 6 {
+		t.Errorf("unexpected top countries: %+v", dashboard.TopCountries)
+	}
+	if len(dashboard.TopReferers) != 1 || dashboard.TopReferers[0].Clicks != 2 || dashboard.TopReferers[0].SourceType != analytics.SOURCE_REFERRAL {
+		t.Errorf("unexpected top referers: %+v", dashboard.TopReferers)
+	}
+
+	wantTrend := []int{2, 0, 5}
+	if len(dashboard.Trend) != len(wantTrend) {
+		t.Fatalf("expected %d days of trend, got %+v", len(wantTrend), dashboard.Trend)
+	}
+	for i, clicks := range wantTrend {
+		if !dashboard.Trend[i].Date.Equal(from.AddDate(0, 0, i)) || dashboard.Trend[i].Clicks != clicks {
+			t.Errorf("unexpected trend day %d: %+v", i, dashboard.Trend[i])
+		}
+	}
+
+	comparison := dashboard.Comparison
+	if comparison.PreviousClicks != 4 || !comparison.PreviousFrom.Equal(from.AddDate(0, 0, -3)) {
+		t.Errorf("unexpected comparison: %+v", comparison)
+	}
+	if comparison.GrowthPercent == nil || *comparison.GrowthPercent != 75 {
+		t.Errorf("expected 75%% growth, got %v", comparison.GrowthPercent)
+	}
+}
+
+func TestDashboard_NoPreviousClicks(t *testing.T) {
+	stats, from, to := dashboardFixture()
+	repo := &memoryStatsRepository{stats: stats[:2]}
+	uc := analytics.NewUseCases(repo, nil, nil, analytics.NewRefererNormalizer(nil), nil, nil, nil, &memoryDashboardCache{dashboards: map[string]analytics.Dashboard{}})
+
+	dashboard, err := uc.Dashboard(context.Background(), uuid.New(), analytics.DashboardFilter{From: from, To: to})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dashboard.Comparison.GrowthPercent != nil {
+		t.Errorf("expected no growth without previous clicks, got %v", *dashboard.Comparison.GrowthPercent)
+	}
+}
+
+func TestDashboard_Cached(t *testing.T) {
+	stats, from, to := dashboardFixture()
+	repo := &memoryStatsRepository{stats: stats}
+	uc := analytics.NewUseCases(repo, nil, nil, analytics.NewRefererNormalizer(nil), nil, nil, nil, &memoryDashboardCache{dashboards: map[string]analytics.Dashboard{}})
+	userID := uuid.New()
+
+	if _, err := uc.Dashboard(context.Background(), userID, analytics.DashboardFilter{From: from, To: to}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dashboard, err := uc.Dashboard(context.Background(), userID, analytics.DashboardFilter{From: from, To: to, Limit: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if repo.calls != 1 {
+		t.Errorf("expected the second dashboard to be cached, stats read %d times", repo.calls)
+	}
+	if len(dashboard.TopLinks) != 1 || dashboard.TopLinks[0].URLID != "b" {
+		t.Errorf("expected cached top links to be limited: %+v", dashboard.TopLinks)
+	}
+}
+
+func TestDashboard_CacheFailure(t *testing.T) {
+	stats, from, to := dashboardFixture()
+	repo := &memoryStatsRepository{stats: stats}
+	uc := analytics.NewUseCases(repo, nil, nil, analytics.NewRefererNormalizer(nil), nil, nil, nil, &memoryDashboardCache{err: errors.New("redis down")})
+
+	dashboard, err := uc.Dashboard(context.Background(), uuid.New(), analytics.DashboardFilter{From: from, To: to})
+	if err != nil {
+		t.Fatalf("expected cache failures to be ignored, got %v", err)
+	}
+	if dashboard.TotalClicks != 7 {
+		t.Errorf("expected 7 clicks, got %d", dashboard.TotalClicks)
+	}
+}
+
+func TestDashboard_InvalidFilter(t *testing.T) {
+	_, from, to := dashboardFixture()
+	uc := analytics.NewUseCases(&memoryStatsRepository{}, nil, nil, analytics.NewRefererNormalizer(nil), nil, nil, nil, &memoryDashboardCache{dashboards: map[string]analytics.Dashboard{}})
+
+	cases := []struct {
+		name   string
+		filter analytics.DashboardFilter
+		want   error
+	}{
+		{"reversed", analytics.DashboardFilter{From: to, To: from}, analytics.ErrInvalidRange},
+		{"too long", analytics.DashboardFilter{From: to.AddDate(-2, 0, 0), To: to}, analytics.ErrInvalidRange},
+		{"negative limit", analytics.DashboardFilter{From: from, To: to, Limit: -1}, analytics.ErrInvalidLimit},
+		{"limit too high", analytics.DashboardFilter{From: from, To: to, Limit: analytics.DASHBOARD_MAX_LIMIT + 1}, analytics.ErrInvalidLimit},
+	}
+
+	for _, c := range cases {
+		if _, err := uc.Dashboard(context.Background(), uuid.New(), c.filter); !errors.Is(err, c.want) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, err)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"roadmap.restapi/internal/analytics"
+)
+
+type recordingRollups struct {
+	until       time.Time
+	purgeBefore time.Time
+}
+
+func (r *recordingRollups) RollUp(ctx context.Context, until time.Time, purgeBefore time.Time) (bool, error) {
+	r.until = until
+	r.purgeBefore = purgeBefore
+	return true, nil
+}
+
+func TestRollup_RollUp(t *testing.T) {
+	now := time.Date(2026, 2, 14, 10, 3, 0, 0, time.UTC)
+
+	repo := &recordingRollups{}
+	if err := analytics.NewRollup(repo, 720*time.Hour, 5*time.Minute).RollUp(context.Background(), now); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	// 10:00 - 10:03 is still within the delay, so only 09:00 is closed
+	if want := time.Date(2026, 2, 14, 9, 0, 0, 0, time.UTC); !repo.until.Equal(want) {
+		t.Errorf("until = %s, want %s", repo.until, want)
+	}
+	if want := now.Add(-720 * time.Hour); !repo.purgeBefore.Equal(want) {
+		t.Errorf("purgeBefore = %s, want %s", repo.purgeBefore, want)
+	}
+
+	repo = &recordingRollups{}
+	if err := analytics.NewRollup(repo, 0, 5*time.Minute).RollUp(context.Background(), now); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if !repo.purgeBefore.IsZero() {
+		t.Errorf("expected no purge without retention, got %s", repo.purgeBefore)
+	}
+}
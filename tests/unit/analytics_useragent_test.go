@@ -0,0 +1,95 @@
+package unit
+
+import (
+	"testing"
+
+	"roadmap.restapi/internal/analytics"
+)
+
+func TestRegexUserAgentParser_Parse(t *testing.T) {
+	cases := []struct {
+		name string
+		ua   string
+		want analytics.UserAgent
+	}{
+		{
+			"chrome on windows",
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			analytics.UserAgent{Device: analytics.DEVICE_DESKTOP, OS: "Windows", Browser: "Chrome"},
+		},
+		{
+			"edge on windows",
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91",
+			analytics.UserAgent{Device: analytics.DEVICE_DESKTOP, OS: "Windows", Browser: "Edge"},
+		},
+		{
+			"safari on mac",
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Safari/605.1.15",
+			analytics.UserAgent{Device: analytics.DEVICE_DESKTOP, OS: "Mac OS", Browser: "Safari"},
+		},
+		{
+			"firefox on linux",
+			"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+			analytics.UserAgent{Device: analytics.DEVICE_DESKTOP, OS: "Linux", Browser: "Firefox"},
+		},
+		{
+			"chrome on chromebook",
+			"Mozilla/5.0 (X11; CrOS x86_64 14541.0.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			analytics.UserAgent{Device: analytics.DEVICE_DESKTOP, OS: "Chrome OS", Browser: "Chrome"},
+		},
+		{
+			"safari on iphone",
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Mobile/15E148 Safari/604.1",
+			analytics.UserAgent{Device: analytics.DEVICE_MOBILE, OS: "iOS", Browser: "Safari"},
+		},
+		{
+			"chrome on iphone",
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/120.0.6099.119 Mobile/15E148 Safari/604.1",
+			analytics.UserAgent{Device: analytics.DEVICE_MOBILE, OS: "iOS", Browser: "Chrome"},
+		},
+		{
+			"safari on ipad",
+			"Mozilla/5.0 (iPad; CPU OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Mobile/15E148 Safari/604.1",
+			analytics.UserAgent{Device: analytics.DEVICE_TABLET, OS: "iOS", Browser: "Safari"},
+		},
+		{
+			"chrome on android phone",
+			"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.144 Mobile Safari/537.36",
+			analytics.UserAgent{Device: analytics.DEVICE_MOBILE, OS: "Android", Browser: "Chrome"},
+		},
+		{
+			"samsung internet on android tablet",
+			"Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/23.0 Chrome/115.0.0.0 Safari/537.36",
+			analytics.UserAgent{Device: analytics.DEVICE_TABLET, OS: "Android", Browser: "Samsung Internet"},
+		},
+		{
+			"opera on windows",
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 OPR/106.0.0.0",
+			analytics.UserAgent{Device: analytics.DEVICE_DESKTOP, OS: "Windows", Browser: "Opera"},
+		},
+		{
+			"internet explorer",
+			"Mozilla/5.0 (Windows NT 10.0; WOW64; Trident/7.0; rv:11.0) like Gecko",
+			analytics.UserAgent{Device: analytics.DEVICE_DESKTOP, OS: "Windows", Browser: "Internet Explorer"},
+		},
+		{
+			"curl",
+			"curl/8.4.0",
+			analytics.UserAgent{Device: analytics.DEVICE_OTHER, OS: analytics.FAMILY_OTHER, Browser: analytics.FAMILY_OTHER},
+		},
+		{
+			"empty",
+			"",
+			analytics.UserAgent{Device: analytics.DEVICE_OTHER, OS: analytics.FAMILY_OTHER, Browser: analytics.FAMILY_OTHER},
+		},
+	}
+
+	parser := analytics.NewRegexUserAgentParser()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := parser.Parse(c.ua); got != c.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", c.ua, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package unit
+
+import (
+	"testing"
+
+	"roadmap.restapi/internal/analytics"
+)
+
+func TestVisitor_Fingerprint(t *testing.T) {
+	visitor := analytics.Visitor{IP: "203.0.113.7", UserAgent: "Mozilla/5.0"}
+
+	fp := visitor.Fingerprint("salt-1")
+	if fp != visitor.Fingerprint("salt-1") {
+		t.Error("fingerprint is not stable for the same salt")
+	}
+	if fp == visitor.Fingerprint("salt-2") {
+		t.Error("fingerprint does not change with the salt")
+	}
+
+	other := analytics.Visitor{IP: "203.0.113.8", UserAgent: "Mozilla/5.0"}
+	if fp == other.Fingerprint("salt-1") {
+		t.Error("different visitors share a fingerprint")
+	}
+
+	// fields are separated, moving bytes between them is another visitor
+	shifted := analytics.Visitor{IP: "203.0.113.7M", UserAgent: "ozilla/5.0"}
+	if fp == shifted.Fingerprint("salt-1") {
+		t.Error("fingerprint does not separate ip and user agent")
+	}
+}
@@ -0,0 +1,88 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/api/middleware"
+	"roadmap.restapi/internal/config"
+	"roadmap.restapi/internal/token"
+	"roadmap.restapi/internal/user"
+)
+
+type memoryUsers struct {
+	users map[uuid.UUID]*user.User
+}
+
+func (m *memoryUsers) Create(ctx context.Context, u *user.User) error {
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *memoryUsers) Update(ctx context.Context, u *user.User) error {
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *memoryUsers) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(m.users, id)
+	return nil
+}
+
+func (m *memoryUsers) ByID(ctx context.Context, id uuid.UUID) (*user.User, error) {
+	u, ok := m.users[id]
+	if !ok {
+		return nil, user.ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (m *memoryUsers) ByEmail(ctx context.Context, email string) (*user.User, error) {
+	for _, u := range m.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, user.ErrUserNotFound
+}
+
+func TestAuth_RejectsRefreshTokens(t *testing.T) {
+	config.SetCfg(&config.Config{TokensConfig: config.TokensConfig{Sources: []string{middleware.TOKEN_SOURCE_HEADER}}})
+	secret := []byte(testTokenSecret)
+	generator := token.NewJWTGenerator(secret, jwt.SigningMethodHS256, time.Minute, time.Hour)
+	extractor := token.NewJWTClaimsExtractor(secret, jwt.SigningMethodHS256)
+
+	uid := uuid.New()
+	users := &memoryUsers{users: map[uuid.UUID]*user.User{uid: {ID: uid}}}
+	auth := middleware.Auth(extractor, users)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	cases := []struct {
+		tokenType token.TokenType
+		want      int
+	}{
+		{token.ACCESS, http.StatusOK},
+		{token.REFRESH, http.StatusUnauthorized},
+	}
+	for _, c := range cases {
+		tokenStr, err := generator.Generate(context.Background(), &token.UserClaims{UID: uid}, c.tokenType, uuid.New())
+		if err != nil {
+			t.Fatalf("failed to generate token: %v", err)
+		}
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "Bearer "+tokenStr)
+		w := httptest.NewRecorder()
+		auth.ServeHTTP(w, r)
+
+		if w.Code != c.want {
+			t.Errorf("token type %v: expected status %d, got %d", c.tokenType, c.want, w.Code)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package unit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"roadmap.restapi/internal/api/middleware"
+)
+
+func TestToken_Precedence(t *testing.T) {
+	both := httptest.NewRequest(http.MethodGet, "/", nil)
+	both.Header.Set("Authorization", "Bearer header-token")
+	both.AddCookie(&http.Cookie{Name: middleware.COOKIE_ACCESS, Value: "cookie-token"})
+
+	cookieOnly := httptest.NewRequest(http.MethodGet, "/", nil)
+	cookieOnly.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	cookieOnly.AddCookie(&http.Cookie{Name: middleware.COOKIE_ACCESS, Value: "cookie-token"})
+
+	headerOnly := httptest.NewRequest(http.MethodGet, "/", nil)
+	headerOnly.Header.Set("Authorization", "bearer  header-token ")
+
+	cases := []struct {
+		name    string
+		r       *http.Request
+		sources []string
+		want    string
+	}{
+		{"header first", both, []string{middleware.TOKEN_SOURCE_HEADER, middleware.TOKEN_SOURCE_COOKIE}, "header-token"},
+		{"cookie first", both, []string{middleware.TOKEN_SOURCE_COOKIE, middleware.TOKEN_SOURCE_HEADER}, "cookie-token"},
+		{"other scheme falls through", cookieOnly, []string{middleware.TOKEN_SOURCE_HEADER, middleware.TOKEN_SOURCE_COOKIE}, "cookie-token"},
+		{"scheme is case insensitive", headerOnly, []string{middleware.TOKEN_SOURCE_COOKIE, middleware.TOKEN_SOURCE_HEADER}, "header-token"},
+	}
+	for _, c := range cases {
+		got, err := middleware.Token(c.r, middleware.COOKIE_ACCESS, c.sources)
+		if err != nil || got != c.want {
+			t.Errorf("%s: expected %q, got %q, %v", c.name, c.want, got, err)
+		}
+	}
+}
+
+func TestToken_DisabledSource(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer header-token")
+
+	if _, err := middleware.Token(r, middleware.COOKIE_ACCESS, []string{middleware.TOKEN_SOURCE_COOKIE}); !errors.Is(err, middleware.ErrNotAuthenticated) {
+		t.Errorf("expected ErrNotAuthenticated, got %v", err)
+	}
+}
+
+func TestCheckTokenSources(t *testing.T) {
+	if err := middleware.CheckTokenSources([]string{"header", "cookie"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	for _, sources := range [][]string{nil, {"header", "query"}} {
+		if err := middleware.CheckTokenSources(sources); !errors.Is(err, middleware.ErrUnknownTokenSource) {
+			t.Errorf("%v: expected ErrUnknownTokenSource, got %v", sources, err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package unit
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"roadmap.restapi/internal/api/handlers"
+)
+
+func TestClientIPResolver_IP(t *testing.T) {
+	resolver, err := handlers.NewClientIPResolver("X-Forwarded-For", []string{"10.0.0.0/8", "192.0.2.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name      string
+		remote    string
+		forwarded string
+		want      string
+	}{
+		{"direct connection ignores header", "203.0.113.7:5000", "198.51.100.1", "203.0.113.7"},
+		{"trusted proxy", "10.1.2.3:5000", "198.51.100.1", "198.51.100.1"},
+		{"spoofed left entries", "10.1.2.3:5000", "1.2.3.4, 198.51.100.1", "198.51.100.1"},
+		{"chain of trusted proxies", "192.0.2.1:5000", "1.2.3.4, 198.51.100.1, 10.0.0.5", "198.51.100.1"},
+		{"no header", "10.1.2.3:5000", "", "10.1.2.3"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = c.remote
+			if c.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", c.forwarded)
+			}
+
+			if got := resolver.IP(r); got != c.want {
+				t.Errorf("got %s, want %s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestClientIPResolver_WithoutHeaderUsesRemoteAddr(t *testing.T) {
+	resolver, err := handlers.NewClientIPResolver("", []string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.1.2.3:5000"
+	r.Header.Set("X-Forwarded-For", "1.2.3.4")
+
+	if got := resolver.IP(r); got != "10.1.2.3" {
+		t.Errorf("got %s, want the connection address", got)
+	}
+}
+
+func TestClientIPResolver_InvalidProxy(t *testing.T) {
+	if _, err := handlers.NewClientIPResolver("X-Forwarded-For", []string{"not-an-ip"}); err == nil {
+		t.Error("expected an error for an invalid proxy")
+	}
+}
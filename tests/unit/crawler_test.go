@@ -0,0 +1,34 @@
+package unit
+
+import (
+	"testing"
+
+	"roadmap.restapi/internal/crawler"
+)
+
+func TestIsPreviewBot(t *testing.T) {
+	cases := []struct {
+		ua   string
+		want bool
+	}{
+		{"facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)", true},
+		{"Mozilla/5.0 (compatible; Discordbot/2.0; +https://discordapp.com)", true},
+		{"Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)", true},
+		{"TelegramBot (like TwitterBot)", true},
+		{"WhatsApp/2.23.20.0", true},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36", false},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148", false},
+		{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", false},
+		{"Mozilla/5.0 (compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm)", false},
+		{"Mozilla/5.0 (compatible; YandexBot/3.0; +http://yandex.com/bots)", false},
+		{"DuckDuckBot/1.1; (+http://duckduckgo.com/duckduckbot.html)", false},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_5) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/13.1.1 Safari/605.1.15 (Applebot/0.1; +http://www.apple.com/go/applebot)", false},
+		{"", false},
+	}
+
+	for _, c := range cases {
+		if got := crawler.IsPreviewBot(c.ua); got != c.want {
+			t.Errorf("IsPreviewBot(%q) = %v, want %v", c.ua, got, c.want)
+		}
+	}
+}
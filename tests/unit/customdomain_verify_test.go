@@ -0,0 +1,243 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/customdomain"
+)
+
+type memoryDomains struct {
+	domains map[uuid.UUID]*customdomain.Domain
+}
+
+func newMemoryDomains() *memoryDomains {
+	return &memoryDomains{domains: map[uuid.UUID]*customdomain.Domain{}}
+}
+
+func (m *memoryDomains) Create(ctx context.Context, domain *customdomain.Domain) error {
+	domain.ID = uuid.New()
+	domain.CreatedAt = time.Now().UTC()
+	stored := *domain
+	m.domains[domain.ID] = &stored
+	return nil
+}
+
+func (m *memoryDomains) ByID(ctx context.Context, id uuid.UUID) (*customdomain.Domain, error) {
+	d, ok := m.domains[id]
+	if !ok {
+		return nil, customdomain.ErrDomainNotFound
+	}
+	found := *d
+	return &found, nil
+}
+
+func (m *memoryDomains) ByUser(ctx context.Context, userID uuid.UUID) ([]customdomain.Domain, error) {
+	list := []customdomain.Domain{}
+	for _, d := range m.domains {
+		if d.UserID == userID {
+			list = append(list, *d)
+		}
+	}
+	return list, nil
+}
+
+func (m *memoryDomains) Verified(ctx context.Context, name string) (*customdomain.Domain, error) {
+	for _, d := range m.domains {
+		if d.Name == name && d.IsVerified() {
+			found := *d
+			return &found, nil
+		}
+	}
+	return nil, customdomain.ErrDomainNotFound
+}
+
+func (m *memoryDomains) MarkVerified(ctx context.Context, id uuid.UUID, at time.Time) error {
+	d, ok := m.domains[id]
+	if !ok {
+		return customdomain.ErrDomainNotFound
+	}
+	d.VerifiedAt = &at
+	return nil
+}
+
+func (m *memoryDomains) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(m.domains, id)
+	return nil
+}
+
+type memoryHostCache struct {
+	hosts map[string]*customdomain.Domain
+}
+
+func newMemoryHostCache() *memoryHostCache {
+	return &memoryHostCache{hosts: map[string]*customdomain.Domain{}}
+}
+
+func (c *memoryHostCache) Get(ctx context.Context, host string) (*customdomain.Domain, bool, error) {
+	domain, ok := c.hosts[host]
+	return domain, ok, nil
+}
+
+func (c *memoryHostCache) Set(ctx context.Context, host string, domain *customdomain.Domain) error {
+	c.hosts[host] = domain
+	return nil
+}
+
+func (c *memoryHostCache) Forget(ctx context.Context, host string) error {
+	delete(c.hosts, host)
+	return nil
+}
+
+type stubResolver struct {
+	records map[string][]string
+}
+
+func (r *stubResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	records, ok := r.records[name]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return records, nil
+}
+
+func TestCustomDomain_Verify(t *testing.T) {
+	resolver := &stubResolver{records: map[string][]string{}}
+	domains := customdomain.NewUseCases(newMemoryDomains(), resolver, newMemoryHostCache())
+	ctx := context.Background()
+	uid := uuid.New()
+
+	d, err := domains.Register(ctx, uid, "Go.Brand.com.")
+	if err != nil {
+		t.Fatalf("failed to register domain: %v", err)
+	}
+	if d.Name != "go.brand.com" {
+		t.Errorf("name is not normalized: %s", d.Name)
+	}
+
+	if _, err = domains.Verify(ctx, uid, d.ID); !errors.Is(err, customdomain.ErrVerificationFailed) {
+		t.Fatalf("expected ErrVerificationFailed without record, got %v", err)
+	}
+
+	resolver.records[d.RecordName()] = []string{"v=spf1 -all", "shortener-verification=wrong"}
+	if _, err = domains.Verify(ctx, uid, d.ID); !errors.Is(err, customdomain.ErrVerificationFailed) {
+		t.Fatalf("expected ErrVerificationFailed with wrong token, got %v", err)
+	}
+
+	if allowed, _ := domains.IsVerifiedOwner(ctx, uid, "go.brand.com"); allowed {
+		t.Error("unverified domain should not allow links")
+	}
+
+	resolver.records[d.RecordName()] = append(resolver.records[d.RecordName()], d.RecordValue())
+	verified, err := domains.Verify(ctx, uid, d.ID)
+	if err != nil {
+		t.Fatalf("failed to verify domain: %v", err)
+	}
+	if !verified.IsVerified() {
+		t.Error("domain should be verified")
+	}
+
+	if allowed, _ := domains.IsVerifiedOwner(ctx, uid, "go.brand.com"); !allowed {
+		t.Error("verified domain should allow links of its owner")
+	}
+	if allowed, _ := domains.IsVerifiedOwner(ctx, uuid.New(), "go.brand.com"); allowed {
+		t.Error("verified domain should not allow links of other users")
+	}
+}
+
+func TestCustomDomain_Verify_NotOwner(t *testing.T) {
+	domains := customdomain.NewUseCases(newMemoryDomains(), &stubResolver{}, newMemoryHostCache())
+	ctx := context.Background()
+
+	d, err := domains.Register(ctx, uuid.New(), "brand.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = domains.Verify(ctx, uuid.New(), d.ID); !errors.Is(err, customdomain.ErrUserIsNotOwner) {
+		t.Errorf("expected ErrUserIsNotOwner, got %v", err)
+	}
+}
+
+func TestCustomDomain_Register_Invalid(t *testing.T) {
+	domains := customdomain.NewUseCases(newMemoryDomains(), &stubResolver{}, newMemoryHostCache())
+
+	for _, name := range []string{"", "localhost", "-bad.com", "bad_domain.com", "a..com"} {
+		if _, err := domains.Register(context.Background(), uuid.New(), name); !errors.Is(err, customdomain.ErrInvalidDomain) {
+			t.Errorf("%q: expected ErrInvalidDomain, got %v", name, err)
+		}
+	}
+}
+
+func TestCustomDomain_ResolveHost(t *testing.T) {
+	resolver := &stubResolver{records: map[string][]string{}}
+	domains := customdomain.NewUseCases(newMemoryDomains(), resolver, newMemoryHostCache())
+	ctx := context.Background()
+	uid := uuid.New()
+
+	d, _ := domains.Register(ctx, uid, "go.brand.com")
+	resolver.records[d.RecordName()] = []string{d.RecordValue()}
+	if _, err := domains.Verify(ctx, uid, d.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"go.brand.com":      "go.brand.com",
+		"GO.BRAND.COM:8080": "go.brand.com",
+		"localhost:8000":    "",
+		"other.com":         "",
+	}
+	for host, want := range tests {
+		resolved, err := domains.ResolveHost(ctx, host)
+		if err != nil {
+			t.Fatalf("%s: %v", host, err)
+		}
+
+		got := ""
+		if resolved != nil {
+			got = resolved.Name
+		}
+		if got != want {
+			t.Errorf("%s: got %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestCustomDomain_ResolveHost_CachedUntilDeleted(t *testing.T) {
+	resolver := &stubResolver{records: map[string][]string{}}
+	repo := newMemoryDomains()
+	domains := customdomain.NewUseCases(repo, resolver, newMemoryHostCache())
+	ctx := context.Background()
+	uid := uuid.New()
+
+	if resolved, _ := domains.ResolveHost(ctx, "go.brand.com"); resolved != nil {
+		t.Fatalf("expected unknown host to resolve to nil, got %v", resolved)
+	}
+
+	d, _ := domains.Register(ctx, uid, "go.brand.com")
+	resolver.records[d.RecordName()] = []string{d.RecordValue()}
+	if _, err := domains.Verify(ctx, uid, d.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if resolved, _ := domains.ResolveHost(ctx, "go.brand.com"); resolved == nil {
+		t.Fatal("expected verification to replace the cached miss")
+	}
+
+	// served from the cache even if the repository does not know the domain
+	delete(repo.domains, d.ID)
+	if resolved, _ := domains.ResolveHost(ctx, "go.brand.com"); resolved == nil {
+		t.Fatal("expected the resolved domain to be cached")
+	}
+
+	repo.domains[d.ID] = d
+	if err := domains.Delete(ctx, uid, d.ID); err != nil {
+		t.Fatal(err)
+	}
+	if resolved, _ := domains.ResolveHost(ctx, "go.brand.com"); resolved != nil {
+		t.Errorf("expected deleted domain not to resolve, got %v", resolved)
+	}
+}
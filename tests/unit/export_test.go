@@ -0,0 +1,339 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/export"
+)
+
+func TestExportWriter(t *testing.T) {
+	clickedAt := time.Date(2026, 2, 18, 10, 30, 0, 0, time.UTC)
+	rows := [][]any{
+		{"sale", clickedAt, "DE", true, int64(3)},
+		{"sale", clickedAt, nil, false, int64(1)},
+		{"sale", clickedAt, `say "hi", bye`, false, int64(0)},
+		{"sale", clickedAt, "=HYPERLINK(\"x\")", false, int64(2)},
+	}
+	columns := []string{"url_id", "clicked_at", "referer", "is_bot", "clicks"}
+
+	cases := []struct {
+		format export.Format
+		want   string
+	}{
+		{export.FORMAT_CSV, "url_id,clicked_at,referer,is_bot,clicks\n" +
+			"sale,2026-02-18T10:30:00Z,DE,true,3\n" +
+			"sale,2026-02-18T10:30:00Z,,false,1\n" +
+			"sale,2026-02-18T10:30:00Z,\"say \"\"hi\"\", bye\",false,0\n" +
+			"sale,2026-02-18T10:30:00Z,\"'=HYPERLINK(\"\"x\"\")\",false,2\n"},
+		{export.FORMAT_NDJSON, `{"url_id":"sale","clicked_at":"2026-02-18T10:30:00Z","referer":"DE","is_bot":true,"clicks":3}` + "\n" +
+			`{"url_id":"sale","clicked_at":"2026-02-18T10:30:00Z","referer":null,"is_bot":false,"clicks":1}` + "\n" +
+			`{"url_id":"sale","clicked_at":"2026-02-18T10:30:00Z","referer":"say \"hi\", bye","is_bot":false,"clicks":0}` + "\n" +
+			`{"url_id":"sale","clicked_at":"2026-02-18T10:30:00Z","referer":"=HYPERLINK(\"x\")","is_bot":false,"clicks":2}` + "\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := export.NewWriter(c.format, &buf, columns)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			for _, row := range rows {
+				if err := w.WriteRow(row); err != nil {
+					t.Fatalf("unexpected err: %v", err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if buf.String() != c.want {
+				t.Errorf("got\n%s\nwant\n%s", buf.String(), c.want)
+			}
+		})
+	}
+
+	if _, err := export.NewWriter("xml", io.Discard, columns); !errors.Is(err, export.ErrInvalidFormat) {
+		t.Errorf("expected ErrInvalidFormat, got %v", err)
+	}
+}
+
+func TestExport_Validate(t *testing.T) {
+	uc := export.NewUseCases(nil, nil, nil, 10)
+	day := time.Date(2026, 2, 18, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name string
+		q    export.Query
+		want error
+	}{
+		{"defaults", export.Query{Format: export.FORMAT_CSV, Granularity: export.GRANULARITY_RAW}, nil},
+		{"rollup columns", export.Query{Format: export.FORMAT_NDJSON, Granularity: export.GRANULARITY_DAILY, Columns: []string{"bucket", "clicks"}}, nil},
+		{"format", export.Query{Format: "xml", Granularity: export.GRANULARITY_RAW}, export.ErrInvalidFormat},
+		{"granularity", export.Query{Format: export.FORMAT_CSV, Granularity: "weekly"}, export.ErrInvalidGranularity},
+		{"unknown column", export.Query{Format: export.FORMAT_CSV, Granularity: export.GRANULARITY_RAW, Columns: []string{"password_hash"}}, export.ErrInvalidColumn},
+		{"raw column of rollup", export.Query{Format: export.FORMAT_CSV, Granularity: export.GRANULARITY_HOURLY, Columns: []string{"clicked_at"}}, export.ErrInvalidColumn},
+		{"range", export.Query{Format: export.FORMAT_CSV, Granularity: export.GRANULARITY_RAW, From: day, To: day}, export.ErrInvalidRange},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			q := c.q
+			if err := uc.Validate(&q); !errors.Is(err, c.want) {
+				t.Fatalf("got %v, want %v", err, c.want)
+			}
+			if c.want == nil && (len(q.Columns) == 0 || q.To.IsZero()) {
+				t.Errorf("defaults not filled in: %+v", q)
+			}
+		})
+	}
+}
+
+type memoryRows struct {
+	rows [][]any
+	err  error
+}
+
+func (m *memoryRows) Count(ctx context.Context, q export.Query) (int64, error) {
+	return int64(len(m.rows)), nil
+}
+
+func (m *memoryRows) Rows(ctx context.Context, q export.Query, fn func(values []any) error) error {
+	for _, row := range m.rows {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return m.err
+}
+
+type memoryJobs struct {
+	jobs map[uuid.UUID]*export.Job
+}
+
+func (m *memoryJobs) Create(ctx context.Context, job *export.Job) error {
+	job.ID = uuid.New()
+	job.CreatedAt = time.Now().UTC()
+	m.jobs[job.ID] = job
+	return nil
+}
+
+func (m *memoryJobs) ByID(ctx context.Context, id uuid.UUID) (*export.Job, error) {
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, export.ErrJobNotFound
+	}
+	return job, nil
+}
+
+func (m *memoryJobs) ByUser(ctx context.Context, userID uuid.UUID) ([]export.Job, error) {
+	jobs := []export.Job{}
+	for _, job := range m.jobs {
+		if job.UserID == userID {
+			jobs = append(jobs, *job)
+		}
+	}
+	return jobs, nil
+}
+
+func (m *memoryJobs) ClaimPending(ctx context.Context, now time.Time, staleBefore time.Time, maxAttempts int) (*export.Job, error) {
+	for _, job := range m.jobs {
+		stale := job.Status == export.STATUS_RUNNING && job.HeartbeatAt != nil && job.HeartbeatAt.Before(staleBefore)
+		if job.Status == export.STATUS_PENDING || (stale && job.Attempts < maxAttempts) {
+			job.Status = export.STATUS_RUNNING
+			job.StartedAt = &now
+			job.HeartbeatAt = &now
+			job.Attempts++
+			return job, nil
+		}
+	}
+	return nil, export.ErrJobNotFound
+}
+
+func (m *memoryJobs) FailAbandoned(ctx context.Context, now time.Time, staleBefore time.Time, maxAttempts int, reason string) error {
+	for _, job := range m.jobs {
+		stale := job.Status == export.STATUS_RUNNING && job.HeartbeatAt != nil && job.HeartbeatAt.Before(staleBefore)
+		if stale && job.Attempts >= maxAttempts {
+			job.Status = export.STATUS_FAILED
+			job.Error = &reason
+			job.FinishedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *memoryJobs) Heartbeat(ctx context.Context, id uuid.UUID, attempt int, at time.Time) error {
+	job, ok := m.jobs[id]
+	if !ok || job.Status != export.STATUS_RUNNING || job.Attempts != attempt {
+		return export.ErrJobLeaseLost
+	}
+	job.HeartbeatAt = &at
+	return nil
+}
+
+func (m *memoryJobs) Finish(ctx context.Context, job *export.Job) error {
+	m.jobs[job.ID] = job
+	return nil
+}
+
+func (m *memoryJobs) FinishedBefore(ctx context.Context, t time.Time) ([]export.Job, error) {
+	jobs := []export.Job{}
+	for _, job := range m.jobs {
+		if job.FinishedAt != nil && job.FinishedAt.Before(t) {
+			jobs = append(jobs, *job)
+		}
+	}
+	return jobs, nil
+}
+
+func (m *memoryJobs) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(m.jobs, id)
+	return nil
+}
+
+type memoryFile struct {
+	bytes.Buffer
+}
+
+func (f *memoryFile) Close() error { return nil }
+
+type memoryFiles map[string]*memoryFile
+
+func (m memoryFiles) Create(name string) (io.WriteCloser, error) {
+	m[name] = &memoryFile{}
+	return m[name], nil
+}
+
+func (m memoryFiles) Open(name string) (io.ReadCloser, error) {
+	f, ok := m[name]
+	if !ok {
+		return nil, errors.New("no such file")
+	}
+	return io.NopCloser(bytes.NewReader(f.Bytes())), nil
+}
+
+func (m memoryFiles) Rename(from string, to string) error {
+	f, ok := m[from]
+	if !ok {
+		return errors.New("no such file")
+	}
+	m[to] = f
+	delete(m, from)
+	return nil
+}
+
+func (m memoryFiles) Remove(name string) error {
+	delete(m, name)
+	return nil
+}
+
+func TestExportWorker(t *testing.T) {
+	ctx := context.Background()
+	rows := &memoryRows{rows: [][]any{{"sale", int64(2)}, {"promo", int64(1)}}}
+	jobs := &memoryJobs{jobs: map[uuid.UUID]*export.Job{}}
+	files := memoryFiles{}
+	uc := export.NewUseCases(rows, jobs, files, 1)
+	worker := export.NewWorker(uc, time.Hour)
+	owner := uuid.New()
+
+	q := export.Query{UserID: owner, Format: export.FORMAT_CSV, Granularity: export.GRANULARITY_DAILY, Columns: []string{"url_id", "clicks"}}
+	if err := uc.Validate(&q); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if large, _ := uc.IsLarge(ctx, q); !large {
+		t.Error("export over the threshold should run in background")
+	}
+
+	job, err := uc.Schedule(ctx, q)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if _, _, err := uc.Download(ctx, owner, job.ID); !errors.Is(err, export.ErrJobNotFinished) {
+		t.Errorf("expected ErrJobNotFinished, got %v", err)
+	}
+
+	if err := worker.RunPending(ctx); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if _, _, err := uc.Download(ctx, uuid.New(), job.ID); !errors.Is(err, export.ErrUserIsNotOwner) {
+		t.Errorf("expected ErrUserIsNotOwner, got %v", err)
+	}
+	done, file, err := uc.Download(ctx, owner, job.ID)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	content, _ := io.ReadAll(file)
+	if done.RowCount != 2 || string(content) != "url_id,clicks\nsale,2\npromo,1\n" {
+		t.Errorf("unexpected result: %d rows, %q", done.RowCount, content)
+	}
+
+	// a failing export is marked failed and leaves no file behind
+	rows.err = errors.New("connection reset")
+	failed, _ := uc.Schedule(ctx, q)
+	if err := worker.RunPending(ctx); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if failed.Status != export.STATUS_FAILED || failed.Error == nil || !strings.Contains(*failed.Error, "connection reset") {
+		t.Errorf("expected failed job, got %+v", failed)
+	}
+	if _, ok := files[failed.FileName()]; ok {
+		t.Error("file of failed job was kept")
+	}
+	if _, ok := files[failed.TempFileName(failed.Attempts)]; ok {
+		t.Error("file of failed attempt was kept")
+	}
+
+	if err := worker.Cleanup(ctx, time.Now().UTC().Add(2*time.Hour)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(jobs.jobs) != 0 || len(files) != 0 {
+		t.Errorf("expected expired jobs and files to be removed, got %d jobs and %d files", len(jobs.jobs), len(files))
+	}
+}
+
+func TestExportWorker_FailsAbandonedJobs(t *testing.T) {
+	ctx := context.Background()
+	rows := &memoryRows{rows: [][]any{{"sale", int64(2)}}}
+	jobs := &memoryJobs{jobs: map[uuid.UUID]*export.Job{}}
+	files := memoryFiles{}
+	uc := export.NewUseCases(rows, jobs, files, 1)
+	worker := export.NewWorker(uc, time.Hour)
+
+	q := export.Query{UserID: uuid.New(), Format: export.FORMAT_CSV, Granularity: export.GRANULARITY_DAILY, Columns: []string{"url_id", "clicks"}}
+	job, err := uc.Schedule(ctx, q)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	// the last worker allowed to run the job died
+	stale := time.Now().UTC().Add(-2 * export.JOB_LEASE)
+	job.Status = export.STATUS_RUNNING
+	job.HeartbeatAt = &stale
+	job.Attempts = export.JOB_MAX_ATTEMPTS
+	files[job.TempFileName(job.Attempts)] = &memoryFile{}
+
+	if err := worker.RunPending(ctx); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if job.Status != export.STATUS_FAILED || job.Error == nil || *job.Error != export.ErrJobAbandoned.Error() {
+		t.Errorf("expected abandoned job to fail, got %+v", job)
+	}
+	if job.Attempts != export.JOB_MAX_ATTEMPTS {
+		t.Errorf("expected no further attempt, got %d", job.Attempts)
+	}
+
+	if err := worker.Cleanup(ctx, time.Now().UTC().Add(2*time.Hour)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("expected files of dead attempts to be removed, got %v", files)
+	}
+}
@@ -0,0 +1,162 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/fallback"
+)
+
+type memoryFallbacks struct {
+	pages map[string]fallback.Page
+}
+
+func newMemoryFallbacks() *memoryFallbacks {
+	return &memoryFallbacks{pages: map[string]fallback.Page{}}
+}
+
+func fallbackKey(userID uuid.UUID, domain string) string {
+	return userID.String() + "/" + domain
+}
+
+func (m *memoryFallbacks) Save(ctx context.Context, page *fallback.Page) error {
+	m.pages[fallbackKey(page.UserID, page.Domain)] = *page
+	return nil
+}
+
+func (m *memoryFallbacks) Get(ctx context.Context, userID uuid.UUID, domain string) (*fallback.Page, error) {
+	page, ok := m.pages[fallbackKey(userID, domain)]
+	if !ok {
+		return nil, fallback.ErrPageNotFound
+	}
+	return &page, nil
+}
+
+func (m *memoryFallbacks) ByUser(ctx context.Context, userID uuid.UUID) ([]fallback.Page, error) {
+	list := []fallback.Page{}
+	for _, page := range m.pages {
+		if page.UserID == userID {
+			list = append(list, page)
+		}
+	}
+	return list, nil
+}
+
+func (m *memoryFallbacks) Delete(ctx context.Context, userID uuid.UUID, domain string) error {
+	delete(m.pages, fallbackKey(userID, domain))
+	return nil
+}
+
+type ownedDomains map[string]uuid.UUID
+
+func (d ownedDomains) IsVerifiedOwner(ctx context.Context, userID uuid.UUID, domain string) (bool, error) {
+	return d[domain] == userID, nil
+}
+
+func TestFallback_Save_Validation(t *testing.T) {
+	uid := uuid.New()
+	fallbacks := fallback.NewUseCases(newMemoryFallbacks(), ownedDomains{"go.brand.com": uid})
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		page fallback.Page
+		want error
+	}{
+		{"unknown mode", fallback.Page{Mode: "teapot"}, fallback.ErrInvalidMode},
+		{"relative redirect", fallback.Page{Mode: fallback.MODE_REDIRECT, RedirectURL: "/home"}, fallback.ErrInvalidRedirect},
+		{"javascript redirect", fallback.Page{Mode: fallback.MODE_REDIRECT, RedirectURL: "javascript:alert(1)"}, fallback.ErrInvalidRedirect},
+		{"broken template", fallback.Page{Mode: fallback.MODE_TEMPLATE, Template: "{{.Slug"}, fallback.ErrInvalidTemplate},
+		{"huge template", fallback.Page{Mode: fallback.MODE_TEMPLATE, Template: strings.Repeat("a", fallback.MAX_TEMPLATE_SIZE+1)}, fallback.ErrTemplateTooLarge},
+		{"foreign domain", fallback.Page{Mode: fallback.MODE_NOT_FOUND, Domain: "other.com"}, fallback.ErrDomainNotAllowed},
+		{"redirect", fallback.Page{Mode: fallback.MODE_REDIRECT, RedirectURL: "https://brand.com"}, nil},
+		{"own domain template", fallback.Page{Mode: fallback.MODE_TEMPLATE, Domain: "go.brand.com", Template: "<p>{{.Slug}}</p>"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := tt.page
+			page.UserID = uid
+			if err := fallbacks.Save(ctx, &page); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFallback_Resolve_DomainFirst(t *testing.T) {
+	uid := uuid.New()
+	repo := newMemoryFallbacks()
+	fallbacks := fallback.NewUseCases(repo, ownedDomains{"go.brand.com": uid})
+	ctx := context.Background()
+
+	page, err := fallbacks.Resolve(ctx, uid, "go.brand.com")
+	if err != nil || page != nil {
+		t.Fatalf("expected no page, got %v, %v", page, err)
+	}
+
+	userWide := &fallback.Page{UserID: uid, Mode: fallback.MODE_REDIRECT, RedirectURL: "https://brand.com"}
+	if err = fallbacks.Save(ctx, userWide); err != nil {
+		t.Fatal(err)
+	}
+
+	page, _ = fallbacks.Resolve(ctx, uid, "go.brand.com")
+	if page == nil || page.Domain != "" {
+		t.Fatalf("expected user wide page, got %+v", page)
+	}
+
+	perDomain := &fallback.Page{UserID: uid, Domain: "go.brand.com", Mode: fallback.MODE_NOT_FOUND}
+	if err = fallbacks.Save(ctx, perDomain); err != nil {
+		t.Fatal(err)
+	}
+
+	page, _ = fallbacks.Resolve(ctx, uid, "go.brand.com")
+	if page == nil || page.Domain != "go.brand.com" {
+		t.Fatalf("expected domain page, got %+v", page)
+	}
+}
+
+func TestFallback_Render_Escapes(t *testing.T) {
+	fallbacks := fallback.NewUseCases(newMemoryFallbacks(), ownedDomains{})
+	page := &fallback.Page{
+		Mode:     fallback.MODE_TEMPLATE,
+		Template: `<h1>{{.Slug}} is {{.Reason}}</h1><a href="/{{.Slug}}">x</a>`,
+	}
+
+	buf := bytes.Buffer{}
+	err := fallbacks.Render(context.Background(), &buf, page, fallback.TemplateData{
+		Slug:   `<script>alert(1)</script>`,
+		Reason: fallback.REASON_EXPIRED,
+	})
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+
+	if strings.Contains(buf.String(), "<script>") {
+		t.Errorf("slug is not escaped: %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "is expired") {
+		t.Errorf("reason is not rendered: %s", buf.String())
+	}
+}
+
+func TestFallback_Render_OutputLimit(t *testing.T) {
+	fallbacks := fallback.NewUseCases(newMemoryFallbacks(), ownedDomains{})
+	page := &fallback.Page{
+		Mode:     fallback.MODE_TEMPLATE,
+		Template: `{{range 128}}` + strings.Repeat("a", 1024) + `{{end}}`,
+	}
+
+	buf := bytes.Buffer{}
+	err := fallbacks.Render(context.Background(), &buf, page, fallback.TemplateData{})
+	if !errors.Is(err, fallback.ErrTemplateTooLarge) {
+		t.Fatalf("expected ErrTemplateTooLarge, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("nothing should be written, got %d bytes", buf.Len())
+	}
+}
@@ -0,0 +1,255 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/health"
+	"roadmap.restapi/internal/outbox"
+	"roadmap.restapi/internal/safehttp"
+)
+
+type memoryHealthRepo struct {
+	mu       sync.Mutex
+	targets  []health.Target
+	statuses map[string]health.Status
+	checks   []health.Check
+	claims   map[string]time.Time
+}
+
+func (m *memoryHealthRepo) Due(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]health.Target, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	due := []health.Target{}
+	for _, t := range m.targets {
+		status, ok := m.statuses[t.URLID]
+		if ok && status.NextCheckAt.After(now) {
+			continue
+		}
+		if m.claims[t.URLID].After(now) {
+			continue
+		}
+		if m.claims == nil {
+			m.claims = map[string]time.Time{}
+		}
+		m.claims[t.URLID] = now.Add(lease)
+		t.Broken = status.Broken
+		t.ConsecutiveFailures = status.ConsecutiveFailures
+		due = append(due, t)
+	}
+	return due, nil
+}
+
+func (m *memoryHealthRepo) Record(ctx context.Context, check *health.Check, status *health.Status) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.checks = append(m.checks, *check)
+	m.statuses[status.URLID] = *status
+	delete(m.claims, status.URLID)
+	return nil
+}
+
+func (m *memoryHealthRepo) ByUser(ctx context.Context, userID uuid.UUID) ([]health.Status, error) {
+	return nil, nil
+}
+
+func (m *memoryHealthRepo) PruneHistory(ctx context.Context, olderThan time.Time) error {
+	return nil
+}
+
+// localProber reaches the loopback test servers the default prober refuses.
+func localProber(timeout time.Duration) *health.HTTPProber {
+	return health.NewHTTPProberWithClient(&http.Client{Timeout: timeout}, "test")
+}
+
+type memoryOutboxAdder struct {
+	mu     sync.Mutex
+	topics []string
+}
+
+func (m *memoryOutboxAdder) Add(ctx context.Context, msg *outbox.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.topics = append(m.topics, msg.Topic)
+	return nil
+}
+
+func (m *memoryOutboxAdder) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]outbox.Message, error) {
+	return nil, nil
+}
+
+func (m *memoryOutboxAdder) Release(ctx context.Context, ids []uuid.UUID) error {
+	return nil
+}
+
+func (m *memoryOutboxAdder) MarkProcessed(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func testCheckerOptions() health.CheckerOptions {
+	return health.CheckerOptions{
+		Interval:         time.Hour,
+		RetryBackoff:     time.Minute,
+		MaxBackoff:       10 * time.Minute,
+		FailureThreshold: 2,
+		Concurrency:      4,
+		HostInterval:     0,
+		BatchSize:        100,
+		HistoryRetention: time.Hour,
+		PruneInterval:    time.Hour,
+	}
+}
+
+func TestHTTPProber_HeadOK(t *testing.T) {
+	var method string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	res := localProber(time.Second).Probe(context.Background(), srv.URL)
+	if res.Failed() {
+		t.Fatalf("expected healthy result, got %+v", res)
+	}
+	if method != http.MethodHead {
+		t.Errorf("expected HEAD request, got %s", method)
+	}
+}
+
+func TestHTTPProber_FallsBackToGet(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	res := localProber(time.Second).Probe(context.Background(), srv.URL)
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected GET fallback to return 200, got %d", res.StatusCode)
+	}
+}
+
+func TestHTTPProber_Timeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	res := localProber(50*time.Millisecond).Probe(context.Background(), srv.URL)
+	if res.Err == nil || !res.Failed() {
+		t.Errorf("expected timeout error, got %+v", res)
+	}
+}
+
+func TestHTTPProber_RefusesLoopback(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	res := health.NewHTTPProber(time.Second, "test").Probe(context.Background(), srv.URL)
+	if !errors.Is(res.Err, safehttp.ErrForbiddenAddress) {
+		t.Errorf("expected forbidden address error, got %+v", res)
+	}
+	if called {
+		t.Error("expected the loopback server not to be reached")
+	}
+}
+
+func TestChecker_FlagsBrokenAfterThresholdAndAlerts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	repo := &memoryHealthRepo{
+		targets:  []health.Target{{URLID: "broken", URL: srv.URL}},
+		statuses: map[string]health.Status{},
+	}
+	alerts := &memoryOutboxAdder{}
+	checker := health.NewChecker(inlineTx{}, repo, localProber(time.Second), alerts, testCheckerOptions())
+
+	if err := checker.CheckDue(context.Background()); err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+
+	status := repo.statuses["broken"]
+	if status.Broken {
+		t.Error("link should not be broken after the first failure")
+	}
+	if status.ConsecutiveFailures != 1 {
+		t.Errorf("expected 1 failure, got %d", status.ConsecutiveFailures)
+	}
+	if status.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", status.StatusCode)
+	}
+	if d := status.NextCheckAt.Sub(status.CheckedAt); d != time.Minute {
+		t.Errorf("expected retry after backoff, got %v", d)
+	}
+
+	// make the link due again
+	status.NextCheckAt = time.Now().UTC().Add(-time.Second)
+	repo.statuses["broken"] = status
+
+	if err := checker.CheckDue(context.Background()); err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+
+	status = repo.statuses["broken"]
+	if !status.Broken {
+		t.Error("link should be broken after reaching the threshold")
+	}
+	if d := status.NextCheckAt.Sub(status.CheckedAt); d != 2*time.Minute {
+		t.Errorf("expected backoff to double, got %v", d)
+	}
+	if len(alerts.topics) != 1 || alerts.topics[0] != health.TOPIC_URL_BROKEN {
+		t.Errorf("expected one broken alert, got %v", alerts.topics)
+	}
+	if len(repo.checks) != 2 {
+		t.Errorf("expected 2 history records, got %d", len(repo.checks))
+	}
+}
+
+func TestChecker_RecoveryResetsFailures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	repo := &memoryHealthRepo{
+		targets: []health.Target{{URLID: "flaky", URL: srv.URL}},
+		statuses: map[string]health.Status{
+			"flaky": {URLID: "flaky", Broken: true, ConsecutiveFailures: 3},
+		},
+	}
+	alerts := &memoryOutboxAdder{}
+	checker := health.NewChecker(inlineTx{}, repo, localProber(time.Second), alerts, testCheckerOptions())
+
+	if err := checker.CheckDue(context.Background()); err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+
+	status := repo.statuses["flaky"]
+	if status.Broken || status.ConsecutiveFailures != 0 {
+		t.Errorf("expected recovered status, got %+v", status)
+	}
+	if d := status.NextCheckAt.Sub(status.CheckedAt); d != time.Hour {
+		t.Errorf("expected regular interval, got %v", d)
+	}
+	if len(alerts.topics) != 1 || alerts.topics[0] != health.TOPIC_URL_RECOVERED {
+		t.Errorf("expected one recovered alert, got %v", alerts.topics)
+	}
+}
@@ -0,0 +1,170 @@
+package unit
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/token"
+)
+
+func hmacKey(id string, secret string) *token.SigningKey {
+	return &token.SigningKey{Method: jwt.SigningMethodHS512, ID: id, Private: []byte(secret), Public: []byte(secret)}
+}
+
+func ecKey(t *testing.T) *token.SigningKey {
+	t.Helper()
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8(t, private)})
+	key, err := token.NewSigningKey(jwt.SigningMethodES256, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestKeyRing_Rotation(t *testing.T) {
+	rotation := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	legacy, current, next := hmacKey("", "legacy"), hmacKey("2026-07", "current"), hmacKey("2026-08", "next")
+	keys, err := token.NewKeyRing([]token.RingKey{
+		{Key: next, ActiveFrom: rotation.AddDate(0, 1, 0)},
+		{Key: legacy},
+		{Key: current, ActiveFrom: rotation},
+	}, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	signing := []struct {
+		now  time.Time
+		want *token.SigningKey
+	}{
+		{rotation.Add(-time.Hour), legacy},
+		{rotation, current},
+		{rotation.AddDate(0, 1, 1), next},
+	}
+	for _, c := range signing {
+		if got, err := keys.Signing(c.now); err != nil || got != c.want {
+			t.Errorf("Signing(%v) = %q, %v, want %q", c.now, got.ID, err, c.want.ID)
+		}
+	}
+
+	verification := []struct {
+		name string
+		id   string
+		now  time.Time
+		want error
+	}{
+		{"retired within grace", "", rotation.Add(23 * time.Hour), nil},
+		{"retired after grace", "", rotation.Add(24 * time.Hour), token.ErrUnknownKey},
+		{"active", "2026-07", rotation.Add(time.Hour), nil},
+		{"upcoming", "2026-08", rotation.Add(time.Hour), nil},
+		{"unknown", "2025-01", rotation.Add(time.Hour), token.ErrUnknownKey},
+	}
+	for _, c := range verification {
+		if _, err := keys.Verification(c.id, "HS512", c.now); !errors.Is(err, c.want) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, err)
+		}
+	}
+
+	if _, err := keys.Verification("2026-07", "HS256", rotation); !errors.Is(err, token.ErrKeyMismatch) {
+		t.Errorf("expected a token of another algorithm to be refused, got %v", err)
+	}
+}
+
+func TestKeyRing_Invalid(t *testing.T) {
+	if _, err := token.NewKeyRing(nil, time.Hour); !errors.Is(err, token.ErrMissingKey) {
+		t.Errorf("expected ErrMissingKey, got %v", err)
+	}
+
+	_, err := token.NewKeyRing([]token.RingKey{{Key: hmacKey("a", "1")}, {Key: hmacKey("a", "2")}}, time.Hour)
+	if !errors.Is(err, token.ErrDuplicateKeyID) {
+		t.Errorf("expected ErrDuplicateKeyID, got %v", err)
+	}
+
+	keys, err := token.NewKeyRing([]token.RingKey{{Key: hmacKey("a", "1"), ActiveFrom: time.Now().Add(time.Hour)}}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Signing(time.Now()); !errors.Is(err, token.ErrNoActiveKey) {
+		t.Errorf("expected ErrNoActiveKey, got %v", err)
+	}
+}
+
+func TestKeyRing_TokensSurviveRotation(t *testing.T) {
+	legacy := hmacKey("", "legacy")
+	before := token.SingleKeyRing(legacy)
+	oldToken, err := token.NewJWTGeneratorWithKeys(before, time.Hour, time.Hour).
+		Generate(context.Background(), &token.UserClaims{UID: uuid.New()}, token.ACCESS, uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := ecKey(t)
+	after, err := token.NewKeyRing([]token.RingKey{
+		{Key: legacy},
+		{Key: rotated, ActiveFrom: time.Now().Add(-time.Minute)},
+	}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	extractor := token.NewJWTClaimsExtractorWithKeys(after)
+
+	if _, err := extractor.ParseAndValidate(context.Background(), oldToken); err != nil {
+		t.Errorf("expected tokens of the previous key to stay valid, got %v", err)
+	}
+
+	newToken, err := token.NewJWTGeneratorWithKeys(after, time.Hour, time.Hour).
+		Generate(context.Background(), &token.UserClaims{UID: uuid.New()}, token.ACCESS, uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &token.TokenClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Header["kid"] != rotated.ID || parsed.Header["alg"] != "ES256" {
+		t.Errorf("expected new tokens to be signed with the rotated key, got header %v", parsed.Header)
+	}
+	if _, err := extractor.ParseAndValidate(context.Background(), newToken); err != nil {
+		t.Errorf("expected tokens of the rotated key to be valid, got %v", err)
+	}
+
+	// verifiers that never got the rotated key refuse its tokens
+	if _, err := token.NewJWTClaimsExtractorWithKeys(before).ParseAndValidate(context.Background(), newToken); err == nil {
+		t.Error("expected tokens of an unknown key to be refused")
+	}
+}
+
+func TestKeyRing_JWKS(t *testing.T) {
+	now := time.Now()
+	retired, active, upcoming := ecKey(t), ecKey(t), ecKey(t)
+	keys, err := token.NewKeyRing([]token.RingKey{
+		{Key: retired},
+		{Key: hmacKey("secret", "s"), ActiveFrom: now.Add(-48 * time.Hour)},
+		{Key: active, ActiveFrom: now.Add(-time.Hour)},
+		{Key: upcoming, ActiveFrom: now.Add(time.Hour)},
+	}, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := keys.JWKS(now)
+	ids := map[string]bool{}
+	for _, jwk := range jwks.Keys {
+		ids[jwk.Kid] = true
+	}
+	if len(jwks.Keys) != 2 || !ids[active.ID] || !ids[upcoming.ID] {
+		t.Errorf("expected the active and the upcoming key only, got %+v", jwks.Keys)
+	}
+}
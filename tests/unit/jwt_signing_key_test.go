@@ -0,0 +1,204 @@
+package unit
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/token"
+)
+
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "key.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func pkcs8(t *testing.T, key any) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func TestSigningKey_Asymmetric(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		algorithm string
+		path      string
+		kty       string
+	}{
+		{"RS256", writePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)), "RSA"},
+		{"PS512", writePEM(t, "PRIVATE KEY", pkcs8(t, rsaKey)), "RSA"},
+		{"ES256", writePEM(t, "EC PRIVATE KEY", ecDER), "EC"},
+		{"ES256", writePEM(t, "PRIVATE KEY", pkcs8(t, ecKey)), "EC"},
+		{"EdDSA", writePEM(t, "PRIVATE KEY", pkcs8(t, edKey)), "OKP"},
+	}
+
+	for _, c := range cases {
+		key, err := token.LoadSigningKey(c.algorithm, "", c.path)
+		if err != nil {
+			t.Fatalf("%s: failed to load key: %v", c.algorithm, err)
+		}
+		if key.ID == "" {
+			t.Errorf("%s: expected a key id", c.algorithm)
+		}
+
+		keys := token.SingleKeyRing(key)
+		generator := token.NewJWTGeneratorWithKeys(keys, time.Hour, time.Hour)
+		extractor := token.NewJWTClaimsExtractorWithKeys(keys)
+		uid := uuid.New()
+		tokenStr, err := generator.Generate(context.Background(), &token.UserClaims{UID: uid}, token.ACCESS, uuid.New())
+		if err != nil {
+			t.Fatalf("%s: failed to generate token: %v", c.algorithm, err)
+		}
+
+		claims, err := extractor.ParseAndValidate(context.Background(), tokenStr)
+		if err != nil {
+			t.Fatalf("%s: failed to validate token: %v", c.algorithm, err)
+		}
+		if claims.UID != uid {
+			t.Errorf("%s: uid mismatch: got %v, want %v", c.algorithm, claims.UID, uid)
+		}
+
+		parsed, _, err := jwt.NewParser().ParseUnverified(tokenStr, &token.TokenClaims{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.Header["kid"] != key.ID || parsed.Header["alg"] != c.algorithm {
+			t.Errorf("%s: unexpected header %v", c.algorithm, parsed.Header)
+		}
+
+		jwks := keys.JWKS(time.Now())
+		if len(jwks.Keys) != 1 {
+			t.Fatalf("%s: expected 1 published key, got %+v", c.algorithm, jwks)
+		}
+		jwk := jwks.Keys[0]
+		if jwk.Kty != c.kty || jwk.Kid != key.ID || jwk.Alg != c.algorithm || jwk.Use != "sig" {
+			t.Errorf("%s: unexpected jwk %+v", c.algorithm, jwk)
+		}
+		if jwk.Thumbprint() != key.ID {
+			t.Errorf("%s: key id is not the thumbprint of the jwk", c.algorithm)
+		}
+	}
+}
+
+func TestSigningKey_HMAC(t *testing.T) {
+	key, err := token.LoadSigningKey("HS512", "secret", "")
+	if err != nil {
+		t.Fatalf("failed to load key: %v", err)
+	}
+	if key.ID != "" {
+		t.Errorf("expected secrets to have no key id, got %q", key.ID)
+	}
+	if jwks := token.SingleKeyRing(key).JWKS(time.Now()); len(jwks.Keys) != 0 {
+		t.Errorf("expected secrets never to be published, got %+v", jwks)
+	}
+}
+
+func TestSigningKey_Invalid(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weakKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	garbage := filepath.Join(t.TempDir(), "garbage.pem")
+	if err := os.WriteFile(garbage, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name      string
+		algorithm string
+		secret    string
+		path      string
+		want      error
+	}{
+		{"none", "none", "", "", token.ErrUnsupportedAlgorithm},
+		{"unknown", "HS1", "secret", "", token.ErrUnsupportedAlgorithm},
+		{"no secret", "HS256", "", "", token.ErrMissingKey},
+		{"no key file", "RS256", "secret", "", token.ErrMissingKey},
+		{"garbage", "RS256", "", garbage, token.ErrInvalidKey},
+		{"weak rsa", "RS256", "", writePEM(t, "PRIVATE KEY", pkcs8(t, weakKey)), token.ErrInvalidKey},
+		{"rsa for ecdsa", "ES256", "", writePEM(t, "PRIVATE KEY", pkcs8(t, rsaKey)), token.ErrKeyMismatch},
+		{"wrong curve", "ES256", "", writePEM(t, "PRIVATE KEY", pkcs8(t, p384Key)), token.ErrKeyMismatch},
+		{"ecdsa for eddsa", "EdDSA", "", writePEM(t, "PRIVATE KEY", pkcs8(t, p384Key)), token.ErrKeyMismatch},
+	}
+
+	for _, c := range cases {
+		if _, err := token.LoadSigningKey(c.algorithm, c.secret, c.path); !errors.Is(err, c.want) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, err)
+		}
+	}
+}
+
+func TestJWK_Thumbprint(t *testing.T) {
+	// example of RFC 7638, section 3.1
+	jwk := token.JWK{
+		Kty: "RSA",
+		E:   "AQAB",
+		N: "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn" +
+			"64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbI" +
+			"SD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+	}
+
+	if got, want := jwk.Thumbprint(), "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; got != want {
+		t.Errorf("Thumbprint() = %q, want %q", got, want)
+	}
+}
+
+func TestJWTClaimsExtractor_RejectsOtherAlgorithm(t *testing.T) {
+	secret := []byte("test-secret-key-very-long-for-hs256")
+	generator := token.NewJWTGenerator(secret, jwt.SigningMethodHS256, time.Hour, time.Hour)
+	extractor := token.NewJWTClaimsExtractor(secret, jwt.SigningMethodHS512)
+
+	tokenStr, err := generator.Generate(context.Background(), &token.UserClaims{UID: uuid.New()}, token.ACCESS, uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := extractor.ParseAndValidate(context.Background(), tokenStr); err == nil {
+		t.Fatal("ParseAndValidate accepted a token signed with another algorithm")
+	}
+}
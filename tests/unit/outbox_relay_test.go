@@ -0,0 +1,132 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/outbox"
+)
+
+type memoryOutbox struct {
+	msgs      []outbox.Message
+	processed map[uuid.UUID]bool
+	claimed   map[uuid.UUID]bool
+}
+
+func (m *memoryOutbox) Add(ctx context.Context, msg *outbox.Message) error {
+	msg.ID = uuid.New()
+	m.msgs = append(m.msgs, *msg)
+	return nil
+}
+
+func (m *memoryOutbox) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]outbox.Message, error) {
+	claimed := []outbox.Message{}
+	for _, msg := range m.msgs {
+		if !m.processed[msg.ID] && !m.claimed[msg.ID] && len(claimed) < limit {
+			m.claimed[msg.ID] = true
+			claimed = append(claimed, msg)
+		}
+	}
+	return claimed, nil
+}
+
+func (m *memoryOutbox) Release(ctx context.Context, ids []uuid.UUID) error {
+	for _, id := range ids {
+		delete(m.claimed, id)
+	}
+	return nil
+}
+
+func (m *memoryOutbox) pending() int {
+	pending := 0
+	for _, msg := range m.msgs {
+		if !m.processed[msg.ID] {
+			pending++
+		}
+	}
+	return pending
+}
+
+func (m *memoryOutbox) MarkProcessed(ctx context.Context, id uuid.UUID) error {
+	m.processed[id] = true
+	delete(m.claimed, id)
+	return nil
+}
+
+// inlineTx runs fn without a transaction, for repositories kept in memory.
+type inlineTx struct{}
+
+func (inlineTx) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type failingSender struct {
+	sent   []string
+	failOn string
+}
+
+func (s *failingSender) Send(ctx context.Context, msg *outbox.Message) error {
+	if msg.Topic == s.failOn {
+		return errors.New("send failed")
+	}
+	s.sent = append(s.sent, msg.Topic)
+	return nil
+}
+
+func TestOutboxRelay_Flush_SendsInOrderAndStopsOnFailure(t *testing.T) {
+	repo := &memoryOutbox{processed: map[uuid.UUID]bool{}, claimed: map[uuid.UUID]bool{}}
+	for _, topic := range []string{"first", "broken", "third"} {
+		msg, _ := outbox.NewMessage(topic, nil)
+		repo.Add(context.Background(), msg)
+	}
+
+	sender := &failingSender{failOn: "broken"}
+	relay := outbox.NewRelay(repo, sender, 10)
+
+	if err := relay.Flush(context.Background()); err == nil {
+		t.Fatal("expected flush to report send failure")
+	}
+
+	if len(sender.sent) != 1 || sender.sent[0] != "first" {
+		t.Errorf("expected only first message to be sent, got %v", sender.sent)
+	}
+
+	if pending := repo.pending(); pending != 2 {
+		t.Errorf("expected 2 pending messages, got %d", pending)
+	}
+	if len(repo.claimed) != 0 {
+		t.Errorf("expected unsent messages to be released, got %d claimed", len(repo.claimed))
+	}
+
+	sender.failOn = ""
+	if err := relay.Flush(context.Background()); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	if pending := repo.pending(); pending != 0 {
+		t.Errorf("expected no pending messages, got %d", pending)
+	}
+}
+
+func TestOutboxDispatcher_RoutesByTopic(t *testing.T) {
+	routed := &failingSender{}
+	fallback := &failingSender{}
+	dispatcher := outbox.NewDispatcher(fallback)
+	dispatcher.Handle("routed", routed)
+
+	for _, topic := range []string{"routed", "other"} {
+		if err := dispatcher.Send(context.Background(), &outbox.Message{Topic: topic}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(routed.sent) != 1 || routed.sent[0] != "routed" {
+		t.Errorf("expected the routed topic to reach its sender, got %v", routed.sent)
+	}
+	if len(fallback.sent) != 1 || fallback.sent[0] != "other" {
+		t.Errorf("expected other topics to reach the fallback, got %v", fallback.sent)
+	}
+}
@@ -0,0 +1,104 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/privacy"
+)
+
+var euPolicy = privacy.Policy{IPv4PrefixBits: 24, IPv6PrefixBits: 48, HonorDNT: true, HonorGPC: true}
+
+type memoryPrivacySettings struct {
+	settings map[uuid.UUID]privacy.Settings
+	err      error
+}
+
+func (m *memoryPrivacySettings) Save(ctx context.Context, settings *privacy.Settings) error {
+	m.settings[settings.UserID] = *settings
+	return nil
+}
+
+func (m *memoryPrivacySettings) Get(ctx context.Context, userID uuid.UUID) (*privacy.Settings, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	settings, ok := m.settings[userID]
+	if !ok {
+		return nil, privacy.ErrSettingsNotFound
+	}
+	return &settings, nil
+}
+
+func TestPrivacy_AnonymizeIP(t *testing.T) {
+	uc := privacy.NewUseCases(&memoryPrivacySettings{}, euPolicy)
+
+	cases := []struct {
+		ip   string
+		want string
+	}{
+		{"203.0.113.57", "203.0.113.0"},
+		{"::ffff:203.0.113.57", "203.0.113.0"},
+		{"2001:db8:85a3:8d3:1319:8a2e:370:7348", "2001:db8:85a3::"},
+		{"fe80::1%eth0", "fe80::"},
+		{"not-an-ip", ""},
+		{"", ""},
+	}
+
+	for _, c := range cases {
+		if got := uc.AnonymizeIP(c.ip); got != c.want {
+			t.Errorf("AnonymizeIP(%q) = %q, want %q", c.ip, got, c.want)
+		}
+	}
+}
+
+func TestPrivacy_RefusesTracking(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy privacy.Policy
+		dnt    string
+		gpc    string
+		want   bool
+	}{
+		{"no signal", euPolicy, "", "", false},
+		{"dnt", euPolicy, "1", "", true},
+		{"dnt allowed", euPolicy, "0", "", false},
+		{"gpc", euPolicy, "", "1", true},
+		{"dnt ignored", privacy.Policy{HonorGPC: true}, "1", "", false},
+		{"gpc ignored", privacy.Policy{HonorDNT: true}, "", "1", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			uc := privacy.NewUseCases(&memoryPrivacySettings{}, c.policy)
+			if got := uc.RefusesTracking(c.dnt, c.gpc); got != c.want {
+				t.Errorf("RefusesTracking(%q, %q) = %v, want %v", c.dnt, c.gpc, got, c.want)
+			}
+		})
+	}
+}
+
+func TestPrivacy_CaptureReferer(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryPrivacySettings{settings: map[uuid.UUID]privacy.Settings{}}
+	uc := privacy.NewUseCases(repo, euPolicy)
+	uid := uuid.New()
+
+	if !uc.CaptureReferer(ctx, uid) {
+		t.Error("referers should be captured by default")
+	}
+
+	if err := uc.Save(ctx, &privacy.Settings{UserID: uid, CaptureReferer: false}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if uc.CaptureReferer(ctx, uid) {
+		t.Error("referers captured although disabled")
+	}
+
+	repo.err = errors.New("connection refused")
+	if uc.CaptureReferer(ctx, uuid.New()) {
+		t.Error("referers captured although settings could not be read")
+	}
+}
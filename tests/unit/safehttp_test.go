@@ -0,0 +1,68 @@
+package unit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	neturl "net/url"
+	"testing"
+	"time"
+
+	"roadmap.restapi/internal/safehttp"
+)
+
+func TestSafeHTTP_IsPublic(t *testing.T) {
+	cases := map[string]bool{
+		"8.8.8.8":         true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"::1":             false,
+		"fe80::1":         false,
+		"fd00::1":         false,
+		"::ffff:10.0.0.1": false,
+		"224.0.0.1":       false,
+	}
+
+	for addr, want := range cases {
+		if got := safehttp.IsPublic(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("IsPublic(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestSafeHTTP_CheckRedirectRejectsInternalHops(t *testing.T) {
+	cases := map[string]error{
+		"https://example.com/next":                 nil,
+		"http://169.254.169.254/latest/meta-data/": safehttp.ErrForbiddenAddress,
+		"http://[::1]:8080/":                       safehttp.ErrForbiddenAddress,
+		"file:///etc/passwd":                       safehttp.ErrForbiddenScheme,
+	}
+
+	for target, want := range cases {
+		u, _ := neturl.Parse(target)
+		err := safehttp.CheckRedirect(&http.Request{URL: u}, nil)
+		if !errors.Is(err, want) {
+			t.Errorf("CheckRedirect(%s) = %v, want %v", target, err, want)
+		}
+	}
+}
+
+func TestSafeHTTP_ClientRefusesResolvedLoopback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	u, _ := neturl.Parse(srv.URL)
+	target := "http://localhost:" + u.Port()
+
+	_, err := safehttp.NewClient(time.Second).Get(target)
+	if !errors.Is(err, safehttp.ErrForbiddenAddress) {
+		t.Errorf("expected forbidden address error, got %v", err)
+	}
+}
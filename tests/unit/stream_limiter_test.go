@@ -0,0 +1,28 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/api/handlers"
+)
+
+func TestStreamLimiter_CapsStreamsPerUser(t *testing.T) {
+	limiter := handlers.NewStreamLimiter(2)
+	alice, bob := uuid.New(), uuid.New()
+
+	if !limiter.Acquire(alice) || !limiter.Acquire(alice) {
+		t.Fatal("expected streams up to the limit to be allowed")
+	}
+	if limiter.Acquire(alice) {
+		t.Error("expected a stream over the limit to be refused")
+	}
+	if !limiter.Acquire(bob) {
+		t.Error("expected the limit to be per user")
+	}
+
+	limiter.Release(alice)
+	if !limiter.Acquire(alice) {
+		t.Error("expected a released stream to be available again")
+	}
+}
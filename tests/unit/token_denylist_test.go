@@ -0,0 +1,101 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/token"
+)
+
+func denylistExtractor(denylist token.Denylist) *token.DenylistClaimsExtractor {
+	return token.NewDenylistClaimsExtractor(
+		token.NewJWTClaimsExtractor([]byte(testTokenSecret), jwt.SigningMethodHS256),
+		denylist,
+	)
+}
+
+func TestDenylist_LogoutRevokesAccessToken(t *testing.T) {
+	ctx := context.Background()
+	denylist := newMemoryDenylist()
+	tokens := newTokenUseCasesDenying(denylist)
+	extractor := denylistExtractor(denylist)
+
+	pair, err := tokens.NewPair(ctx, uuid.New(), testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = extractor.ParseAndValidate(ctx, pair.Access); err != nil {
+		t.Fatalf("expected a fresh access token to be valid, got %v", err)
+	}
+
+	if err = tokens.RevokeToken(ctx, pair.Refresh, pair.Access); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = extractor.ParseAndValidate(ctx, pair.Access); !errors.Is(err, token.ErrTokenRevoked) {
+		t.Errorf("expected ErrTokenRevoked, got %v", err)
+	}
+}
+
+func TestDenylist_RevokedSessionRevokesAllItsAccessTokens(t *testing.T) {
+	ctx := context.Background()
+	denylist := newMemoryDenylist()
+	tokens := newTokenUseCasesDenying(denylist)
+	extractor := denylistExtractor(denylist)
+	userID := uuid.New()
+
+	first, err := tokens.NewPair(ctx, userID, testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := tokens.RefreshTokenPair(ctx, first.Refresh, testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := tokens.NewPair(ctx, userID, testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = tokens.RevokeSession(ctx, userID, sessionOf(t, first.Refresh)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, access := range []string{first.Access, second.Access} {
+		if _, err = extractor.ParseAndValidate(ctx, access); !errors.Is(err, token.ErrTokenRevoked) {
+			t.Errorf("expected ErrTokenRevoked, got %v", err)
+		}
+	}
+
+	if _, err = extractor.ParseAndValidate(ctx, other.Access); err != nil {
+		t.Errorf("expected tokens of other sessions to stay valid, got %v", err)
+	}
+}
+
+func TestDenylist_ReuseRevokesAccessTokens(t *testing.T) {
+	ctx := context.Background()
+	denylist := newMemoryDenylist()
+	tokens := newTokenUseCasesDenying(denylist)
+	extractor := denylistExtractor(denylist)
+
+	stolen, err := tokens.NewPair(ctx, uuid.New(), testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := tokens.RefreshTokenPair(ctx, stolen.Refresh, testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = tokens.RefreshTokenPair(ctx, stolen.Refresh, testClient); !errors.Is(err, token.ErrRefreshReused) {
+		t.Fatalf("expected ErrRefreshReused, got %v", err)
+	}
+
+	if _, err = extractor.ParseAndValidate(ctx, rotated.Access); !errors.Is(err, token.ErrTokenRevoked) {
+		t.Errorf("expected ErrTokenRevoked, got %v", err)
+	}
+}
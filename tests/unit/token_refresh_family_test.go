@@ -0,0 +1,301 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/token"
+)
+
+type memoryWhitelist struct {
+	mu      sync.Mutex
+	active  map[uuid.UUID]uuid.UUID
+	used    map[uuid.UUID]uuid.UUID
+	revoked map[uuid.UUID]bool
+}
+
+func newMemoryWhitelist() *memoryWhitelist {
+	return &memoryWhitelist{active: map[uuid.UUID]uuid.UUID{}, used: map[uuid.UUID]uuid.UUID{}, revoked: map[uuid.UUID]bool{}}
+}
+
+func (m *memoryWhitelist) Add(_ context.Context, id uuid.UUID, family uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.revoked[family] {
+		return token.ErrTokenRevoked
+	}
+	m.active[id] = family
+	return nil
+}
+
+func (m *memoryWhitelist) Exists(_ context.Context, id uuid.UUID) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.active[id]
+	return ok, nil
+}
+
+func (m *memoryWhitelist) Consume(_ context.Context, id uuid.UUID) (uuid.UUID, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	family, ok := m.active[id]
+	if !ok {
+		return uuid.Nil, false, nil
+	}
+	delete(m.active, id)
+	m.used[id] = family
+	return family, true, nil
+}
+
+func (m *memoryWhitelist) UsedFamily(_ context.Context, id uuid.UUID) (uuid.UUID, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	family, ok := m.used[id]
+	return family, ok, nil
+}
+
+func (m *memoryWhitelist) RevokeFamily(_ context.Context, family uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.revoked[family] = true
+	for id, f := range m.active {
+		if f == family {
+			delete(m.active, id)
+		}
+	}
+	return nil
+}
+
+func (m *memoryWhitelist) Remove(_ context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.active, id)
+	return nil
+}
+
+type memorySessions struct {
+	mu       sync.Mutex
+	sessions map[uuid.UUID]token.Session
+}
+
+func newMemorySessions() *memorySessions {
+	return &memorySessions{sessions: map[uuid.UUID]token.Session{}}
+}
+
+func (m *memorySessions) Save(_ context.Context, session *token.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ID] = *session
+	return nil
+}
+
+func (m *memorySessions) Update(_ context.Context, session *token.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sessions[session.ID]; !ok {
+		return token.ErrSessionNotFound
+	}
+	m.sessions[session.ID] = *session
+	return nil
+}
+
+func (m *memorySessions) Get(_ context.Context, id uuid.UUID) (*token.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[id]
+	if !ok {
+		return nil, token.ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (m *memorySessions) ByUser(_ context.Context, userID uuid.UUID) ([]token.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var sessions []token.Session
+	for _, session := range m.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *memorySessions) Delete(_ context.Context, _ uuid.UUID, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+type memoryDenylist struct {
+	mu     sync.Mutex
+	denied map[uuid.UUID]time.Time
+}
+
+func newMemoryDenylist() *memoryDenylist {
+	return &memoryDenylist{denied: map[uuid.UUID]time.Time{}}
+}
+
+func (m *memoryDenylist) Deny(_ context.Context, id uuid.UUID, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.denied[id] = expiresAt
+	return nil
+}
+
+func (m *memoryDenylist) Denied(_ context.Context, ids ...uuid.UUID) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		if expiresAt, ok := m.denied[id]; ok && time.Now().Before(expiresAt) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+const testTokenSecret = "test-secret-key-very-long-for-hs256"
+
+func newTokenUseCases() *token.UseCases {
+	return newTokenUseCasesDenying(newMemoryDenylist())
+}
+
+func newTokenUseCasesDenying(denylist token.Denylist) *token.UseCases {
+	secret := []byte(testTokenSecret)
+	return token.NewUseCases(
+		token.NewJWTClaimsExtractor(secret, jwt.SigningMethodHS256),
+		token.NewJWTGenerator(secret, jwt.SigningMethodHS256, time.Minute, time.Hour),
+		newMemoryWhitelist(),
+		newMemorySessions(),
+		denylist,
+	)
+}
+
+var testClient = token.SessionClient{IP: "203.0.113.7", UserAgent: "Mozilla/5.0"}
+
+func TestTokenUseCases_RefreshRotates(t *testing.T) {
+	ctx := context.Background()
+	tokens := newTokenUseCases()
+
+	pair, err := tokens.NewPair(ctx, uuid.New(), testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		next, err := tokens.RefreshTokenPair(ctx, pair.Refresh, testClient)
+		if err != nil {
+			t.Fatalf("refresh %d failed: %v", i, err)
+		}
+		pair = next
+	}
+}
+
+func TestTokenUseCases_ReuseRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	tokens := newTokenUseCases()
+	userID := uuid.New()
+
+	stolen, err := tokens.NewPair(ctx, userID, testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSession, err := tokens.NewPair(ctx, userID, testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := tokens.RefreshTokenPair(ctx, stolen.Refresh, testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	descendant, err := tokens.RefreshTokenPair(ctx, rotated.Refresh, testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = tokens.RefreshTokenPair(ctx, stolen.Refresh, testClient); !errors.Is(err, token.ErrRefreshReused) {
+		t.Fatalf("expected ErrRefreshReused, got %v", err)
+	}
+
+	if _, err = tokens.RefreshTokenPair(ctx, descendant.Refresh, testClient); !errors.Is(err, token.ErrTokenRevoked) {
+		t.Errorf("expected the family to be revoked, got %v", err)
+	}
+
+	if _, err = tokens.RefreshTokenPair(ctx, otherSession.Refresh, testClient); err != nil {
+		t.Errorf("expected other logins to stay valid, got %v", err)
+	}
+}
+
+func TestTokenUseCases_RevokedTokenIsNotReuse(t *testing.T) {
+	ctx := context.Background()
+	tokens := newTokenUseCases()
+
+	pair, err := tokens.NewPair(ctx, uuid.New(), testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = tokens.RevokeToken(ctx, pair.Refresh, pair.Access); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = tokens.RefreshTokenPair(ctx, pair.Refresh, testClient); !errors.Is(err, token.ErrTokenRevoked) {
+		t.Errorf("expected ErrTokenRevoked, got %v", err)
+	}
+}
+
+func TestTokenUseCases_RefreshRequiresRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	tokens := newTokenUseCases()
+
+	pair, err := tokens.NewPair(ctx, uuid.New(), testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = tokens.RefreshTokenPair(ctx, pair.Access, testClient); !errors.Is(err, token.ErrTokenInvalid) {
+		t.Errorf("expected an access token to be refused for refresh, got %v", err)
+	}
+	if err = tokens.RevokeToken(ctx, pair.Access, ""); !errors.Is(err, token.ErrTokenInvalid) {
+		t.Errorf("expected an access token to be refused for logout, got %v", err)
+	}
+}
+
+func TestTokenUseCases_RefreshDoesNotRecreateSession(t *testing.T) {
+	ctx := context.Background()
+	whitelist := newMemoryWhitelist()
+	sessions := newMemorySessions()
+	secret := []byte(testTokenSecret)
+	tokens := token.NewUseCases(
+		token.NewJWTClaimsExtractor(secret, jwt.SigningMethodHS256),
+		token.NewJWTGenerator(secret, jwt.SigningMethodHS256, time.Minute, time.Hour),
+		whitelist,
+		sessions,
+		newMemoryDenylist(),
+	)
+	userID := uuid.New()
+
+	pair, err := tokens.NewPair(ctx, userID, testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the session was deleted while its token was still whitelisted
+	if err = sessions.Delete(ctx, userID, sessionOf(t, pair.Refresh)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = tokens.RefreshTokenPair(ctx, pair.Refresh, testClient); !errors.Is(err, token.ErrRefreshNotWhitelisted) {
+		t.Errorf("expected ErrRefreshNotWhitelisted, got %v", err)
+	}
+	if list, _ := tokens.Sessions(ctx, userID); len(list) != 0 {
+		t.Errorf("expected the session to stay deleted, got %d", len(list))
+	}
+}
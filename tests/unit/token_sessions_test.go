@@ -0,0 +1,120 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/token"
+)
+
+func TestTokenUseCases_SessionsTrackClients(t *testing.T) {
+	ctx := context.Background()
+	tokens := newTokenUseCases()
+	userID := uuid.New()
+
+	pair, err := tokens.NewPair(ctx, userID, testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	moved := token.SessionClient{IP: "198.51.100.1", UserAgent: "curl/8.0"}
+	if _, err = tokens.RefreshTokenPair(ctx, pair.Refresh, moved); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tokens.NewPair(ctx, uuid.New(), testClient); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := tokens.Sessions(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("expected one session of the user, got %d", len(sessions))
+	}
+
+	s := sessions[0]
+	if s.IP != moved.IP || s.UserAgent != moved.UserAgent {
+		t.Errorf("expected the client of the last refresh, got %q %q", s.IP, s.UserAgent)
+	}
+	if !s.LastRefreshedAt.After(s.CreatedAt) {
+		t.Errorf("expected the refresh to be recorded, got created %v refreshed %v", s.CreatedAt, s.LastRefreshedAt)
+	}
+}
+
+func TestTokenUseCases_RevokeSession(t *testing.T) {
+	ctx := context.Background()
+	tokens := newTokenUseCases()
+	userID := uuid.New()
+
+	phone, err := tokens.NewPair(ctx, userID, testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	laptop, err := tokens.NewPair(ctx, userID, testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	phoneSession := sessionOf(t, phone.Refresh)
+
+	if err = tokens.RevokeSession(ctx, uuid.New(), phoneSession); !errors.Is(err, token.ErrSessionNotFound) {
+		t.Errorf("expected sessions of other users to be hidden, got %v", err)
+	}
+
+	if err = tokens.RevokeSession(ctx, userID, phoneSession); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = tokens.RefreshTokenPair(ctx, phone.Refresh, testClient); !errors.Is(err, token.ErrTokenRevoked) {
+		t.Errorf("expected the revoked session to be logged out, got %v", err)
+	}
+	if _, err = tokens.RefreshTokenPair(ctx, laptop.Refresh, testClient); err != nil {
+		t.Errorf("expected other sessions to stay, got %v", err)
+	}
+}
+
+func TestTokenUseCases_RevokeSessionsExcept(t *testing.T) {
+	ctx := context.Background()
+	tokens := newTokenUseCases()
+	userID := uuid.New()
+
+	current, _ := tokens.NewPair(ctx, userID, testClient)
+	other, _ := tokens.NewPair(ctx, userID, testClient)
+
+	currentSession := sessionOf(t, current.Refresh)
+
+	if err := tokens.RevokeSessions(ctx, userID, currentSession); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tokens.RefreshTokenPair(ctx, other.Refresh, testClient); !errors.Is(err, token.ErrTokenRevoked) {
+		t.Errorf("expected other sessions to be logged out, got %v", err)
+	}
+	if _, err := tokens.RefreshTokenPair(ctx, current.Refresh, testClient); err != nil {
+		t.Errorf("expected the current session to stay, got %v", err)
+	}
+
+	if err := tokens.RevokeSessions(ctx, userID, uuid.Nil); err != nil {
+		t.Fatal(err)
+	}
+	if sessions, _ := tokens.Sessions(ctx, userID); len(sessions) != 0 {
+		t.Errorf("expected no sessions after logging out everywhere, got %d", len(sessions))
+	}
+}
+
+// sessionOf returns the session the refresh token was issued for.
+func sessionOf(t *testing.T, refresh string) uuid.UUID {
+	t.Helper()
+	claims := &token.TokenClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(refresh, claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims.SID == uuid.Nil {
+		t.Fatal("refresh token carries no session")
+	}
+	return claims.SID
+}
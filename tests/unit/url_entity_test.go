@@ -0,0 +1,46 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"roadmap.restapi/internal/url"
+)
+
+func TestURL_ShortURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     url.URL
+		baseURL string
+		want    string
+	}{
+		{"default domain", url.URL{ID: "sale"}, "https://sho.rt", "https://sho.rt/sale"},
+		{"trailing slash", url.URL{ID: "sale"}, "https://sho.rt/", "https://sho.rt/sale"},
+		{"custom domain", url.URL{Domain: "go.brand.com", ID: "sale"}, "https://sho.rt", "https://go.brand.com/sale"},
+		{"custom domain over http", url.URL{Domain: "go.brand.test", ID: "sale"}, "http://localhost:8000", "http://go.brand.test/sale"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.url.ShortURL(tt.baseURL); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestURL_IsExpired(t *testing.T) {
+	now := time.Now().UTC()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	if (&url.URL{}).IsExpired(now) {
+		t.Error("link without expiry should never expire")
+	}
+	if !(&url.URL{ExpiresAt: &past}).IsExpired(now) {
+		t.Error("link should be expired")
+	}
+	if (&url.URL{ExpiresAt: &future}).IsExpired(now) {
+		t.Error("link should not be expired yet")
+	}
+}
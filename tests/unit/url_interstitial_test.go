@@ -0,0 +1,52 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"roadmap.restapi/internal/url"
+)
+
+func TestInterstitialPolicy_Reason(t *testing.T) {
+	policy, err := url.NewInterstitialPolicy(
+		[]string{"Warn.test"},
+		[]string{`\.exe$`},
+		time.Hour,
+	)
+	if err != nil {
+		t.Fatalf("failed to build policy: %v", err)
+	}
+
+	now := time.Now().UTC()
+	recently := now.Add(-time.Minute)
+	longAgo := now.Add(-2 * time.Hour)
+
+	cases := []struct {
+		name   string
+		url    url.URL
+		reason url.InterstitialReason
+		show   bool
+	}{
+		{"plain link", url.URL{URL: "https://safe.test/page"}, "", false},
+		{"enabled on link", url.URL{URL: "https://safe.test", Interstitial: true}, url.INTERSTITIAL_LINK, true},
+		{"listed domain", url.URL{URL: "https://warn.test/page"}, url.INTERSTITIAL_DOMAIN, true},
+		{"listed domain subdomain", url.URL{URL: "https://a.warn.test"}, url.INTERSTITIAL_DOMAIN, true},
+		{"domain suffix is not a subdomain", url.URL{URL: "https://notwarn.test"}, "", false},
+		{"suspicious destination", url.URL{URL: "https://files.test/setup.EXE"}, url.INTERSTITIAL_SUSPICIOUS, true},
+		{"recently changed", url.URL{URL: "https://safe.test", DestinationChangedAt: &recently}, url.INTERSTITIAL_RECENTLY_CHANGED, true},
+		{"changed long ago", url.URL{URL: "https://safe.test", DestinationChangedAt: &longAgo}, "", false},
+	}
+
+	for _, c := range cases {
+		reason, show := policy.Reason(&c.url, now)
+		if show != c.show || reason != c.reason {
+			t.Errorf("%s: got (%q, %v), want (%q, %v)", c.name, reason, show, c.reason, c.show)
+		}
+	}
+}
+
+func TestInterstitialPolicy_InvalidPattern(t *testing.T) {
+	if _, err := url.NewInterstitialPolicy(nil, []string{"("}, time.Hour); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
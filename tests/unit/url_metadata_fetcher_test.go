@@ -0,0 +1,178 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"roadmap.restapi/internal/outbox"
+	"roadmap.restapi/internal/url"
+)
+
+func TestParseMetadata_PrefersOpenGraph(t *testing.T) {
+	doc := `<html><head>
+		<title>Plain title</title>
+		<meta name="description" content="plain description">
+		<meta name="twitter:title" content="Twitter title">
+		<meta content="OG &amp; title" property="og:title" />
+		<meta property='og:description' content='og description'>
+		<meta property="og:image" content="https://cdn.test/image.png">
+	</head></html>`
+
+	meta := url.ParseMetadata(doc)
+
+	if meta.Title != "OG & title" {
+		t.Errorf("Title = %q, want %q", meta.Title, "OG & title")
+	}
+	if meta.Description != "og description" {
+		t.Errorf("Description = %q, want %q", meta.Description, "og description")
+	}
+	if meta.Image != "https://cdn.test/image.png" {
+		t.Errorf("Image = %q", meta.Image)
+	}
+}
+
+func TestParseMetadata_FallsBackToHTML(t *testing.T) {
+	doc := `<html><head>
+		<TITLE>
+			Multi   line
+			title
+		</TITLE>
+		<meta name="Description" content="plain description">
+	</head></html>`
+
+	meta := url.ParseMetadata(doc)
+
+	if meta.Title != "Multi line title" {
+		t.Errorf("Title = %q, want %q", meta.Title, "Multi line title")
+	}
+	if meta.Description != "plain description" {
+		t.Errorf("Description = %q, want %q", meta.Description, "plain description")
+	}
+	if meta.Image != "" {
+		t.Errorf("Image = %q, want empty", meta.Image)
+	}
+}
+
+func TestHTTPMetadataFetcher_ResolvesRelativeImage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<title>Page</title><meta property="og:image" content="/img/cover.png">`))
+	}))
+	defer srv.Close()
+
+	fetcher := url.NewHTTPMetadataFetcherWithClient(&http.Client{Timeout: time.Second}, "test", 1024)
+	meta, err := fetcher.Fetch(context.Background(), srv.URL+"/article")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	if meta.Title != "Page" {
+		t.Errorf("Title = %q, want %q", meta.Title, "Page")
+	}
+	if meta.Image != srv.URL+"/img/cover.png" {
+		t.Errorf("Image = %q, want %q", meta.Image, srv.URL+"/img/cover.png")
+	}
+}
+
+func TestHTTPMetadataFetcher_RejectsNonHTML(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.4"))
+	}))
+	defer srv.Close()
+
+	fetcher := url.NewHTTPMetadataFetcherWithClient(&http.Client{Timeout: time.Second}, "test", 1024)
+	if _, err := fetcher.Fetch(context.Background(), srv.URL); err == nil {
+		t.Error("expected error for non html destination")
+	}
+}
+
+func TestURL_Preview_OverridesWin(t *testing.T) {
+	u := &url.URL{
+		Name:            "name",
+		MetaTitle:       "fetched title",
+		MetaDescription: "fetched description",
+		MetaImage:       "https://fetched.test/img.png",
+		OGTitle:         "override title",
+	}
+
+	preview := u.Preview()
+	if preview.Title != "override title" {
+		t.Errorf("Title = %q, want override", preview.Title)
+	}
+	if preview.Description != "fetched description" {
+		t.Errorf("Description = %q, want fetched", preview.Description)
+	}
+	if preview.Image != "https://fetched.test/img.png" {
+		t.Errorf("Image = %q, want fetched", preview.Image)
+	}
+}
+
+type metadataURLRepo struct {
+	url.URLRepository
+	created []url.URL
+	stored  map[string]url.Metadata
+}
+
+func (r *metadataURLRepo) Create(ctx context.Context, u *url.URL) error {
+	r.created = append(r.created, *u)
+	return nil
+}
+
+func (r *metadataURLRepo) SetMetadata(ctx context.Context, domain string, id string, destination string, meta *url.Metadata) error {
+	r.stored[destination] = *meta
+	return nil
+}
+
+type stubFetcher struct {
+	meta *url.Metadata
+	err  error
+}
+
+func (f stubFetcher) Fetch(ctx context.Context, target string) (*url.Metadata, error) {
+	return f.meta, f.err
+}
+
+func TestURLCreate_RequestsMetadataThroughOutbox(t *testing.T) {
+	repo := &metadataURLRepo{stored: map[string]url.Metadata{}}
+	messages := &memoryOutbox{processed: map[uuid.UUID]bool{}}
+	urls := url.NewUseCases(inlineTx{}, repo, nil, nil, messages, nil, time.Hour)
+
+	err := urls.Create(context.Background(), &url.URL{ID: "abc", URL: "https://dest.test", AuthorID: uuid.New()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(repo.created) != 1 {
+		t.Fatalf("expected url to be created, got %d", len(repo.created))
+	}
+	if len(messages.msgs) != 1 || messages.msgs[0].Topic != url.TOPIC_METADATA_REQUESTED {
+		t.Fatalf("expected one metadata request, got %v", messages.msgs)
+	}
+
+	sender := url.NewMetadataSender(repo, stubFetcher{meta: &url.Metadata{Title: "Fetched"}})
+	if err = sender.Send(context.Background(), &messages.msgs[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.stored["https://dest.test"].Title != "Fetched" {
+		t.Errorf("expected fetched metadata to be stored, got %v", repo.stored)
+	}
+}
+
+func TestMetadataSender_IgnoresFailedFetch(t *testing.T) {
+	repo := &metadataURLRepo{stored: map[string]url.Metadata{}}
+	sender := url.NewMetadataSender(repo, stubFetcher{err: errors.New("unreachable")})
+
+	msg := &outbox.Message{Topic: url.TOPIC_METADATA_REQUESTED, Payload: `{"url_id":"abc","url":"https://dest.test"}`}
+	if err := sender.Send(context.Background(), msg); err != nil {
+		t.Errorf("expected a failed fetch not to fail the message, got %v", err)
+	}
+	if len(repo.stored) != 0 {
+		t.Errorf("expected nothing to be stored, got %v", repo.stored)
+	}
+}